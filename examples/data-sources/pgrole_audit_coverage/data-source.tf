@@ -0,0 +1,8 @@
+data "pgrole_audit_coverage" "this" {}
+
+check "login_roles_audited" {
+  assert {
+    condition     = length(data.pgrole_audit_coverage.this.unaudited_login_roles) == 0
+    error_message = "Login roles without pgaudit settings: ${join(", ", data.pgrole_audit_coverage.this.unaudited_login_roles)}."
+  }
+}
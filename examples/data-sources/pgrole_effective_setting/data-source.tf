@@ -0,0 +1,11 @@
+# Why is the statement timeout of app not the one set by pgrole_statement_timeout?
+data "pgrole_effective_setting" "app_timeout" {
+  role     = "app"
+  database = "orders"
+  name     = "statement_timeout"
+}
+
+output "app_timeout" {
+  # e.g. "5s from role_in_database"
+  value = "${data.pgrole_effective_setting.app_timeout.value} from ${data.pgrole_effective_setting.app_timeout.source}"
+}
@@ -0,0 +1,18 @@
+data "pgrole_grant_role" "app_admin" {
+  role       = "app"
+  grant_role = "admin"
+}
+
+# Fail the plan if the application role gained administration privileges,
+# e.g. through a nested group role.
+resource "pgrole_statement_timeout" "app" {
+  role    = "app"
+  timeout = "30s"
+
+  lifecycle {
+    precondition {
+      condition     = !data.pgrole_grant_role.app_admin.member
+      error_message = "Role app must not be a member of role admin."
+    }
+  }
+}
@@ -0,0 +1,10 @@
+data "pgrole_instance_info" "this" {}
+
+# Only track I/O timing on self-managed servers, where the connecting role is
+# a true superuser.
+resource "pgrole_track_settings" "app" {
+  count = data.pgrole_instance_info.this.managed ? 0 : 1
+
+  role            = "app"
+  track_io_timing = true
+}
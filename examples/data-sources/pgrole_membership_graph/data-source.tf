@@ -0,0 +1,11 @@
+data "pgrole_membership_graph" "this" {}
+
+# Render with: dot -Tsvg roles.dot -o roles.svg
+resource "local_file" "roles" {
+  filename = "${path.module}/roles.dot"
+  content  = data.pgrole_membership_graph.this.dot
+}
+
+output "admins" {
+  value = [for e in data.pgrole_membership_graph.this.edges : e.member if e.admin_option]
+}
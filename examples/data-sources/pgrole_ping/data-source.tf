@@ -0,0 +1,9 @@
+# Wait for the server to accept connections before managing its roles
+data "pgrole_ping" "this" {}
+
+resource "pgrole_statement_timeout" "app" {
+  role    = "app"
+  timeout = "30s"
+
+  depends_on = [data.pgrole_ping.this]
+}
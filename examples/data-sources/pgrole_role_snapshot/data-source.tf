@@ -0,0 +1,10 @@
+data "pgrole_role_snapshot" "example" {
+  role = "app"
+}
+
+# Export the definition of the role, e.g. to diff it against another
+# environment.
+resource "local_file" "app_role" {
+  filename = "${path.module}/app_role.json"
+  content  = data.pgrole_role_snapshot.example.json
+}
@@ -0,0 +1,16 @@
+data "pgrole_roles" "apps" {
+  name_regex = "^app_"
+}
+
+# Import the connection limit of every application role in one pass.
+import {
+  for_each = toset(data.pgrole_roles.apps.names)
+  to       = pgrole_connection_limit.apps[each.key]
+  id       = each.key
+}
+
+resource "pgrole_connection_limit" "apps" {
+  for_each         = toset(data.pgrole_roles.apps.names)
+  role             = each.key
+  connection_limit = 20
+}
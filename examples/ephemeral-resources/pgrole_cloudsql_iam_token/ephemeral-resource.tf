@@ -0,0 +1,9 @@
+ephemeral "pgrole_cloudsql_iam_token" "migrations" {
+  impersonate_service_account = "migrations@my-project.iam.gserviceaccount.com"
+}
+
+provider "postgresql" {
+  host     = "10.0.0.3"
+  username = "migrations@my-project.iam"
+  password = ephemeral.pgrole_cloudsql_iam_token.migrations.token
+}
@@ -0,0 +1,9 @@
+ephemeral "pgrole_connection_string" "this" {}
+
+# Hand the connection string to a job through Secret Manager, without
+# writing it to state.
+resource "google_secret_manager_secret_version" "dsn" {
+  secret                 = google_secret_manager_secret.dsn.id
+  secret_data_wo         = ephemeral.pgrole_connection_string.this.value
+  secret_data_wo_version = 1
+}
@@ -0,0 +1,11 @@
+ephemeral "pgrole_password" "app" {
+  length           = 40
+  override_special = "-_"
+}
+
+# Store the password without writing it to state. Bump the version to rotate.
+resource "google_secret_manager_secret_version" "app_password" {
+  secret                 = google_secret_manager_secret.app_password.id
+  secret_data_wo         = ephemeral.pgrole_password.app.result
+  secret_data_wo_version = 1
+}
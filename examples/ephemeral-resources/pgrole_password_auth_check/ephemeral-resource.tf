@@ -0,0 +1,16 @@
+ephemeral "google_secret_manager_secret_version" "app_next" {
+  secret = "app-password-next"
+}
+
+# Verify the new password of app before retiring the old one
+ephemeral "pgrole_password_auth_check" "app_next" {
+  role     = "app"
+  password = ephemeral.google_secret_manager_secret_version.app_next.secret_data
+
+  lifecycle {
+    postcondition {
+      condition     = self.success
+      error_message = "The new password of app is refused: ${self.error}"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+locals {
+  # A quarter of the memory of the instance, spread over 64 connections
+  work_mem_bytes = floor(16 * 1024 * 1024 * 1024 / 4 / 64)
+}
+
+resource "pgrole_statement_memory_limits" "example" {
+  role     = "etl"
+  work_mem = provider::pgrole::bytes_to_memory(local.work_mem_bytes)
+}
@@ -0,0 +1,13 @@
+variable "statement_timeout" {
+  type = string
+
+  validation {
+    condition     = provider::pgrole::interval_to_ms(var.statement_timeout) <= provider::pgrole::interval_to_ms("5min")
+    error_message = "The statement timeout must be at most 5 minutes."
+  }
+}
+
+resource "pgrole_statement_timeout" "example" {
+  role    = "user1"
+  timeout = var.statement_timeout
+}
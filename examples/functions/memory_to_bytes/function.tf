@@ -0,0 +1,14 @@
+variable "work_mem" {
+  type = string
+
+  validation {
+    condition     = provider::pgrole::memory_to_bytes(var.work_mem) <= provider::pgrole::memory_to_bytes("1GB")
+    error_message = "work_mem must be at most 1GB."
+  }
+}
+
+resource "pgrole_statement_memory_limits" "example" {
+  role            = "etl"
+  work_mem        = var.work_mem
+  temp_file_limit = "20GB"
+}
@@ -0,0 +1,16 @@
+locals {
+  app_settings = {
+    statement_timeout = "30s"
+    work_mem          = "64MB"
+  }
+}
+
+resource "pgrole_config_map" "app" {
+  role     = "app"
+  settings = local.app_settings
+}
+
+# The same settings as a migration script
+output "app_migration" {
+  value = join("\n", provider::pgrole::to_alter_statements("app", local.app_settings))
+}
@@ -0,0 +1,13 @@
+variable "role" {
+  type = string
+
+  validation {
+    condition     = provider::pgrole::validate_pg_identifier(var.role)
+    error_message = "The role must be a lowercase PostgreSQL identifier that needs no quoting."
+  }
+}
+
+resource "pgrole_login" "example" {
+  role    = var.role
+  enabled = true
+}
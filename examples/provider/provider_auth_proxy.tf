@@ -0,0 +1,8 @@
+provider "pgrole" {
+  # Cloud SQL Auth Proxy listening locally, with IAM database authentication
+  host                        = "127.0.0.1"
+  port                        = 5432
+  username                    = "terraform@my-gcp-project.iam"
+  iam_authentication          = true
+  impersonate_service_account = "terraform@my-gcp-project.iam.gserviceaccount.com"
+}
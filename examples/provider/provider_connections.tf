@@ -0,0 +1,26 @@
+provider "pgrole" {
+  host     = "primary.internal"
+  username = "postgres"
+  password = var.primary_password
+
+  # Additional instances managed by the same provider block
+  connections = {
+    "analytics" = {
+      host     = "analytics.internal"
+      username = "postgres"
+      password = var.analytics_password
+    }
+    "reporting" = {
+      project_id = "my-gcp-project"
+      region     = "asia-southeast1"
+      instance   = "reporting"
+      username   = "postgres"
+    }
+  }
+}
+
+resource "pgrole_statement_timeout" "analytics_app" {
+  connection = "analytics"
+  role       = "app"
+  timeout    = "5min"
+}
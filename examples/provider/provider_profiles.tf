@@ -0,0 +1,22 @@
+provider "pgrole" {
+  host     = "localhost"
+  username = "postgres"
+
+  # Golden role configurations, applied with pgrole_role_template
+  profiles = {
+    "app" = {
+      connection_limit = 50
+      login            = true
+      settings = {
+        statement_timeout                   = "30s"
+        idle_in_transaction_session_timeout = "1min"
+      }
+    }
+    "readonly" = {
+      login = true
+      settings = {
+        default_transaction_read_only = "on"
+      }
+    }
+  }
+}
@@ -0,0 +1,2 @@
+# String handling settings can be imported by specifying the role.
+terraform import pgrole_backslash_quote_safety.example role
@@ -0,0 +1,5 @@
+resource "pgrole_backslash_quote_safety" "example" {
+  role                  = "legacy_app"
+  backslash_quote       = "safe_encoding"
+  escape_string_warning = false
+}
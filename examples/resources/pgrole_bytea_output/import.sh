@@ -0,0 +1,2 @@
+# bytea_output can be imported by specifying the role.
+terraform import pgrole_bytea_output.example role
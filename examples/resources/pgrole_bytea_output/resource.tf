@@ -0,0 +1,4 @@
+resource "pgrole_bytea_output" "example" {
+  role   = "legacy_app"
+  format = "escape"
+}
@@ -0,0 +1,2 @@
+# check_function_bodies can be imported by specifying the role.
+terraform import pgrole_check_function_bodies.example role
@@ -0,0 +1,4 @@
+resource "pgrole_check_function_bodies" "example" {
+  role    = "migrations"
+  enabled = false
+}
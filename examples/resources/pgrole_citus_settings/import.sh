@@ -0,0 +1,2 @@
+# Citus settings can be imported by specifying the role.
+terraform import pgrole_citus_settings.example role
@@ -0,0 +1,7 @@
+resource "pgrole_citus_settings" "example" {
+  role     = "analytics"
+  settings = {
+    "citus.enable_repartition_joins"        = "on"
+    "citus.max_adaptive_executor_pool_size" = "4"
+  }
+}
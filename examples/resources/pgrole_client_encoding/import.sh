@@ -0,0 +1,2 @@
+# client_encoding can be imported by specifying the role.
+terraform import pgrole_client_encoding.example role
@@ -0,0 +1,4 @@
+resource "pgrole_client_encoding" "example" {
+  role     = "legacy_app"
+  encoding = "LATIN1"
+}
@@ -0,0 +1,2 @@
+# Collapse limits can be imported by specifying the role.
+terraform import pgrole_collapse_limits.example role
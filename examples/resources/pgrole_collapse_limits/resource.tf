@@ -0,0 +1,5 @@
+resource "pgrole_collapse_limits" "example" {
+  role                = "reporting"
+  from_collapse_limit = 16
+  join_collapse_limit = 16
+}
@@ -0,0 +1,2 @@
+# Role settings can be imported by specifying the role.
+terraform import pgrole_config_map.example role
@@ -0,0 +1,9 @@
+resource "pgrole_config_map" "example" {
+  role     = "app"
+  settings = {
+    "statement_timeout" = "30s"
+    "work_mem"          = "64MB"
+    "application_name"  = "app"
+  }
+  exclusive = true
+}
@@ -2,3 +2,15 @@ resource "pgrole_connection_limit" "example" {
   role             = "user1"
   connection_limit = 200
 }
+
+# Lowering the limit of a busy role: idle sessions over the new limit are
+# terminated, and active ones get 5 minutes to finish
+resource "pgrole_connection_limit" "batch" {
+  role             = "batch"
+  connection_limit = 10
+
+  drain = {
+    timeout        = "5m"
+    terminate_idle = true
+  }
+}
@@ -0,0 +1,2 @@
+# constraint_exclusion can be imported by specifying the role.
+terraform import pgrole_constraint_exclusion.example role
@@ -0,0 +1,4 @@
+resource "pgrole_constraint_exclusion" "example" {
+  role = "legacy_reporting"
+  mode = "on"
+}
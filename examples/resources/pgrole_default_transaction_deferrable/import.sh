@@ -0,0 +1,2 @@
+# default_transaction_deferrable can be imported by specifying the role.
+terraform import pgrole_default_transaction_deferrable.example role
@@ -0,0 +1,4 @@
+resource "pgrole_default_transaction_deferrable" "example" {
+  role    = "reporting"
+  enabled = true
+}
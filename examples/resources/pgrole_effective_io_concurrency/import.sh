@@ -0,0 +1,2 @@
+# effective_io_concurrency can be imported by specifying the role.
+terraform import pgrole_effective_io_concurrency.example role
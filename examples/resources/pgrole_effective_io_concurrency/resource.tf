@@ -0,0 +1,5 @@
+resource "pgrole_effective_io_concurrency" "example" {
+  role                       = "analytics"
+  effective_io_concurrency   = 200
+  maintenance_io_concurrency = 100
+}
@@ -0,0 +1,2 @@
+# extra_float_digits can be imported by specifying the role.
+terraform import pgrole_extra_float_digits.example role
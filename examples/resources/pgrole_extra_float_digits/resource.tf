@@ -0,0 +1,4 @@
+resource "pgrole_extra_float_digits" "example" {
+  role   = "etl"
+  digits = 3
+}
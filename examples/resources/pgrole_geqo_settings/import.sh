@@ -0,0 +1,2 @@
+# GEQO settings can be imported by specifying the role.
+terraform import pgrole_geqo_settings.example role
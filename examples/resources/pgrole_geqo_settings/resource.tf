@@ -0,0 +1,5 @@
+resource "pgrole_geqo_settings" "example" {
+  role           = "analytics"
+  geqo_threshold = 14
+  geqo_effort    = 8
+}
@@ -0,0 +1,2 @@
+# IntervalStyle can be imported by specifying the role.
+terraform import pgrole_intervalstyle.example role
@@ -0,0 +1,4 @@
+resource "pgrole_intervalstyle" "example" {
+  role  = "reporting"
+  style = "iso_8601"
+}
@@ -0,0 +1,2 @@
+# Locale settings can be imported by specifying the role.
+terraform import pgrole_lc_settings.example role
@@ -0,0 +1,6 @@
+resource "pgrole_lc_settings" "example" {
+  role        = "reporting_de"
+  lc_monetary = "de_DE.UTF-8"
+  lc_numeric  = "de_DE.UTF-8"
+  lc_time     = "de_DE.UTF-8"
+}
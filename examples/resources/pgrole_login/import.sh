@@ -0,0 +1,2 @@
+# LOGIN status can be imported by specifying the role.
+terraform import pgrole_login.example role
@@ -0,0 +1,11 @@
+resource "pgrole_login" "example" {
+  role    = "user1"
+  enabled = true
+}
+
+# Disabling a compromised role also kills its active sessions
+resource "pgrole_login" "compromised" {
+  role               = "user2"
+  enabled            = false
+  terminate_sessions = true
+}
@@ -0,0 +1,2 @@
+# Parallel query settings can be imported by specifying the role.
+terraform import pgrole_parallel_query_settings.example role
@@ -0,0 +1,5 @@
+resource "pgrole_parallel_query_settings" "example" {
+  role                            = "analytics"
+  max_parallel_workers_per_gather = 4
+  min_parallel_table_scan_size    = "64MB"
+}
@@ -0,0 +1,2 @@
+# Role passwords can be imported by specifying the role.
+terraform import pgrole_password.example role
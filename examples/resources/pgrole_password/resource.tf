@@ -0,0 +1,13 @@
+ephemeral "pgrole_password" "app" {
+  length = 40
+}
+
+resource "pgrole_password" "app" {
+  role                = "app"
+  password_wo         = ephemeral.pgrole_password.app.result
+  password_wo_version = 1
+
+  # The ephemeral resource generates a new password on every run, which is
+  # set once the current one is 90 days old
+  rotation_days = 90
+}
@@ -0,0 +1,2 @@
+# The password expiry can be imported by specifying the role.
+terraform import pgrole_password_expiry_notifier.example role
@@ -0,0 +1,15 @@
+resource "pgrole_password_expiry_notifier" "example" {
+  role        = "user1"
+  valid_until = "2026-12-31T00:00:00Z"
+  warn_days   = 30
+}
+
+# Fail the pipeline before the credentials lapse
+output "user1_password_days_left" {
+  value = pgrole_password_expiry_notifier.example.days_until_expiry
+
+  precondition {
+    condition     = !pgrole_password_expiry_notifier.example.expired
+    error_message = "The password of user1 has expired."
+  }
+}
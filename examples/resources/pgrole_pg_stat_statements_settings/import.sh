@@ -0,0 +1,2 @@
+# pg_stat_statements.track can be imported by specifying the role.
+terraform import pgrole_pg_stat_statements_settings.example role
@@ -0,0 +1,4 @@
+resource "pgrole_pg_stat_statements_settings" "example" {
+  role  = "app"
+  track = "all"
+}
@@ -0,0 +1,6 @@
+# Fail every plan if a role other than admin is a superuser, or if any role
+# bypasses row-level security
+resource "pgrole_role_attributes_enforcer" "guardrail" {
+  superuser_roles = ["admin"]
+  bypassrls_roles = []
+}
@@ -0,0 +1,3 @@
+# Role templates can be imported by specifying the role. The next apply
+# applies the configured profile.
+terraform import pgrole_role_template.example role
@@ -0,0 +1,26 @@
+provider "pgrole" {
+  host     = "localhost"
+  username = "postgres"
+
+  profiles = {
+    etl = {
+      connection_limit = 5
+      settings = {
+        statement_timeout = "0"
+        work_mem          = "256MB"
+      }
+    }
+    readonly = {
+      login = true
+      settings = {
+        default_transaction_read_only = "on"
+        statement_timeout             = "30s"
+      }
+    }
+  }
+}
+
+resource "pgrole_role_template" "reporting" {
+  role    = "reporting"
+  profile = "readonly"
+}
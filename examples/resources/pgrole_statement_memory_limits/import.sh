@@ -0,0 +1,2 @@
+# Statement memory limits can be imported by specifying the role.
+terraform import pgrole_statement_memory_limits.example role
@@ -0,0 +1,6 @@
+resource "pgrole_statement_memory_limits" "example" {
+  role                = "etl"
+  work_mem            = "256MB"
+  hash_mem_multiplier = 2
+  temp_file_limit     = "20GB"
+}
@@ -0,0 +1,2 @@
+# TCP keepalive settings can be imported by specifying the role.
+terraform import pgrole_tcp_keepalives.example role
@@ -0,0 +1,6 @@
+resource "pgrole_tcp_keepalives" "example" {
+  role     = "remote_worker"
+  idle     = 60
+  interval = 10
+  count    = 6
+}
@@ -0,0 +1,4 @@
+# TimescaleDB settings can be imported by specifying the role, or the role and
+# the database for settings applied in a single database.
+terraform import pgrole_timescaledb_settings.example role
+terraform import pgrole_timescaledb_settings.metrics role@database
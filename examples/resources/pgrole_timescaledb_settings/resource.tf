@@ -0,0 +1,16 @@
+resource "pgrole_timescaledb_settings" "example" {
+  role     = "ingest"
+  settings = {
+    "timescaledb.enable_chunk_skipping"                       = "on"
+    "timescaledb.max_tuples_decompressed_per_dml_transaction" = "0"
+  }
+}
+
+# Settings applied only in the database where the extension is installed
+resource "pgrole_timescaledb_settings" "metrics" {
+  role     = "reporting"
+  database = "metrics"
+  settings = {
+    "timescaledb.enable_chunk_skipping" = "on"
+  }
+}
@@ -0,0 +1,2 @@
+# Statistics tracking settings can be imported by specifying the role.
+terraform import pgrole_track_settings.example role
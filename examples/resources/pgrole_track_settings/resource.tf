@@ -0,0 +1,5 @@
+resource "pgrole_track_settings" "example" {
+  role            = "analytics"
+  track_io_timing = true
+  track_functions = "pl"
+}
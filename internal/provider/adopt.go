@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+)
+
+// adoptAttribute returns the schema of the adopt attribute of the resources
+// restoring the value a role had before they managed it.
+func adoptAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: "Whether to record the value the role has when this resource is created, and restore it when this resource is destroyed instead of resetting the role to its default. Makes the resource safe to layer onto hand-tuned roles. The value is kept in the private state of the resource. When adopt is turned on for an existing or imported resource, the value the role has at that update is recorded instead, since the one it had before is no longer known. Defaults to false.",
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(false),
+	}
+}
+
+// recordOriginal records the value the role has, returned by read, in
+// private for the resource to restore it on destroy. It adds an error to
+// diags and returns false if the value cannot be read.
+func recordOriginal[T any](ctx context.Context, diags *diag.Diagnostics, private privateState, role string, read func() (T, error)) bool {
+	original, err := read()
+	if err != nil {
+		diags.AddError(
+			"Failed to query the original value",
+			fmt.Sprintf("Failed to query the value of role %s to restore on destroy: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return false
+	}
+	return setPrivate(ctx, diags, private, privateOriginalKey, original)
+}
+
+// recordMissingOriginal records the value the role has as recordOriginal
+// does, unless private already holds one, for Update to record it when adopt
+// is turned on for an existing resource.
+func recordMissingOriginal[T any](ctx context.Context, diags *diag.Diagnostics, private privateState, role string, read func() (T, error)) bool {
+	var original json.RawMessage
+	if ok, _ := getPrivate(ctx, private, privateOriginalKey, &original); ok {
+		return true
+	}
+	return recordOriginal(ctx, diags, private, role, read)
+}
+
+// getOriginal reads the value recorded by recordOriginal into original, and
+// returns false if there is none. An invalid value is reported as a warning
+// on diags, for the resource to fall back to its default.
+func getOriginal(ctx context.Context, diags *diag.Diagnostics, private privateState, original any) bool {
+	ok, err := getPrivate(ctx, private, privateOriginalKey, original)
+	if err != nil {
+		diags.AddWarning(
+			"Failed to read the original value",
+			fmt.Sprintf("The original value recorded in the private state is invalid, the default is restored instead: %s", err),
+		)
+	}
+	return ok
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// testPrivateState is an in-memory private state.
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestRecordOriginal(t *testing.T) {
+	ctx := context.Background()
+	private := testPrivateState{}
+	var diags diag.Diagnostics
+
+	timeout := "30s"
+	if !recordOriginal(ctx, &diags, private, "app", func() (*string, error) { return &timeout, nil }) {
+		t.Fatalf("recordOriginal() error = %v", diags)
+	}
+	var original *string
+	if !getOriginal(ctx, &diags, private, &original) || original == nil || *original != "30s" {
+		t.Errorf("getOriginal() = %v, want 30s", original)
+	}
+
+	// An unset value is restored as such
+	if !recordOriginal(ctx, &diags, private, "app", func() (*string, error) { return nil, nil }) {
+		t.Fatalf("recordOriginal() error = %v", diags)
+	}
+	if !getOriginal(ctx, &diags, private, &original) || original != nil {
+		t.Errorf("getOriginal() = %v, want nil", original)
+	}
+
+	if recordOriginal(ctx, &diags, private, "app", func() (bool, error) { return false, errors.New("permission denied") }) {
+		t.Error("recordOriginal() with a failed read = true, want false")
+	}
+	if !diags.HasError() {
+		t.Error("recordOriginal() with a failed read added no error")
+	}
+}
+
+func TestGetOriginalMissing(t *testing.T) {
+	ctx := context.Background()
+	var diags diag.Diagnostics
+	var original bool
+	if getOriginal(ctx, &diags, testPrivateState{}, &original) {
+		t.Error("getOriginal() without a recorded value = true, want false")
+	}
+	if getOriginal(ctx, &diags, nil, &original) {
+		t.Error("getOriginal() without private state = true, want false")
+	}
+	if getOriginal(ctx, &diags, testPrivateState{privateOriginalKey: []byte(`"yes"`)}, &original) {
+		t.Error("getOriginal() with an invalid value = true, want false")
+	}
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("getOriginal() diagnostics = %v, want a single warning", diags)
+	}
+}
+
+func TestRecordMissingOriginal(t *testing.T) {
+	ctx := context.Background()
+	private := testPrivateState{}
+	var diags diag.Diagnostics
+
+	if !recordMissingOriginal(ctx, &diags, private, "app", func() (int64, error) { return 5, nil }) {
+		t.Fatalf("recordMissingOriginal() error = %v", diags)
+	}
+	// The value recorded first is kept
+	if !recordMissingOriginal(ctx, &diags, private, "app", func() (int64, error) { return 10, nil }) {
+		t.Fatalf("recordMissingOriginal() error = %v", diags)
+	}
+	var original int64
+	if !getOriginal(ctx, &diags, private, &original) || original != 5 {
+		t.Errorf("getOriginal() = %d, want 5", original)
+	}
+}
+
+func TestAdoptTurnedOnRestoresOnDestroy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: testAccExecSQL(t, `ALTER ROLE "example_user" CONNECTION LIMIT 7;`),
+		CheckDestroy: func(*terraform.State) error {
+			ctx := context.Background()
+			db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			defer testAccExecSQL(t, `ALTER ROLE "example_user" CONNECTION LIMIT -1;`)()
+			limit, err := readConnectionLimit(ctx, db, "example_user")
+			if err != nil {
+				return err
+			}
+			// The value the role had when adopt was turned on is restored
+			if limit != 5 {
+				return fmt.Errorf("connection limit after destroy = %d, want 5", limit)
+			}
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "pgrole_connection_limit" "test" {
+  role             = "example_user"
+  connection_limit = 5
+}
+`,
+			},
+			{
+				Config: providerConfig + `
+resource "pgrole_connection_limit" "test" {
+  role             = "example_user"
+  connection_limit = 10
+  adopt            = true
+}
+`,
+				Check: resource.TestCheckResourceAttr("pgrole_connection_limit.test", "adopt", "true"),
+			},
+		},
+	})
+}
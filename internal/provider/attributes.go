@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// deletionProtectionAttribute returns the schema of the deletion_protection
+// attribute shared by all resources.
+func deletionProtectionAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: "Whether Terraform is prevented from destroying this resource, i.e. resetting the role to its default. Set to false and apply before destroying. Defaults to false.",
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(false),
+	}
+}
+
+// checkDeletionProtection adds an error to diags and returns false if the
+// resource for role is protected against deletion.
+func checkDeletionProtection(diags *diag.Diagnostics, role string, protected bool) bool {
+	if !protected {
+		return true
+	}
+	diags.AddError(
+		"Deletion protection enabled",
+		fmt.Sprintf("Cannot destroy this resource for role %s because deletion_protection is enabled. Set deletion_protection = false and apply before destroying it.", role),
+	)
+	return false
+}
+
+// skipResetOnDestroyAttribute returns the schema of the skip_reset_on_destroy
+// attribute shared by all resources.
+func skipResetOnDestroyAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: "Whether to leave the current value in place when this resource is destroyed, instead of resetting the role to its default. Useful to stop managing a setting without changing it. Defaults to false.",
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(false),
+	}
+}
+
+// databaseAttribute returns the schema of the database attribute of settings
+// resources, which scopes the settings to a single database.
+func databaseAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "Name of the database to apply the settings in, with ALTER ROLE ... IN DATABASE. The resource connects to this database instead of the provider one, e.g. to check the extensions installed there. Defaults to applying the settings in all databases.",
+		Optional:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// connectionAttribute returns the schema of the connection attribute shared
+// by all resources, which selects one of the named connections of the
+// provider.
+func connectionAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "Name of the provider connection to manage the role through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block. Resources using a named connection are imported with an import block identity naming it, e.g. identity = { role = \"app\", connection = \"analytics\" }.",
+		Optional:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// sqlAttribute returns the schema of the computed sql attribute shared by all
+// resources, which previews the statement run on create or update.
+func sqlAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "The SQL statement run by the next apply, or by the last one if nothing changed. Useful to review role changes during code review.",
+		Computed:    true,
+	}
+}
+
+// connectionLimitValidators returns the validators of connection_limit
+// attributes, bounded by the int4 rolconnlimit column of pg_authid, with -1
+// meaning no limit.
+func connectionLimitValidators() []validator.Int64 {
+	return []validator.Int64{
+		int64validator.Between(-1, math.MaxInt32),
+	}
+}
+
+// endpointRe matches the base URLs of Google APIs.
+var endpointRe = regexp.MustCompile(`^https?://[^/?#]+(/[^?#]*)?$`)
+
+// endpointValidators returns the validators of API endpoint attributes.
+func endpointValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.RegexMatches(endpointRe, "must be an http:// or https:// base URL, e.g. https://sqladmin.googleapis.com/"),
+	}
+}
+
+// hostListRe matches comma-separated lists of hosts, e.g. "db1,db2" or
+// "[::1]".
+var hostListRe = regexp.MustCompile(`^\s*[^,\s]+\s*(,\s*[^,\s]+\s*)*$`)
+
+// hostValidators returns the validators of host attributes.
+func hostValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.RegexMatches(hostListRe, "must be a host name, an IP address or a comma-separated list of them"),
+	}
+}
+
+// portValidators returns the validators of port attributes.
+func portValidators() []validator.Int64 {
+	return []validator.Int64{
+		int64validator.Between(1, 65535),
+	}
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestCheckDeletionProtection(t *testing.T) {
+	var diags diag.Diagnostics
+	if !checkDeletionProtection(&diags, "app", false) || diags.HasError() {
+		t.Errorf("checkDeletionProtection() without protection = false, %v, want true", diags)
+	}
+	if checkDeletionProtection(&diags, "app", true) || !diags.HasError() {
+		t.Error("checkDeletionProtection() with protection = true, want false with an error")
+	}
+}
+
+// testDelete destroys r holding model against fake, and returns the
+// response.
+func testDelete(t *testing.T, r resource.Resource, fake *fakedb.DB, model any) *resource.DeleteResponse {
+	t.Helper()
+	testConfigure(t, r, fake)
+	state := testResourceState(t, r, model)
+	resp := &resource.DeleteResponse{State: state}
+	r.Delete(context.Background(), resource.DeleteRequest{State: state}, resp)
+	return resp
+}
+
+func TestDeleteDeletionProtection(t *testing.T) {
+	fake := fakedb.New()
+	resp := testDelete(t, NewBypassRLSResource(), fake, bypassrlsModel{
+		Role:               "app",
+		Enabled:            true,
+		DeletionProtection: true,
+		SQL:                types.StringValue(sqlSetBypassRLS("app", true)),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Error("Delete() with deletion_protection succeeded, want error")
+	}
+	if execs := fake.Execs(); len(execs) != 0 {
+		t.Errorf("Delete() with deletion_protection executed %v, want nothing", execs)
+	}
+}
+
+func TestDeleteSkipResetOnDestroy(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		fake := fakedb.New().ExpectQuery(`r.rolname = current_user`,
+			[]string{"rolname", "rolsuper", "rolcreaterole", "rolbypassrls", "rolreplication", "cloudsqlsuperuser", "admin", "target_super", "server_version_num"},
+			[]driver.Value{"postgres", true, true, true, true, false, true, false, int64(160000)},
+		)
+		resp := testDelete(t, NewBypassRLSResource(), fake, bypassrlsModel{
+			Role:               "app",
+			Enabled:            true,
+			SkipResetOnDestroy: skip,
+			SQL:                types.StringValue(sqlSetBypassRLS("app", true)),
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Delete(skip_reset_on_destroy = %t) error = %v", skip, resp.Diagnostics)
+		}
+
+		reset := slices.ContainsFunc(fake.Execs(), func(s fakedb.Statement) bool {
+			return s.SQL == sqlDisableBypassRLS("app")
+		})
+		if reset == skip {
+			t.Errorf("Delete(skip_reset_on_destroy = %t) executed %v, want reset %t", skip, fake.Execs(), !skip)
+		}
+	}
+}
+
+func TestDeleteRestoreValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     resource.Resource
+		model any
+		want  string
+	}{
+		{
+			name:  "statement_timeout reset",
+			r:     NewStatementTimeoutResource(),
+			model: statementTimeoutModel{Role: "app", Timeout: "5s", RestoreValue: types.StringNull()},
+			want:  sqlResetStatementTimeout("app"),
+		},
+		{
+			name:  "statement_timeout restored",
+			r:     NewStatementTimeoutResource(),
+			model: statementTimeoutModel{Role: "app", Timeout: "5s", RestoreValue: types.StringValue("30s")},
+			want:  sqlSetStatementTimeout("app", "30s"),
+		},
+		{
+			name:  "connection_limit reset",
+			r:     NewConnectionLimitResource(),
+			model: connectionLimitModel{Role: "app", ConnectionLimit: 10, RestoreValue: types.Int64Null()},
+			want:  sqlSetConnectionLimit("app", -1),
+		},
+		{
+			name:  "connection_limit restored",
+			r:     NewConnectionLimitResource(),
+			model: connectionLimitModel{Role: "app", ConnectionLimit: 10, RestoreValue: types.Int64Value(50)},
+			want:  sqlSetConnectionLimit("app", 50),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakedb.New().ExpectQuery(`r.rolname = current_user`,
+				[]string{"rolname", "rolsuper", "rolcreaterole", "rolbypassrls", "rolreplication", "cloudsqlsuperuser", "admin", "target_super", "server_version_num"},
+				[]driver.Value{"postgres", true, true, true, true, false, true, false, int64(160000)},
+			)
+			resp := testDelete(t, tt.r, fake, tt.model)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Delete() error = %v", resp.Diagnostics)
+			}
+			if !slices.ContainsFunc(fake.Execs(), func(s fakedb.Statement) bool { return s.SQL == tt.want }) {
+				t.Errorf("Delete() executed %v, want %q", fake.Execs(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHostListRe(t *testing.T) {
+	for host, want := range map[string]bool{
+		"db.internal":                true,
+		"::1":                        true,
+		"[::1]":                      true,
+		"db1.internal, db2.internal": true,
+		"10.0.0.1,[2001:db8::1]":     true,
+		"":                           false,
+		"db1.internal,":              false,
+		"db1.internal,,db2.internal": false,
+		"db 1.internal":              false,
+	} {
+		if got := hostListRe.MatchString(host); got != want {
+			t.Errorf("hostListRe.MatchString(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
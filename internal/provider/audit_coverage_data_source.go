@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*auditCoverageDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*auditCoverageDataSource)(nil)
+)
+
+// NewAuditCoverageDataSource is a helper function to simplify the provider implementation.
+func NewAuditCoverageDataSource() datasource.DataSource {
+	return &auditCoverageDataSource{}
+}
+
+type auditCoverageDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type auditCoverageDataSourceModel struct {
+	Connection          types.String             `tfsdk:"connection"`
+	Roles               []auditCoverageRoleModel `tfsdk:"roles"`
+	UnauditedLoginRoles []string                 `tfsdk:"unaudited_login_roles"`
+}
+
+// auditCoverageRoleModel is a role with pgaudit settings.
+type auditCoverageRoleModel struct {
+	Role     string            `tfsdk:"role"`
+	Login    bool              `tfsdk:"login"`
+	Settings map[string]string `tfsdk:"settings"`
+}
+
+// Metadata returns the data source type name.
+func (d *auditCoverageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_coverage"
+}
+
+// Schema defines the schema for the data source.
+func (d *auditCoverageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Lists the non-system roles that have any ` + "`pgaudit.*`" + ` setting in rolconfig, i.e. set for all databases, and the login roles that have none.
+
+Useful for compliance checks, e.g. asserting with a ` + "`check`" + ` block that every login role has auditing configured.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the roles through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"roles": schema.ListNestedAttribute{
+				Description: "The roles with pgaudit settings, sorted by name.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"role": schema.StringAttribute{
+							Description: "Name of the role.",
+							Computed:    true,
+						},
+						"login": schema.BoolAttribute{
+							Description: "Whether the role can LOGIN.",
+							Computed:    true,
+						},
+						"settings": schema.MapAttribute{
+							Description: "Map of pgaudit parameter name, e.g. pgaudit.log, to value.",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"unaudited_login_roles": schema.ListAttribute{
+				Description: "The names of the login roles without any pgaudit setting, sorted.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *auditCoverageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *auditCoverageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data auditCoverageDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	data.Roles, data.UnauditedLoginRoles, err = readAuditCoverage(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query roles",
+			"Failed to query roles: "+err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readAuditCoverage returns the non-system roles with pgaudit settings, and
+// the names of the login roles without any, both sorted by name.
+func readAuditCoverage(ctx context.Context, db *sql.DB) ([]auditCoverageRoleModel, []string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT rolname, rolcanlogin, rolconfig FROM pg_roles WHERE oid >= 16384 AND rolname !~ '^pg_' ORDER BY rolname;`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	audited := []auditCoverageRoleModel{}
+	unaudited := []string{}
+	for rows.Next() {
+		var role auditCoverageRoleModel
+		var rolconfig pq.StringArray
+		if err := rows.Scan(&role.Role, &role.Login, &rolconfig); err != nil {
+			return nil, nil, err
+		}
+		role.Settings = map[string]string{}
+		for name, value := range parseRoleConfig(rolconfig) {
+			if strings.HasPrefix(name, "pgaudit.") {
+				role.Settings[name] = value
+			}
+		}
+		switch {
+		case len(role.Settings) > 0:
+			audited = append(audited, role)
+		case role.Login:
+			unaudited = append(unaudited, role.Role)
+		}
+	}
+	return audited, unaudited, rows.Err()
+}
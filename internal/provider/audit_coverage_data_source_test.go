@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadAuditCoverage(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`FROM pg_roles`, []string{"rolname", "rolcanlogin", "rolconfig"},
+		[]driver.Value{"app", true, "{pgaudit.log=write,work_mem=64MB}"},
+		[]driver.Value{"batch", true, "{statement_timeout=0}"},
+		[]driver.Value{"readers", false, nil},
+		[]driver.Value{"reporting", false, "{pgaudit.log=read,pgaudit.log_relation=on}"},
+		[]driver.Value{"web", true, nil},
+	)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	roles, unaudited, err := readAuditCoverage(ctx, db)
+	if err != nil {
+		t.Fatalf("readAuditCoverage() error = %v", err)
+	}
+	want := []auditCoverageRoleModel{
+		{Role: "app", Login: true, Settings: map[string]string{"pgaudit.log": "write"}},
+		{Role: "reporting", Settings: map[string]string{"pgaudit.log": "read", "pgaudit.log_relation": "on"}},
+	}
+	if !reflect.DeepEqual(roles, want) {
+		t.Errorf("readAuditCoverage() roles = %+v, want %+v", roles, want)
+	}
+	if want := []string{"batch", "web"}; !reflect.DeepEqual(unaudited, want) {
+		t.Errorf("readAuditCoverage() unaudited = %v, want %v", unaudited, want)
+	}
+}
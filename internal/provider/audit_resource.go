@@ -5,12 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -18,6 +25,8 @@ var (
 	_ resource.Resource                = (*auditResource)(nil)
 	_ resource.ResourceWithConfigure   = (*auditResource)(nil)
 	_ resource.ResourceWithImportState = (*auditResource)(nil)
+	_ resource.ResourceWithIdentity    = (*auditResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*auditResource)(nil)
 )
 
 // NewAuditResource is a helper function to simplify the provider implementation.
@@ -26,7 +35,12 @@ func NewAuditResource() resource.Resource {
 }
 
 type auditResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+	parameterGuard   parameterGuard
 }
 
 // Metadata returns the resource type name.
@@ -42,18 +56,47 @@ func (r *auditResource) Schema(_ context.Context, req resource.SchemaRequest, re
 			"role": schema.StringAttribute{
 				Description: "Name of the role.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"audit_log_option": schema.StringAttribute{
-				Description: "Value for the pgaudit.log option for this role. Examples: 'none', 'all', 'ddl', 'write', etc.",
-				Required:    true,
+				Description: "Value for the pgaudit.log option for this role. Examples: 'none', 'all', 'ddl', 'write', etc. Defaults to 'none', which is also how a role without the setting is read, so that omitting it and setting 'none' converge to the same state.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					auditLogNoneDefault{},
+				},
+			},
+			"strict": schema.BoolAttribute{
+				Description: "Whether the apply fails when pgaudit is not loaded through shared_preload_libraries or its extension is not installed, instead of only warning that the setting may have no effect. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
 			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type auditModel struct {
-	Role           string `tfsdk:"role"`
-	AuditLogOption string `tfsdk:"audit_log_option"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	AuditLogOption     string       `tfsdk:"audit_log_option"`
+	Strict             bool         `tfsdk:"strict"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *auditResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -64,15 +107,55 @@ func (r *auditResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
 	}
 
-	r.getDB = client
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+	r.parameterGuard = data.parameterGuard
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *auditResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.parameterGuard.check(path.Root("audit_log_option"), "pgaudit.log")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role types.String
+	var auditLogOption types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("audit_log_option"), &auditLogOption)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || auditLogOption.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetAuditLog(role.ValueString(), auditLogOption.ValueString())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -87,8 +170,9 @@ func (r *auditResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Create the resource
 	sqlstr := sqlSetAuditLog(plan.Role, plan.AuditLogOption)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -97,15 +181,35 @@ func (r *auditResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 	defer db.Close()
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if !checkPgaudit(ctx, db, &resp.Diagnostics, plan.Strict) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readAuditLog(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("audit_log_option", plan.AuditLogOption, actual)) {
+			return
+		}
+	}
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -124,7 +228,7 @@ func (r *auditResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	// Get the actual value in postgres
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -134,23 +238,16 @@ func (r *auditResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 	defer db.Close()
 
-	var auditLogOption string
-	sqlstr := `SELECT setting
-FROM (
-	SELECT UNNEST(rolconfig) AS setting
-	FROM pg_roles
-	WHERE rolname = $1
-) t
-WHERE setting LIKE 'pgaudit.log=%' LIMIT 1;`
-	err = db.QueryRowContext(ctx, sqlstr, state.Role).Scan(&auditLogOption)
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		auditLogOption = "none"
-	case err == nil:
-		auditLogOption = strings.TrimPrefix(auditLogOption, "pgaudit.log=")
-	default:
-
-		resp.Diagnostics.AddError(
+	auditLogOption, err := readAuditLog(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
 			"Failed to query pgaudit.log value",
 			fmt.Sprintf("Failed to query pgaudit.log value for role %s: %s", state.Role, err),
 		)
@@ -162,10 +259,13 @@ WHERE setting LIKE 'pgaudit.log=%' LIMIT 1;`
 		"want": state.AuditLogOption,
 	})
 
+	addDriftWarning(&resp.Diagnostics, state.Role, "audit_log_option", state.AuditLogOption, auditLogOption)
+
 	// Overwrite the state with the actual state
 	state.AuditLogOption = auditLogOption
 
 	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -185,8 +285,9 @@ func (r *auditResource) Update(ctx context.Context, req resource.UpdateRequest,
 
 	// Update resource state with updated values
 	sqlstr := sqlSetAuditLog(plan.Role, plan.AuditLogOption)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -195,14 +296,34 @@ func (r *auditResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if !checkPgaudit(ctx, db, &resp.Diagnostics, plan.Strict) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readAuditLog(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("audit_log_option", plan.AuditLogOption, actual)) {
+			return
+		}
+	}
 
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -220,9 +341,19 @@ func (r *auditResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
 	// Delete the resource by unsetting the pgaudit.log parameter
-	sqlstr := fmt.Sprintf("ALTER ROLE %q RESET pgaudit.log;", state.Role)
-	db, err := r.getDB(ctx)
+	sqlstr := sqlResetAuditLog(state.Role)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -231,20 +362,158 @@ func (r *auditResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 }
 
 func (r *auditResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.State.SetAttribute(ctx, path.Root("audit_log_option"), "none")
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	auditLogOption, err := readAuditLog(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query pgaudit.log value",
+			fmt.Sprintf("Failed to query pgaudit.log value for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("audit_log_option"), auditLogOption)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("strict"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetAuditLog(role, auditLogOption))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// auditLogNoneDefault plans "none" for an unset audit_log_option: pgaudit
+// reports an unset pgaudit.log as "none", so a role reset on destroy, a role
+// without the setting, and one configured with "none" are all read back as
+// "none" and do not show a diff after a destroy and reapply.
+type auditLogNoneDefault struct{}
+
+func (m auditLogNoneDefault) Description(_ context.Context) string {
+	return `Defaults to "none", the value of an unset pgaudit.log.`
+}
+
+func (m auditLogNoneDefault) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m auditLogNoneDefault) PlanModifyString(_ context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.StringValue("none")
+	}
+}
+
+// readAuditLog returns the pgaudit.log setting of the role, "none" if none
+// is set, or sql.ErrNoRows if the role does not exist.
+func readAuditLog(ctx context.Context, db *sql.DB, role string) (string, error) {
+	auditLogOption, ok, err := readRoleSetting(ctx, db, role, "pgaudit.log")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "none", nil
+	}
+	return auditLogOption, nil
+}
+
+// checkPgaudit verifies that pgaudit is loaded and installed, so that the
+// pgaudit.log setting takes effect. Problems are added to diags as an error if
+// strict is set, and as a warning otherwise.
+//
+// pgaudit is detected as loaded from its pgaudit.log parameter, which
+// pg_settings shows to all roles, unlike shared_preload_libraries which is
+// only readable by superusers and members of pg_read_all_settings.
+func checkPgaudit(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, strict bool) bool {
+	var loaded, installed bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_settings WHERE name = 'pgaudit.log'),
+  EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pgaudit');`).Scan(&loaded, &installed)
+	if err != nil {
+		if strict {
+			diags.AddAttributeError(
+				path.Root("strict"),
+				"Failed to query pgaudit status",
+				"Failed to query pgaudit status: "+err.Error(),
+			)
+			return false
+		}
+		diags.AddWarning(
+			"Failed to query pgaudit status",
+			"Failed to query pgaudit status, the pgaudit.log setting may have no effect: "+err.Error(),
+		)
+		return true
+	}
+
+	var problems []string
+	if !loaded {
+		problems = append(problems, "pgaudit is not in shared_preload_libraries, so pgaudit.log is not recognized and nothing is audited. Add it and restart the server")
+	}
+	if !installed {
+		problems = append(problems, "the pgaudit extension is not installed in the database, so DDL statements are not fully audited. Install it, e.g.: CREATE EXTENSION pgaudit;")
+	}
+	if len(problems) == 0 {
+		return true
+	}
+
+	detail := "The pgaudit.log setting may have no effect: " + strings.Join(problems, "; ") + "."
+	if strict {
+		diags.AddAttributeError(path.Root("strict"), "pgaudit not available", detail)
+		return false
+	}
+	diags.AddWarning("pgaudit not available", detail+" Set strict to true to fail instead.")
+	return true
 }
 
 func sqlSetAuditLog(role string, auditLogOption string) string {
-	return fmt.Sprintf("ALTER ROLE %q SET pgaudit.log = '%s';", role, auditLogOption)
+	return fmt.Sprintf("ALTER ROLE %s SET pgaudit.log = %s;", quoteIdentifier(role), pq.QuoteLiteral(auditLogOption))
+}
+
+func sqlResetAuditLog(role string) string {
+	return fmt.Sprintf("ALTER ROLE %s RESET pgaudit.log;", quoteIdentifier(role))
 }
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestSqlSetAuditLog(t *testing.T) {
+	tests := []struct {
+		option string
+		want   string
+	}{
+		{"all", `ALTER ROLE "test" SET pgaudit.log = 'all';`},
+		{"read, write", `ALTER ROLE "test" SET pgaudit.log = 'read, write';`},
+		{"none'; ALTER ROLE test SUPERUSER; --", `ALTER ROLE "test" SET pgaudit.log = 'none''; ALTER ROLE test SUPERUSER; --';`},
+		{`ddl\`, `ALTER ROLE "test" SET pgaudit.log =  E'ddl\\';`},
+	}
+	for _, tt := range tests {
+		if got := sqlSetAuditLog("test", tt.option); got != tt.want {
+			t.Errorf("sqlSetAuditLog(%q) = %q, want %q", tt.option, got, tt.want)
+		}
+	}
+}
+
+func TestAuditLogNoneDefault(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		config types.String
+		plan   types.String
+		want   types.String
+	}{
+		{"unset", types.StringNull(), types.StringUnknown(), types.StringValue("none")},
+		{"unset after destroy and reapply", types.StringNull(), types.StringValue("none"), types.StringValue("none")},
+		{"none", types.StringValue("none"), types.StringValue("none"), types.StringValue("none")},
+		{"configured", types.StringValue("ddl"), types.StringValue("ddl"), types.StringValue("ddl")},
+		{"unknown", types.StringUnknown(), types.StringUnknown(), types.StringUnknown()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{ConfigValue: tt.config, PlanValue: tt.plan}
+			resp := &planmodifier.StringResponse{PlanValue: tt.plan}
+			auditLogNoneDefault{}.PlanModifyString(ctx, req, resp)
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("PlanModifyString() = %s, want %s", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPgaudit(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		loaded       bool
+		installed    bool
+		err          error
+		strict       bool
+		want         bool
+		wantWarnings int
+		wantErrors   int
+	}{
+		{"available", true, true, nil, true, true, 0, 0},
+		{"not loaded", false, true, nil, false, true, 1, 0},
+		{"not installed", true, false, nil, false, true, 1, 0},
+		{"strict", false, false, nil, true, false, 0, 1},
+		// e.g. catalogs hidden from roles without superuser
+		{"query failure", false, false, errors.New("permission denied"), false, true, 1, 0},
+		{"strict query failure", false, false, errors.New("permission denied"), true, false, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakedb.New().ExpectQuery(`pgaudit\.log`, []string{"loaded", "installed"},
+				[]driver.Value{tt.loaded, tt.installed},
+			)
+			if tt.err != nil {
+				fake.ExpectError(`pgaudit\.log`, tt.err)
+			}
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			var diags diag.Diagnostics
+			if got := checkPgaudit(ctx, db, &diags, tt.strict); got != tt.want {
+				t.Errorf("checkPgaudit() = %v, want %v", got, tt.want)
+			}
+			if got := diags.WarningsCount(); got != tt.wantWarnings {
+				t.Errorf("checkPgaudit() added %d warnings, want %d", got, tt.wantWarnings)
+			}
+			if got := diags.ErrorsCount(); got != tt.wantErrors {
+				t.Errorf("checkPgaudit() added %d errors, want %d", got, tt.wantErrors)
+			}
+		})
+	}
+}
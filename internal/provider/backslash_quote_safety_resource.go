@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewBackslashQuoteSafetyResource is a helper function to simplify the provider implementation.
+func NewBackslashQuoteSafetyResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "backslash_quote_safety",
+		description: `Manage the legacy string handling settings of an existing role (standard_conforming_strings, backslash_quote and escape_string_warning), e.g. for compatibility with old client libraries. Settings left unset are reset to the database default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-compatible.html#RUNTIME-CONFIG-COMPATIBLE-VERSION) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "standard_conforming_strings",
+				Parameter:   "standard_conforming_strings",
+				Description: "Whether ordinary string literals treat backslashes literally, as specified in the SQL standard.",
+				Type:        settingBool,
+			},
+			{
+				Attribute:   "backslash_quote",
+				Parameter:   "backslash_quote",
+				Description: "Whether a quote mark can be represented by \\' in a string literal, one of \"on\", \"off\" or \"safe_encoding\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("on", "off", "safe_encoding"),
+				},
+			},
+			{
+				Attribute:   "escape_string_warning",
+				Parameter:   "escape_string_warning",
+				Description: "Whether a warning is issued when a backslash appears in an ordinary string literal.",
+				Type:        settingBool,
+			},
+		},
+	}
+}
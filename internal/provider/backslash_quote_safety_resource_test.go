@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestBackslashQuoteSafetyResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_backslash_quote_safety" "test" {
+  role                  = "example_user"
+  backslash_quote       = "safe_encoding"
+  escape_string_warning = false
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_backslash_quote_safety.test", "backslash_quote", "safe_encoding"),
+					resource.TestCheckResourceAttr("pgrole_backslash_quote_safety.test", "escape_string_warning", "false"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_backslash_quote_safety.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET backslash_quote = 'off';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
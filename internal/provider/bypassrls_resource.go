@@ -2,11 +2,19 @@ package provider
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
@@ -15,6 +23,9 @@ var (
 	_ resource.Resource                = (*bypassrlsResource)(nil)
 	_ resource.ResourceWithConfigure   = (*bypassrlsResource)(nil)
 	_ resource.ResourceWithImportState = (*bypassrlsResource)(nil)
+	_ resource.ResourceWithIdentity    = (*bypassrlsResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*bypassrlsResource)(nil)
+	_ resource.ResourceWithMoveState   = (*bypassrlsResource)(nil)
 )
 
 // NewBypassRLSResource is a helper function to simplify the provider implementation.
@@ -23,7 +34,11 @@ func NewBypassRLSResource() resource.Resource {
 }
 
 type bypassrlsResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
 }
 
 // Metadata returns the resource type name.
@@ -39,18 +54,43 @@ func (r *bypassrlsResource) Schema(_ context.Context, req resource.SchemaRequest
 			"role": schema.StringAttribute{
 				Description: "Name of the role.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"enabled": schema.BoolAttribute{
-				Description: "Whether to enable BYPASSRLS for the role.",
+				Description: "Whether to enable BYPASSRLS for the role. Defaults to false.",
 				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"adopt":                 adoptAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type bypassrlsModel struct {
-	Role    string `tfsdk:"role"`
-	Enabled bool   `tfsdk:"enabled"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	Enabled            bool         `tfsdk:"enabled"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	Adopt              bool         `tfsdk:"adopt"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *bypassrlsResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -61,15 +101,50 @@ func (r *bypassrlsResource) Configure(_ context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
 	}
 
-	r.getDB = client
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *bypassrlsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role types.String
+	var enabled types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("enabled"), &enabled)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || enabled.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetBypassRLS(role.ValueString(), enabled.ValueBool())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -83,14 +158,10 @@ func (r *bypassrlsResource) Create(ctx context.Context, req resource.CreateReque
 	}
 
 	// Create the resource
-	var sqlstr string
-	if plan.Enabled {
-		sqlstr = sqlEnableBypassRLS(plan.Role)
-	} else {
-		sqlstr = sqlDisableBypassRLS(plan.Role)
-	}
+	sqlstr := sqlSetBypassRLS(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -99,15 +170,38 @@ func (r *bypassrlsResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 	defer db.Close()
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "BYPASSRLS") {
+		return
+	}
+	if plan.Adopt && !recordOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readBypassRLS(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readBypassRLS(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -126,7 +220,7 @@ func (r *bypassrlsResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	// Get the actual BYPASSRLS state in postgres
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -136,9 +230,16 @@ func (r *bypassrlsResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 	defer db.Close()
 
-	var enabled bool
-	if err := db.QueryRowContext(ctx, "SELECT rolbypassrls FROM pg_roles WHERE rolname = $1;", state.Role).Scan(&enabled); err != nil {
-		resp.Diagnostics.AddError(
+	enabled, err := readBypassRLS(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
 			"Failed to query BYPASSRLS status",
 			fmt.Sprintf("Failed to query BYPASSRLS status for role %s: %s", state.Role, err),
 		)
@@ -150,10 +251,14 @@ func (r *bypassrlsResource) Read(ctx context.Context, req resource.ReadRequest,
 		"want": state.Enabled,
 	})
 
+	logApplied(ctx, req.Private, state.Role, state.SQL.ValueString())
+	addDriftWarning(&resp.Diagnostics, state.Role, "enabled", state.Enabled, enabled)
+
 	// Overwrite the state with the actual state
 	state.Enabled = enabled
 
 	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -172,14 +277,10 @@ func (r *bypassrlsResource) Update(ctx context.Context, req resource.UpdateReque
 	}
 
 	// Update resource state with updated values
-	var sqlstr string
-	if plan.Enabled {
-		sqlstr = sqlEnableBypassRLS(plan.Role)
-	} else {
-		sqlstr = sqlDisableBypassRLS(plan.Role)
-	}
+	sqlstr := sqlSetBypassRLS(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -188,14 +289,37 @@ func (r *bypassrlsResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "BYPASSRLS") {
+		return
+	}
+	if plan.Adopt && !recordMissingOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readBypassRLS(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readBypassRLS(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
 
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -213,9 +337,23 @@ func (r *bypassrlsResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	// Delete the resource
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Delete the resource, or restore the original value
 	sqlstr := sqlDisableBypassRLS(state.Role)
-	db, err := r.getDB(ctx)
+	var original bool
+	if state.Adopt && getOriginal(ctx, &resp.Diagnostics, req.Private, &original) {
+		sqlstr = sqlSetBypassRLS(state.Role, original)
+	}
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -224,24 +362,116 @@ func (r *bypassrlsResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "BYPASSRLS") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 }
 
 func (r *bypassrlsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.State.SetAttribute(ctx, path.Root("enabled"), false)
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enabled, err := readBypassRLS(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query BYPASSRLS status",
+			fmt.Sprintf("Failed to query BYPASSRLS status for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enabled"), enabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetBypassRLS(role, enabled))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_role resource of the community
+// PostgreSQL provider into this resource.
+func (r *bypassrlsResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlRoleState
+				if !readPostgresqlState(req, resp, "postgresql_role", &source) {
+					return
+				}
+
+				target := bypassrlsModel{
+					Role:    source.Name,
+					Enabled: source.BypassRowLevelSecurity,
+					SQL:     types.StringValue(sqlSetBypassRLS(source.Name, source.BypassRowLevelSecurity)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.Name)...)
+			},
+		},
+	}
+}
+
+// readBypassRLS returns the BYPASSRLS status of the role, or sql.ErrNoRows if
+// the role does not exist.
+func readBypassRLS(ctx context.Context, db *sql.DB, role string) (bool, error) {
+	var enabled bool
+	err := db.QueryRowContext(ctx, "SELECT rolbypassrls FROM pg_roles WHERE rolname = $1;", role).Scan(&enabled)
+	return enabled, err
+}
+
+func sqlSetBypassRLS(role string, enabled bool) string {
+	if enabled {
+		return sqlEnableBypassRLS(role)
+	}
+	return sqlDisableBypassRLS(role)
 }
 
 func sqlEnableBypassRLS(role string) string {
-	return fmt.Sprintf("ALTER ROLE %q BYPASSRLS;", role)
+	return fmt.Sprintf("ALTER ROLE %s BYPASSRLS;", quoteIdentifier(role))
 }
 
 func sqlDisableBypassRLS(role string) string {
-	return fmt.Sprintf("ALTER ROLE %q NOBYPASSRLS;", role)
+	return fmt.Sprintf("ALTER ROLE %s NOBYPASSRLS;", quoteIdentifier(role))
 }
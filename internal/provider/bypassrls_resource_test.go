@@ -23,6 +23,18 @@ resource "pgrole_bypassrls" "test" {
 					resource.TestCheckResourceAttr("pgrole_bypassrls.test", "enabled", "true"),
 				),
 			},
+			// Omitting enabled falls back to the default
+			{
+				Config: providerConfig + `
+resource "pgrole_bypassrls" "test" {
+  role = "test"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_bypassrls.test", "role", "test"),
+					resource.TestCheckResourceAttr("pgrole_bypassrls.test", "enabled", "false"),
+				),
+			},
 		},
 	})
 }
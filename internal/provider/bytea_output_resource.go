@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewByteaOutputResource is a helper function to simplify the provider implementation.
+func NewByteaOutputResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "bytea_output",
+		description: `Manage bytea_output for an existing role, e.g. for legacy applications connecting under a specific role that require the escape format.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-BYTEA-OUTPUT) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "format",
+				Parameter:   "bytea_output",
+				Description: "The output format of bytea values, either \"hex\" or \"escape\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("hex", "escape"),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestByteaOutputResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_bytea_output" "test" {
+  role   = "example_user"
+  format = "escape"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_bytea_output.test", "format", "escape"),
+					resource.TestCheckResourceAttr("pgrole_bytea_output.test", "sql", `ALTER ROLE "example_user" SET bytea_output = 'escape';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_bytea_output.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Import blocks plan without differences
+			{
+				ResourceName:    "pgrole_bytea_output.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithID,
+				ImportStateId:   "example_user",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" RESET bytea_output;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// A role without the setting cannot be imported
+			{
+				ResourceName:  "pgrole_bytea_output.test",
+				ImportState:   true,
+				ImportStateId: "example_user",
+				ExpectError:   regexp.MustCompile(`bytea_output is not set for the role`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = (*bytesToMemoryFunction)(nil)
+
+// NewBytesToMemoryFunction is a helper function to simplify the provider implementation.
+func NewBytesToMemoryFunction() function.Function {
+	return &bytesToMemoryFunction{}
+}
+
+type bytesToMemoryFunction struct{}
+
+// Metadata returns the function name.
+func (f *bytesToMemoryFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "bytes_to_memory"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *bytesToMemoryFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Convert bytes to a PostgreSQL memory size",
+		MarkdownDescription: "Converts a number of bytes into the value of a PostgreSQL memory parameter, in the largest unit dividing it, e.g. 67108864 into \"64MB\", for use with the work_mem and temp_file_limit resources. The inverse of memory_to_bytes.",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:                "bytes",
+				MarkdownDescription: "The number of bytes to convert, at least 0.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run converts the number of bytes.
+func (f *bytesToMemoryFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var bytes int64
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &bytes))
+	if resp.Error != nil {
+		return
+	}
+
+	if bytes < 0 {
+		resp.Error = function.NewArgumentFuncError(0, "bytes must be at least 0")
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, bytesToMemory(bytes)))
+}
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewCheckFunctionBodiesResource is a helper function to simplify the provider implementation.
+func NewCheckFunctionBodiesResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "check_function_bodies",
+		description: `Manage check_function_bodies for an existing role, e.g. to let migration roles deploy functions referencing objects created later in the same migration.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-CHECK-FUNCTION-BODIES) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "enabled",
+				Parameter:   "check_function_bodies",
+				Description: "Whether function bodies are validated by CREATE FUNCTION and CREATE PROCEDURE.",
+				Type:        settingBool,
+			},
+		},
+	}
+}
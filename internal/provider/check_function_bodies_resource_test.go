@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestCheckFunctionBodiesResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_check_function_bodies" "test" {
+  role    = "example_user"
+  enabled = false
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_check_function_bodies.test", "enabled", "false"),
+					resource.TestCheckResourceAttr("pgrole_check_function_bodies.test", "sql", `ALTER ROLE "example_user" SET check_function_bodies = off;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_check_function_bodies.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET check_function_bodies = on;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
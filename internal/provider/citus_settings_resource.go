@@ -0,0 +1,18 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewCitusSettingsResource is a helper function to simplify the provider implementation.
+func NewCitusSettingsResource() resource.Resource {
+	return &namespaceSettingsResource{
+		typeName: "citus_settings",
+		description: `Manage Citus configuration parameters (citus.*) for an existing role, e.g. citus.enable_repartition_joins, on Citus or Azure Cosmos DB for PostgreSQL.
+
+Parameters are given as a map, so that any parameter of the citus namespace can be managed. Parameters removed from the map are reset to the database default.
+
+See Citus [documentation](https://docs.citusdata.com/en/stable/develop/api_guc.html) for more details.`,
+		namespaces: []string{"citus"},
+	}
+}
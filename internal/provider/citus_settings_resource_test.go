@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestCitusSettingsResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Parameters outside the citus namespace are rejected
+			{
+				Config: providerConfig + `
+resource "pgrole_citus_settings" "test" {
+  role     = "example_user"
+  settings = {
+    "work_mem" = "64MB"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`must be a lowercase parameter name in an allowed namespace`),
+			},
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "pgrole_citus_settings" "test" {
+  role     = "example_user"
+  settings = {
+    "citus.enable_repartition_joins" = "on"
+    "citus.log_remote_commands"      = "on"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_citus_settings.test", "settings.%", "2"),
+					resource.TestCheckResourceAttr("pgrole_citus_settings.test", "settings.citus.enable_repartition_joins", "on"),
+					resource.TestCheckResourceAttr("pgrole_citus_settings.test", "sql", `ALTER ROLE "example_user" SET citus.enable_repartition_joins = 'on';
+ALTER ROLE "example_user" SET citus.log_remote_commands = 'on';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_citus_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Removed parameters are reset
+			{
+				Config: providerConfig + `
+resource "pgrole_citus_settings" "test" {
+  role     = "example_user"
+  settings = {
+    "citus.enable_repartition_joins" = "off"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_citus_settings.test", "settings.%", "1"),
+					resource.TestCheckResourceAttr("pgrole_citus_settings.test", "sql", `ALTER ROLE "example_user" RESET citus.log_remote_commands;
+ALTER ROLE "example_user" SET citus.enable_repartition_joins = 'off';`),
+				),
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig: testAccExecSQL(t, `ALTER ROLE "example_user" SET citus.enable_repartition_joins = 'on';`),
+				Config: providerConfig + `
+resource "pgrole_citus_settings" "test" {
+  role     = "example_user"
+  settings = {
+    "citus.enable_repartition_joins" = "off"
+  }
+}
+`,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
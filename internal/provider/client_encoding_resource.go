@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// NewClientEncodingResource is a helper function to simplify the provider implementation.
+func NewClientEncodingResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "client_encoding",
+		description: `Manage client_encoding for an existing role.
+
+The encoding is validated before it is applied: it must be the server encoding, or one the server encoding can be converted to (see ` + "`pg_conversion`" + `).
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/multibyte.html#MULTIBYTE-AUTOMATIC-CONVERSION) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "encoding",
+				Parameter:   "client_encoding",
+				Description: "The client-side character set encoding, e.g. \"UTF8\" or \"LATIN1\".",
+			},
+		},
+		check: checkClientEncoding,
+	}
+}
+
+// sqlClientEncodingSupported reports whether $1 names an encoding clients can
+// use with the server encoding.
+const sqlClientEncodingSupported = `
+SELECT pg_char_to_encoding($1) >= 0 AND (
+	pg_char_to_encoding($1) = pg_char_to_encoding(current_setting('server_encoding'))
+	OR current_setting('server_encoding') = 'SQL_ASCII'
+	OR EXISTS (
+		SELECT 1 FROM pg_conversion
+		WHERE conforencoding = pg_char_to_encoding(current_setting('server_encoding'))
+		AND contoencoding = pg_char_to_encoding($1)
+	)
+);`
+
+// checkClientEncoding validates the encoding against the conversions
+// supported by the server.
+func checkClientEncoding(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, values map[string]attr.Value) bool {
+	encoding := values["encoding"].(types.String).ValueString()
+
+	var supported bool
+	if err := db.QueryRowContext(ctx, sqlClientEncodingSupported, encoding).Scan(&supported); err != nil {
+		diags.AddError(
+			"Failed to query supported encodings",
+			"Failed to query supported encodings: "+err.Error(),
+		)
+		return false
+	}
+	if !supported {
+		diags.AddAttributeError(
+			path.Root("encoding"),
+			"Unsupported client encoding",
+			fmt.Sprintf("Encoding %q is not supported by the server: it is not a known encoding, or the server encoding cannot be converted to it.", encoding),
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestClientEncodingResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_client_encoding" "test" {
+  role     = "example_user"
+  encoding = "LATIN1"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_client_encoding.test", "encoding", "LATIN1"),
+					resource.TestCheckResourceAttr("pgrole_client_encoding.test", "sql", `ALTER ROLE "example_user" SET client_encoding = 'LATIN1';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_client_encoding.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET client_encoding = 'UTF8';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Unknown encodings are rejected before they are applied
+			{
+				Config: providerConfig + `
+resource "pgrole_client_encoding" "test" {
+  role     = "example_user"
+  encoding = "KLINGON"
+}
+`,
+				ExpectError: regexp.MustCompile(`Unsupported client encoding`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
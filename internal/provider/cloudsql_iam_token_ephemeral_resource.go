@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = (*cloudSQLIAMTokenEphemeralResource)(nil)
+	_ ephemeral.EphemeralResourceWithConfigure = (*cloudSQLIAMTokenEphemeralResource)(nil)
+	_ ephemeral.EphemeralResourceWithRenew     = (*cloudSQLIAMTokenEphemeralResource)(nil)
+)
+
+// cloudSQLIAMTokenRenewMargin is how long before the expiry of the token
+// Terraform is asked to renew it, accounting for latency.
+const cloudSQLIAMTokenRenewMargin = 5 * time.Minute
+
+// NewCloudSQLIAMTokenEphemeralResource is a helper function to simplify the provider implementation.
+func NewCloudSQLIAMTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &cloudSQLIAMTokenEphemeralResource{}
+}
+
+type cloudSQLIAMTokenEphemeralResource struct {
+	impersonateServiceAccount string
+	impersonationScopes       []string
+	gcp                       gcpOptions
+}
+
+// Metadata returns the ephemeral resource type name.
+func (r *cloudSQLIAMTokenEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cloudsql_iam_token"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *cloudSQLIAMTokenEphemeralResource) Schema(_ context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Mints a short-lived Cloud SQL IAM database authentication token.
+
+The token can be used as the password of an IAM database user, for example to configure another PostgreSQL provider or a migration tool, without ever being persisted in the plan or state.
+
+The token is minted for the application default credentials, or for ` + "`impersonate_service_account`" + ` if set. When not set, the provider's ` + "`impersonate_service_account`" + ` is used.
+
+Tokens expire after about an hour and cannot be renewed. Terraform warns when an operation outlives the token.`,
+		Attributes: map[string]schema.Attribute{
+			"impersonate_service_account": schema.StringAttribute{
+				Description: "The service account to mint the token for. Defaults to the provider's impersonate_service_account.",
+				Optional:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "The IAM database authentication token.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "The expiry time of the token, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+type cloudSQLIAMTokenModel struct {
+	ImpersonateServiceAccount types.String `tfsdk:"impersonate_service_account"`
+	Token                     types.String `tfsdk:"token"`
+	ExpiresAt                 types.String `tfsdk:"expires_at"`
+}
+
+// Configure adds the provider configured client to the ephemeral resource.
+func (r *cloudSQLIAMTokenEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.impersonateServiceAccount = data.impersonateServiceAccount
+	r.impersonationScopes = data.impersonationScopes
+	r.gcp = data.gcp
+}
+
+// Open mints the token.
+func (r *cloudSQLIAMTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data cloudSQLIAMTokenModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serviceAccount := r.impersonateServiceAccount
+	if !data.ImpersonateServiceAccount.IsNull() {
+		serviceAccount = data.ImpersonateServiceAccount.ValueString()
+	}
+
+	ts, err := CloudSQLIAMTokenSource(ctx, serviceAccount, r.impersonationScopes, r.gcp)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create token source",
+			"Failed to create token source: "+err.Error(),
+		)
+		return
+	}
+	token, err := ts.Token()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to mint token",
+			"Failed to mint token: "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Info(ctx, "Minted Cloud SQL IAM token", map[string]any{
+		"impersonate_service_account": serviceAccount,
+		"expires_at":                  token.Expiry.Format(time.RFC3339),
+	})
+
+	data.ImpersonateServiceAccount = types.StringValue(serviceAccount)
+	if serviceAccount == "" {
+		data.ImpersonateServiceAccount = types.StringNull()
+	}
+	data.Token = types.StringValue(token.AccessToken)
+	data.ExpiresAt = types.StringValue(token.Expiry.Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+
+	// Tokens without expiry, e.g. from some metadata servers, never need
+	// renewing
+	if !token.Expiry.IsZero() {
+		resp.RenewAt = token.Expiry.Add(-cloudSQLIAMTokenRenewMargin)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, "expires_at", fmt.Appendf(nil, "%q", data.ExpiresAt.ValueString()))...)
+	}
+}
+
+// Renew is called by Terraform shortly before the token expires, during long
+// operations. IAM tokens cannot be extended, and Terraform does not let
+// ephemeral resources return a new one, so Renew warns that consumers of the
+// token will fail to authenticate from then on.
+func (r *cloudSQLIAMTokenEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	expiresAt := "soon"
+	if req.Private != nil {
+		value, diags := req.Private.GetKey(ctx, "expires_at")
+		resp.Diagnostics.Append(diags...)
+		var t string
+		if json.Unmarshal(value, &t) == nil {
+			expiresAt = "at " + t
+		}
+	}
+	resp.Diagnostics.AddWarning(
+		"Cloud SQL IAM token expiring",
+		fmt.Sprintf("The Cloud SQL IAM token expires %s and cannot be renewed, operations using it afterwards will fail to authenticate. Split long operations, e.g. with -target, so that each runs with a fresh token.", expiresAt),
+	)
+}
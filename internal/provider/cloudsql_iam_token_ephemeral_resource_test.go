@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+)
+
+func TestCloudSQLIAMTokenRenew(t *testing.T) {
+	var resp ephemeral.RenewResponse
+	(&cloudSQLIAMTokenEphemeralResource{}).Renew(context.Background(), ephemeral.RenewRequest{}, &resp)
+	if got := resp.Diagnostics.WarningsCount(); got != 1 {
+		t.Errorf("Renew() added %d warnings, want 1", got)
+	}
+	if resp.Diagnostics.HasError() {
+		t.Errorf("Renew() error = %v, want none", resp.Diagnostics)
+	}
+	if !resp.RenewAt.IsZero() {
+		t.Errorf("Renew() RenewAt = %v, want no further renewal", resp.RenewAt)
+	}
+}
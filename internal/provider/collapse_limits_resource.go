@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewCollapseLimitsResource is a helper function to simplify the provider implementation.
+func NewCollapseLimitsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "collapse_limits",
+		description: `Manage from_collapse_limit and join_collapse_limit for an existing role, e.g. for reporting roles with very large join trees. Settings left unset are reset to the database default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-query.html#RUNTIME-CONFIG-QUERY-OTHER) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "from_collapse_limit",
+				Parameter:   "from_collapse_limit",
+				Description: "The FROM list size beyond which sub-queries are not merged into the upper query.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			{
+				Attribute:   "join_collapse_limit",
+				Parameter:   "join_collapse_limit",
+				Description: "The FROM list size beyond which explicit JOIN constructs are not flattened.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestCollapseLimitsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_collapse_limits" "test" {
+  role                = "example_user"
+  from_collapse_limit = 16
+  join_collapse_limit = 12
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_collapse_limits.test", "from_collapse_limit", "16"),
+					resource.TestCheckResourceAttr("pgrole_collapse_limits.test", "join_collapse_limit", "12"),
+					resource.TestCheckResourceAttr("pgrole_collapse_limits.test", "sql", `ALTER ROLE "example_user" SET from_collapse_limit = 16;
+ALTER ROLE "example_user" SET join_collapse_limit = 12;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_collapse_limits.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" RESET join_collapse_limit;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// compatibilityCheckModel describes the compatibility_check provider
+// attribute.
+type compatibilityCheckModel struct {
+	MinServerVersion   types.Int64 `tfsdk:"min_server_version"`
+	RequiredExtensions types.List  `tfsdk:"required_extensions"`
+	RequirePrivileges  types.Bool  `tfsdk:"require_privileges"`
+}
+
+// providerCompatibilityCheckAttribute returns the schema of the
+// compatibility_check provider attribute.
+func providerCompatibilityCheckAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "Checks of the server run when the provider is configured, so that an incompatible server fails the plan with a single error listing every problem, before the first resource is applied. Only the connection of the provider block is checked, not the named connections.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"min_server_version": schema.Int64Attribute{
+				Description: "Minimum major version of the server, e.g. 14.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(10),
+				},
+			},
+			"required_extensions": schema.ListAttribute{
+				Description: "Extensions that must be installed in the database of the connection, e.g. [\"pgaudit\"].",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"require_privileges": schema.BoolAttribute{
+				Description: "Whether the connecting role must be able to alter other roles: a superuser, a member of cloudsqlsuperuser on Cloud SQL, or a role with the CREATEROLE attribute. Defaults to true.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// compatibility is what the compatibility checks read from the server.
+type compatibility struct {
+	Version           string
+	VersionNum        int
+	Role              string
+	Superuser         bool
+	CreateRole        bool
+	CloudSQLSuperuser bool
+	Extensions        []string
+}
+
+const sqlCompatibility = `
+SELECT
+	current_setting('server_version'),
+	current_setting('server_version_num')::int,
+	r.rolname,
+	r.rolsuper,
+	r.rolcreaterole,
+	CASE WHEN EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'cloudsqlsuperuser')
+		THEN pg_has_role(r.oid, 'cloudsqlsuperuser', 'MEMBER')
+		ELSE false
+	END
+FROM pg_roles r
+WHERE r.rolname = current_user;`
+
+// readCompatibility returns the server version, the attributes of the
+// connecting role and the extensions installed in the database.
+func readCompatibility(ctx context.Context, db *sql.DB) (compatibility, error) {
+	var c compatibility
+	err := db.QueryRowContext(ctx, sqlCompatibility).Scan(&c.Version, &c.VersionNum, &c.Role, &c.Superuser, &c.CreateRole, &c.CloudSQLSuperuser)
+	if err != nil {
+		return c, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT extname FROM pg_extension ORDER BY extname;")
+	if err != nil {
+		return c, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return c, err
+		}
+		c.Extensions = append(c.Extensions, name)
+	}
+	return c, rows.Err()
+}
+
+// problems returns the problems of the server against the checks of m.
+func (c compatibility) problems(ctx context.Context, m *compatibilityCheckModel) []string {
+	var problems []string
+	if v := m.MinServerVersion.ValueInt64(); !m.MinServerVersion.IsNull() && int64(c.VersionNum) < v*10000 {
+		problems = append(problems, fmt.Sprintf("The server runs PostgreSQL %s, older than the minimum version %d.", c.Version, v))
+	}
+
+	var extensions []string
+	m.RequiredExtensions.ElementsAs(ctx, &extensions, false)
+	for _, extension := range extensions {
+		if !slices.Contains(c.Extensions, extension) {
+			problems = append(problems, fmt.Sprintf("The %s extension is not installed in the database, e.g.: CREATE EXTENSION %s;", extension, quoteIdentifier(extension)))
+		}
+	}
+
+	if (m.RequirePrivileges.IsNull() || m.RequirePrivileges.ValueBool()) && !c.Superuser && !c.CloudSQLSuperuser && !c.CreateRole {
+		problems = append(problems, fmt.Sprintf("The connecting role %s cannot alter other roles: it needs to be a superuser, a member of cloudsqlsuperuser on Cloud SQL, or have the CREATEROLE attribute.", c.Role))
+	}
+	return problems
+}
+
+// checkCompatibility runs the checks of m against the server of getter, and
+// returns a single error listing every problem found.
+func checkCompatibility(ctx context.Context, getter DBGetter, m *compatibilityCheckModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	p := path.Root("compatibility_check")
+	unknown := m.MinServerVersion.IsUnknown() || m.RequiredExtensions.IsUnknown() || m.RequirePrivileges.IsUnknown()
+	for _, extension := range m.RequiredExtensions.Elements() {
+		unknown = unknown || extension.IsUnknown()
+	}
+	if unknown {
+		diags.AddAttributeError(p, "unknown compatibility_check", "unknown compatibility_check")
+		return diags
+	}
+
+	db, err := getter.GetDB(ctx)
+	if err != nil {
+		diags.AddAttributeError(p, "Incompatible server", "Failed to get database connection: "+err.Error())
+		return diags
+	}
+	defer db.Close()
+
+	c, err := readCompatibility(ctx, db)
+	if err != nil {
+		diags.AddAttributeError(p, "Incompatible server", "Failed to query the server: "+err.Error())
+		return diags
+	}
+	if problems := c.problems(ctx, m); len(problems) > 0 {
+		diags.AddAttributeError(p, "Incompatible server", "The server does not pass the compatibility checks:\n\n  - "+strings.Join(problems, "\n  - "))
+	}
+	return diags
+}
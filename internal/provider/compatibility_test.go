@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"server_version", "server_version_num", "rolname", "rolsuper", "rolcreaterole", "cloudsqlsuperuser"}
+	extensions := func(names ...string) types.List {
+		values := make([]attr.Value, len(names))
+		for i, name := range names {
+			values[i] = types.StringValue(name)
+		}
+		return types.ListValueMust(types.StringType, values)
+	}
+	tests := []struct {
+		name  string
+		row   []driver.Value
+		check compatibilityCheckModel
+		want  []string
+	}{
+		{
+			name:  "compatible",
+			row:   []driver.Value{"16.4", int64(160004), "admin", false, true, false},
+			check: compatibilityCheckModel{MinServerVersion: types.Int64Value(14), RequiredExtensions: extensions("pgaudit")},
+		},
+		{
+			name:  "cloud sql",
+			row:   []driver.Value{"15.7", int64(150007), "admin", false, false, true},
+			check: compatibilityCheckModel{RequiredExtensions: types.ListNull(types.StringType)},
+		},
+		{
+			name:  "all problems",
+			row:   []driver.Value{"13.15", int64(130015), "app", false, false, false},
+			check: compatibilityCheckModel{MinServerVersion: types.Int64Value(14), RequiredExtensions: extensions("pgaudit", "pg_cron")},
+			want: []string{
+				"PostgreSQL 13.15, older than the minimum version 14",
+				"The pg_cron extension is not installed",
+				"connecting role app cannot alter other roles",
+			},
+		},
+		{
+			name:  "privileges not required",
+			row:   []driver.Value{"16.4", int64(160004), "app", false, false, false},
+			check: compatibilityCheckModel{RequiredExtensions: types.ListNull(types.StringType), RequirePrivileges: types.BoolValue(false)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakedb.New().
+				ExpectQuery(`current_user`, columns, tt.row).
+				ExpectQuery(`pg_extension`, []string{"extname"}, []driver.Value{"pgaudit"}, []driver.Value{"plpgsql"})
+
+			diags := checkCompatibility(ctx, fake, &tt.check)
+			if len(tt.want) == 0 {
+				if diags.HasError() {
+					t.Errorf("checkCompatibility() error = %v", diags)
+				}
+				return
+			}
+			if diags.ErrorsCount() != 1 {
+				t.Fatalf("checkCompatibility() = %v, want a single error", diags)
+			}
+			detail := diags.Errors()[0].Detail()
+			for _, want := range tt.want {
+				if !strings.Contains(detail, want) {
+					t.Errorf("checkCompatibility() detail = %q, want it to contain %q", detail, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCompatibilityQueryFailure(t *testing.T) {
+	fake := fakedb.New().ExpectError(`current_user`, errors.New("connection refused"))
+	check := compatibilityCheckModel{RequiredExtensions: types.ListNull(types.StringType)}
+	diags := checkCompatibility(context.Background(), fake, &check)
+	if diags.ErrorsCount() != 1 || !strings.Contains(diags.Errors()[0].Detail(), "connection refused") {
+		t.Errorf("checkCompatibility() = %v, want the query error", diags)
+	}
+}
@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewConfigMapResource is a helper function to simplify the provider implementation.
+func NewConfigMapResource() resource.Resource {
+	return &namespaceSettingsResource{
+		typeName: "config_map",
+		description: `Manage any configuration parameters of an existing role, given as a map of parameter name to value, e.g. for parameters without a dedicated resource.
+
+Parameters removed from the map are reset to the database default. With exclusive = true, every other parameter of the role is reset too, including those set outside of Terraform, giving Terraform full ownership of the session defaults of the role.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/sql-alterrole.html) for more details.`,
+	}
+}
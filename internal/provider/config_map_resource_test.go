@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestConfigMapResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_config_map" "test" {
+  role     = "example_user"
+  settings = {
+    "statement_timeout" = "30s"
+    "work_mem"          = "64MB"
+  }
+  exclusive = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccExecSQL(t, `ALTER ROLE "example_user" SET lock_timeout = '5s';`),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, resetting the undeclared parameter
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "settings.%", "2"),
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "settings.work_mem", "64MB"),
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "exclusive", "true"),
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "sql", `ALTER ROLE "example_user" RESET lock_timeout;
+ALTER ROLE "example_user" SET statement_timeout = '30s';
+ALTER ROLE "example_user" SET work_mem = '64MB';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_config_map.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+				ImportStateVerifyIgnore:              []string{"exclusive", "sql"},
+			},
+			// Parameters set outside of Terraform are detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET lock_timeout = '5s';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// and reset by the next apply
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "settings.%", "2"),
+					resource.TestCheckResourceAttr("pgrole_config_map.test", "sql", `ALTER ROLE "example_user" RESET lock_timeout;
+ALTER ROLE "example_user" SET statement_timeout = '30s';
+ALTER ROLE "example_user" SET work_mem = '64MB';`),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
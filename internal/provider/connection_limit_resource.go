@@ -2,11 +2,20 @@ package provider
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -14,6 +23,9 @@ var (
 	_ resource.Resource                = (*connectionLimitResource)(nil)
 	_ resource.ResourceWithConfigure   = (*connectionLimitResource)(nil)
 	_ resource.ResourceWithImportState = (*connectionLimitResource)(nil)
+	_ resource.ResourceWithIdentity    = (*connectionLimitResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*connectionLimitResource)(nil)
+	_ resource.ResourceWithMoveState   = (*connectionLimitResource)(nil)
 )
 
 // NewConnectionLimitResource is a helper function to simplify the provider implementation.
@@ -22,7 +34,11 @@ func NewConnectionLimitResource() resource.Resource {
 }
 
 type connectionLimitResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
 }
 
 // Metadata returns the resource type name.
@@ -38,18 +54,47 @@ func (r *connectionLimitResource) Schema(_ context.Context, req resource.SchemaR
 			"role": schema.StringAttribute{
 				Description: "Name of the role.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"connection_limit": schema.Int32Attribute{
+			"connection_limit": schema.Int64Attribute{
 				Description: "Value for the connection limit for this role. The initial value in Postgres for all roles is -1, which means no limit.",
 				Required:    true,
+				Validators:  connectionLimitValidators(),
 			},
+			"restore_value": schema.Int64Attribute{
+				Description: "The connection limit set on the role when this resource is destroyed, e.g. the organization default of 50. Defaults to -1, i.e. no limit. Recorded when this resource is created: later changes to it only take effect if it was not set then. Takes precedence over adopt, and is ignored if skip_reset_on_destroy is set.",
+				Optional:    true,
+				Validators:  connectionLimitValidators(),
+			},
+			"drain":                 drainAttribute(),
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"adopt":                 adoptAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type connectionLimitModel struct {
-	Role            string `tfsdk:"role"`
-	ConnectionLimit int32  `tfsdk:"connection_limit"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	ConnectionLimit    int64        `tfsdk:"connection_limit"`
+	RestoreValue       types.Int64  `tfsdk:"restore_value"`
+	Drain              *drainModel  `tfsdk:"drain"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	Adopt              bool         `tfsdk:"adopt"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *connectionLimitResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -60,15 +105,50 @@ func (r *connectionLimitResource) Configure(_ context.Context, req resource.Conf
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *connectionLimitResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	r.getDB = client
+	var role types.String
+	var connLimit types.Int64
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("connection_limit"), &connLimit)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || connLimit.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetConnectionLimit(role.ValueString(), connLimit.ValueInt64())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -83,8 +163,9 @@ func (r *connectionLimitResource) Create(ctx context.Context, req resource.Creat
 
 	// Create the resource
 	sqlstr := sqlSetConnectionLimit(plan.Role, plan.ConnectionLimit)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -93,15 +174,42 @@ func (r *connectionLimitResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 	defer db.Close()
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (int64, error) {
+		return readConnectionLimit(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if !plan.RestoreValue.IsNull() && !setPrivate(ctx, &resp.Diagnostics, resp.Private, privateRestoreValueKey, plan.RestoreValue.ValueInt64()) {
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readConnectionLimit(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("connection_limit", plan.ConnectionLimit, actual)) {
+			return
+		}
+	}
+	r.drain(ctx, &resp.Diagnostics, db, plan)
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -120,7 +228,7 @@ func (r *connectionLimitResource) Read(ctx context.Context, req resource.ReadReq
 	}
 
 	// Get the actual value in postgres
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -130,19 +238,30 @@ func (r *connectionLimitResource) Read(ctx context.Context, req resource.ReadReq
 	}
 	defer db.Close()
 
-	var connLimit int32
-	if err := db.QueryRowContext(ctx, "SELECT rolconnlimit FROM pg_roles WHERE rolname = $1;", state.Role).Scan(&connLimit); err != nil {
-		resp.Diagnostics.AddError(
+	connLimit, err := readConnectionLimit(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
 			"Failed to query CONNECTION LIMIT value",
 			fmt.Sprintf("Failed to query CONNECTION LIMIT value for role %s: %s", state.Role, err),
 		)
 		return
 	}
 
+	logApplied(ctx, req.Private, state.Role, state.SQL.ValueString())
+	addDriftWarning(&resp.Diagnostics, state.Role, "connection_limit", state.ConnectionLimit, connLimit)
+
 	// Overwrite the state with the actual state
 	state.ConnectionLimit = connLimit
 
 	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -162,8 +281,9 @@ func (r *connectionLimitResource) Update(ctx context.Context, req resource.Updat
 
 	// Update resource state with updated values
 	sqlstr := sqlSetConnectionLimit(plan.Role, plan.ConnectionLimit)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -172,14 +292,38 @@ func (r *connectionLimitResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordMissingOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (int64, error) {
+		return readConnectionLimit(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readConnectionLimit(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("connection_limit", plan.ConnectionLimit, actual)) {
+			return
+		}
+	}
+	r.drain(ctx, &resp.Diagnostics, db, plan)
 
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -197,9 +341,32 @@ func (r *connectionLimitResource) Delete(ctx context.Context, req resource.Delet
 		return
 	}
 
-	// Delete the resource
-	sqlstr := sqlSetConnectionLimit(state.Role, -1)
-	db, err := r.getDB(ctx)
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Remove the limit, or restore the configured or original one
+	restore := int64(-1)
+	restoreValue := state.RestoreValue
+	var captured int64
+	if getRestoreValue(ctx, &resp.Diagnostics, req.Private, &captured) {
+		restoreValue = types.Int64Value(captured)
+	}
+	var original int64
+	switch {
+	case !restoreValue.IsNull():
+		restore = restoreValue.ValueInt64()
+	case state.Adopt && getOriginal(ctx, &resp.Diagnostics, req.Private, &original):
+		restore = original
+	}
+	sqlstr := sqlSetConnectionLimit(state.Role, restore)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -208,20 +375,138 @@ func (r *connectionLimitResource) Delete(ctx context.Context, req resource.Delet
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 }
 
 func (r *connectionLimitResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.State.SetAttribute(ctx, path.Root("connection_limit"), -1)
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	connLimit, err := readConnectionLimit(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query CONNECTION LIMIT value",
+			fmt.Sprintf("Failed to query CONNECTION LIMIT value for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection_limit"), connLimit)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetConnectionLimit(role, connLimit))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_role resource of the community
+// PostgreSQL provider into this resource.
+func (r *connectionLimitResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlRoleState
+				if !readPostgresqlState(req, resp, "postgresql_role", &source) {
+					return
+				}
+
+				target := connectionLimitModel{
+					Role:            source.Name,
+					ConnectionLimit: source.ConnectionLimit,
+					SQL:             types.StringValue(sqlSetConnectionLimit(source.Name, source.ConnectionLimit)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.Name)...)
+			},
+		},
+	}
+}
+
+// drain brings the sessions of the role within its new connection limit, as
+// configured by the drain attribute. The limit is already set, so failures
+// are reported as warnings.
+func (r *connectionLimitResource) drain(ctx context.Context, diags *diag.Diagnostics, db *sql.DB, m connectionLimitModel) {
+	if m.Drain == nil || m.ConnectionLimit < 0 {
+		return
+	}
+
+	timeout, err := time.ParseDuration(m.Drain.Timeout.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("drain").AtName("timeout"),
+			"Invalid drain configuration",
+			err.Error(),
+		)
+		return
+	}
+	over, err := drainSessions(ctx, db, r.retry.audit, m.Role, m.ConnectionLimit, timeout, m.Drain.TerminateIdle.ValueBool())
+	if err != nil {
+		diags.AddWarning(
+			"Failed to drain sessions",
+			fmt.Sprintf("The connection limit of role %s is set, but its sessions could not be drained: %s", m.Role, err),
+		)
+		return
+	}
+	if over > 0 {
+		diags.AddWarning(
+			"Role over its connection limit",
+			fmt.Sprintf("Role %s still has %d sessions over its connection limit of %d after %s. New sessions are refused until existing ones end.", m.Role, over, m.ConnectionLimit, timeout),
+		)
+	}
+}
+
+// readConnectionLimit returns the CONNECTION LIMIT of the role, or
+// sql.ErrNoRows if the role does not exist.
+func readConnectionLimit(ctx context.Context, db *sql.DB, role string) (int64, error) {
+	var connLimit int64
+	err := db.QueryRowContext(ctx, "SELECT rolconnlimit FROM pg_roles WHERE rolname = $1;", role).Scan(&connLimit)
+	return connLimit, err
 }
 
-func sqlSetConnectionLimit(role string, connLimit int32) string {
-	return fmt.Sprintf("ALTER ROLE %q CONNECTION LIMIT %d;", role, connLimit)
+func sqlSetConnectionLimit(role string, connLimit int64) string {
+	return fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d;", quoteIdentifier(role), connLimit)
 }
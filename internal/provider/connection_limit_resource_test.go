@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestSqlSetConnectionLimit(t *testing.T) {
+	if got, want := sqlSetConnectionLimit("app", 50), `ALTER ROLE "app" CONNECTION LIMIT 50;`; got != want {
+		t.Errorf("sqlSetConnectionLimit() = %q, want %q", got, want)
+	}
+}
+
+func TestConnectionLimitRestoreValue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		CheckDestroy: func(*terraform.State) error {
+			ctx := context.Background()
+			db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			defer testAccExecSQL(t, `ALTER ROLE "example_user" CONNECTION LIMIT -1;`)()
+			limit, err := readConnectionLimit(ctx, db, "example_user")
+			if err != nil {
+				return err
+			}
+			// The restore_value captured on create is restored
+			if limit != 50 {
+				return fmt.Errorf("connection limit after destroy = %d, want 50", limit)
+			}
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "pgrole_connection_limit" "test" {
+  role             = "example_user"
+  connection_limit = 10
+  restore_value    = 50
+}
+`,
+				Check: resource.TestCheckResourceAttr("pgrole_connection_limit.test", "connection_limit", "10"),
+			},
+			// Changing restore_value later does not change the captured one
+			{
+				Config: providerConfig + `
+resource "pgrole_connection_limit" "test" {
+  role             = "example_user"
+  connection_limit = 10
+  restore_value    = 20
+}
+`,
+				Check: resource.TestCheckResourceAttr("pgrole_connection_limit.test", "restore_value", "20"),
+			},
+		},
+	})
+}
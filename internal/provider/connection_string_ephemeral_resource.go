@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = (*connectionStringEphemeralResource)(nil)
+	_ ephemeral.EphemeralResourceWithConfigure = (*connectionStringEphemeralResource)(nil)
+)
+
+// NewConnectionStringEphemeralResource is a helper function to simplify the provider implementation.
+func NewConnectionStringEphemeralResource() ephemeral.EphemeralResource {
+	return &connectionStringEphemeralResource{}
+}
+
+type connectionStringEphemeralResource struct {
+	dsn string
+}
+
+// Metadata returns the ephemeral resource type name.
+func (r *connectionStringEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_connection_string"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *connectionStringEphemeralResource) Schema(_ context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Renders the connection string the provider uses to connect to the database, without ever persisting it in the plan or state.
+
+For standard PostgreSQL connections this is a ` + "`postgres://`" + ` URL including the password, unless ` + "`iam_authentication`" + ` or ` + "`password_command`" + ` is used. For Cloud SQL connections this is the ` + "`gcppostgres://`" + ` URL, which carries no password since IAM database authentication is used; see ` + "`pgrole_cloudsql_iam_token`" + ` to mint one.`,
+		Attributes: map[string]schema.Attribute{
+			"value": schema.StringAttribute{
+				Description: "The connection string.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+type connectionStringModel struct {
+	Value types.String `tfsdk:"value"`
+}
+
+// Configure adds the provider configured client to the ephemeral resource.
+func (r *connectionStringEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.dsn = data.dsn
+}
+
+// Open renders the connection string.
+func (r *connectionStringEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	data := connectionStringModel{
+		Value: types.StringValue(r.dsn),
+	}
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// connectionConfig holds the settings of a connection, with defaults applied.
+type connectionConfig struct {
+	projectID                 string
+	region                    string
+	instance                  string
+	database                  string
+	username                  string
+	impersonateServiceAccount string
+	impersonationScopes       []string
+	gcp                       gcpOptions
+	host                      string
+	port                      int64
+	password                  string
+	sslmode                   string
+	tls                       tlsOptions
+	iamAuthentication         bool
+	passwordCommand           []string
+	passwordKeyring           string
+
+	// cache holds the Google Cloud clients of the connection, shared by its
+	// copies, e.g. by as.
+	cache *gcpCache
+}
+
+// config applies the defaults to the connection settings of m.
+func (m connectionModel) config() connectionConfig {
+	c := connectionConfig{
+		projectID:                 m.ProjectID.ValueString(),
+		region:                    m.Region.ValueString(),
+		instance:                  m.Instance.ValueString(),
+		database:                  "postgres",
+		username:                  m.Username.ValueString(),
+		impersonateServiceAccount: m.ImpersonateServiceAccount.ValueString(),
+		impersonationScopes:       defaultImpersonationScopes,
+		gcp: gcpOptions{
+			quotaProject:           m.QuotaProject.ValueString(),
+			universeDomain:         m.UniverseDomain.ValueString(),
+			sqladminEndpoint:       m.SQLAdminEndpoint.ValueString(),
+			iamCredentialsEndpoint: m.IAMCredentialsEndpoint.ValueString(),
+			certFetchTimeout:       defaultCertFetchTimeout,
+		},
+		host:     m.Host.ValueString(),
+		port:     5432, // Default PostgreSQL port
+		password: m.Password.ValueString(),
+		sslmode:  "disable", // Default to disable SSL
+		tls: tlsOptions{
+			serverName: m.ExpectedServerName.ValueString(),
+			minVersion: tlsVersions[m.MinTLSVersion.ValueString()],
+		},
+		iamAuthentication: m.IAMAuthentication.ValueBool(),
+		passwordKeyring:   m.PasswordKeyring.ValueString(),
+		cache:             &gcpCache{},
+	}
+	if !m.Database.IsNull() {
+		c.database = m.Database.ValueString()
+	}
+	if !m.Port.IsNull() {
+		c.port = m.Port.ValueInt64()
+	}
+	if !m.SSLMode.IsNull() {
+		c.sslmode = m.SSLMode.ValueString()
+	}
+	if !m.PasswordCommand.IsNull() {
+		m.PasswordCommand.ElementsAs(context.Background(), &c.passwordCommand, false)
+	}
+	if !m.TLSCipherSuites.IsNull() {
+		var names []string
+		m.TLSCipherSuites.ElementsAs(context.Background(), &names, false)
+		c.tls.cipherSuites = cipherSuiteIDs(names)
+	}
+	if !m.CertFetchTimeout.IsNull() {
+		c.gcp.certFetchTimeout, _ = time.ParseDuration(m.CertFetchTimeout.ValueString())
+	}
+	if !m.ImpersonationScopes.IsNull() {
+		m.ImpersonationScopes.ElementsAs(context.Background(), &c.impersonationScopes, false)
+	}
+	return c
+}
+
+// validate checks the settings of a named connection at p, the same way as
+// the unknown values checks of Configure and ConfigValidators do for the
+// provider connection.
+func (m connectionModel) validate(p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	values := map[string]attr.Value{
+		"project_id":                  m.ProjectID,
+		"region":                      m.Region,
+		"instance":                    m.Instance,
+		"cert_fetch_timeout":          m.CertFetchTimeout,
+		"database":                    m.Database,
+		"username":                    m.Username,
+		"impersonate_service_account": m.ImpersonateServiceAccount,
+		"impersonation_scopes":        m.ImpersonationScopes,
+		"quota_project":               m.QuotaProject,
+		"universe_domain":             m.UniverseDomain,
+		"sqladmin_endpoint":           m.SQLAdminEndpoint,
+		"iam_credentials_endpoint":    m.IAMCredentialsEndpoint,
+		"host":                        m.Host,
+		"port":                        m.Port,
+		"password":                    m.Password,
+		"sslmode":                     m.SSLMode,
+		"expected_server_name":        m.ExpectedServerName,
+		"min_tls_version":             m.MinTLSVersion,
+		"tls_cipher_suites":           m.TLSCipherSuites,
+		"iam_authentication":          m.IAMAuthentication,
+		"password_command":            m.PasswordCommand,
+		"password_keyring":            m.PasswordKeyring,
+	}
+	for name, value := range values {
+		if value.IsUnknown() {
+			diags.AddAttributeError(p.AtName(name), "unknown "+name, "unknown "+name)
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	set := func(names ...string) (n int) {
+		for _, name := range names {
+			if !values[name].IsNull() {
+				n++
+			}
+		}
+		return n
+	}
+	cloudSQL := set("project_id", "region", "instance")
+	switch {
+	case set(cloudSQLAttributes...) > 0 && set(standardAttributes...) > 0:
+		diags.AddAttributeError(p, "Invalid connection", fmt.Sprintf("The Cloud SQL attributes (%s) and the standard PostgreSQL attributes (%s) cannot be configured together.", strings.Join(cloudSQLAttributes, ", "), strings.Join(standardAttributes, ", ")))
+	case cloudSQL == 0 && m.Host.IsNull():
+		diags.AddAttributeError(p, "Invalid connection", "Either project_id, region and instance must be configured for Cloud SQL, or host for standard PostgreSQL.")
+	case cloudSQL > 0 && cloudSQL < 3:
+		diags.AddAttributeError(p, "Invalid connection", "project_id, region and instance must be configured together.")
+	}
+	diags.Append(validateAuthentication(p, m)...)
+	diags.Append(validateTLS(p, m)...)
+	return diags
+}
+
+// dsn returns the connection string of database on the server, or of the
+// database of the connection if empty.
+func (c connectionConfig) dsn(database string) string {
+	if database == "" {
+		database = c.database
+	}
+	// Check if we should use standard PostgreSQL connection
+	if c.host != "" {
+		return c.standardDSN(database, c.addresses())
+	}
+	// Continue with Cloud SQL connection, the required attributes are
+	// enforced by ConfigValidators and validate. Only built-in users checked
+	// by pgrole_password_auth_check have a password.
+	user := c.username
+	if c.password != "" {
+		user = url.UserPassword(c.username, c.password).String()
+	}
+	return fmt.Sprintf("gcppostgres://%s@%s/%s/%s/%s", user, c.projectID, c.region, c.instance, database)
+}
+
+// standardDSN returns the connection string of database on the hosts at
+// addresses, in the multiple hosts format of libpq if more than one.
+func (c connectionConfig) standardDSN(database string, addresses []string) string {
+	// Without a password, lib/pq looks it up in the password file
+	user := url.User(c.username)
+	if c.password != "" {
+		user = url.UserPassword(c.username, c.password)
+	}
+	return (&url.URL{
+		Scheme:   "postgres",
+		User:     user,
+		Host:     strings.Join(addresses, ","),
+		Path:     "/" + database,
+		RawQuery: url.Values{"sslmode": {c.sslmode}}.Encode(),
+	}).String()
+}
+
+// hosts returns the hosts of the comma-separated host list of the connection,
+// without the brackets of IPv6 addresses, e.g. "[::1]".
+func (c connectionConfig) hosts() []string {
+	var hosts []string
+	for _, host := range strings.Split(c.host, ",") {
+		host = strings.TrimSpace(host)
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = host[1 : len(host)-1]
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// addresses returns the host:port of the hosts of the connection.
+func (c connectionConfig) addresses() []string {
+	port := strconv.FormatInt(c.port, 10)
+	var addresses []string
+	for _, host := range c.hosts() {
+		addresses = append(addresses, net.JoinHostPort(host, port))
+	}
+	return addresses
+}
+
+// dialer returns the dialer of the standard PostgreSQL connection, or nil if
+// lib/pq connects on its own: to a single host, with the TLS settings it
+// supports.
+func (c connectionConfig) dialer() (*postgresDialer, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	addresses := c.addresses()
+	if len(addresses) == 1 && tlsConfig == nil {
+		return nil, nil
+	}
+	return &postgresDialer{addresses: addresses, tlsConfig: tlsConfig}, nil
+}
+
+// connect returns the getter of connections to database on the server, or to
+// the database of the connection if empty.
+func (c connectionConfig) connect(database string) DBGetter {
+	dsn := c.dsn(database)
+	var dialer *postgresDialer
+	if c.host != "" {
+		var err error
+		if dialer, err = c.dialer(); err != nil {
+			return F(func(context.Context) (*sql.DB, error) {
+				return nil, err
+			})
+		}
+		if dialer != nil {
+			// lib/pq connects to a single host, the first one, while the
+			// dialer tries each in turn
+			dsn = c.standardDSN(database, dialer.addresses[:1])
+		}
+	}
+	switch {
+	case c.host != "" && len(c.passwordCommand) > 0:
+		return GetStandardPostgresGetterWithPasswordCommand(dsn, dialer, c.passwordCommand)
+	case c.host != "" && c.iamAuthentication:
+		return GetStandardPostgresGetterWithIAM(dsn, dialer, c.impersonateServiceAccount, c.impersonationScopes, c.gcp, c.cache)
+	case c.host != "":
+		return GetStandardPostgresGetter(dsn, dialer)
+	case c.impersonateServiceAccount != "":
+		return GetDatabaseGetterWithImpersonation(dsn, c.impersonateServiceAccount, c.impersonationScopes, c.gcp, c.cache)
+	default:
+		return GetDatabaseGetter(dsn, c.gcp, c.cache)
+	}
+}
+
+// as returns the connection as role, authenticated with password.
+func (c connectionConfig) as(role, password string) connectionConfig {
+	c.username, c.password = role, password
+	c.iamAuthentication, c.passwordCommand, c.passwordKeyring = false, nil, ""
+	return c
+}
+
+// validateAuthentication checks that standard PostgreSQL connections get
+// their password from a single source, and that impersonate_service_account
+// is only used by them to mint the IAM token of iam_authentication, since it
+// would be ignored otherwise.
+func validateAuthentication(p path.Path, m connectionModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	sources := 0
+	for _, configured := range []bool{!m.Password.IsNull(), !m.PasswordCommand.IsNull(), !m.PasswordKeyring.IsNull(), m.IAMAuthentication.ValueBool()} {
+		if configured {
+			sources++
+		}
+	}
+	if sources > 1 {
+		diags.AddAttributeError(
+			p,
+			"Invalid connection",
+			"Only one of password, password_command, password_keyring and iam_authentication can be configured, as the source of the password.",
+		)
+	}
+	if !m.Host.IsNull() && !m.ImpersonateServiceAccount.IsNull() && !m.IAMAuthentication.ValueBool() {
+		diags.AddAttributeError(
+			p.AtName("impersonate_service_account"),
+			"Invalid connection",
+			"impersonate_service_account requires iam_authentication for standard PostgreSQL connections, to connect as the service account with a Cloud SQL IAM token.",
+		)
+	}
+	return diags
+}
+
+// validateTLS checks that the TLS attributes are only configured with an
+// sslmode negotiating TLS, and that tls_cipher_suites are not configured with
+// TLS 1.3 only, since they would be ignored otherwise.
+func validateTLS(p path.Path, m connectionModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	switch m.SSLMode.ValueString() {
+	case "require", "verify-ca", "verify-full":
+	default:
+		for name, value := range map[string]attr.Value{
+			"expected_server_name": m.ExpectedServerName,
+			"min_tls_version":      m.MinTLSVersion,
+			"tls_cipher_suites":    m.TLSCipherSuites,
+		} {
+			if !value.IsNull() {
+				diags.AddAttributeError(
+					p.AtName(name),
+					"Invalid connection",
+					name+" requires sslmode require, verify-ca or verify-full.",
+				)
+			}
+		}
+	}
+	if m.MinTLSVersion.ValueString() == "1.3" && !m.TLSCipherSuites.IsNull() {
+		diags.AddAttributeError(
+			p.AtName("tls_cipher_suites"),
+			"Invalid connection",
+			"tls_cipher_suites only apply to TLS 1.2, and cannot be configured with min_tls_version 1.3.",
+		)
+	}
+	return diags
+}
+
+// unknownConnection returns a getter failing with an error naming connection,
+// for resources referencing a connection missing from the provider
+// configuration.
+func unknownConnection(connection string) DBGetter {
+	return F(func(context.Context) (*sql.DB, error) {
+		return nil, fmt.Errorf("no connection named %q in the provider configuration", connection)
+	})
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewConstraintExclusionResource is a helper function to simplify the provider implementation.
+func NewConstraintExclusionResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "constraint_exclusion",
+		description: `Manage constraint_exclusion for an existing role, e.g. for roles querying legacy inheritance-partitioned tables.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-query.html#GUC-CONSTRAINT-EXCLUSION) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "mode",
+				Parameter:   "constraint_exclusion",
+				Description: "When the planner uses table constraints to optimize queries, one of \"partition\", \"on\" or \"off\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("partition", "on", "off"),
+				},
+			},
+		},
+	}
+}
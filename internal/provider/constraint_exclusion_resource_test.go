@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestConstraintExclusionResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_constraint_exclusion" "test" {
+  role = "example_user"
+  mode = "on"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_constraint_exclusion.test", "mode", "on"),
+					resource.TestCheckResourceAttr("pgrole_constraint_exclusion.test", "sql", `ALTER ROLE "example_user" SET constraint_exclusion = 'on';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_constraint_exclusion.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET constraint_exclusion = 'off';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -1,62 +1,119 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
+	"os/exec"
+	"strings"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 	"gocloud.dev/gcp"
-	"gocloud.dev/gcp/cloudsql"
-	"gocloud.dev/postgres"
 	"gocloud.dev/postgres/gcppostgres"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 	"google.golang.org/api/impersonate"
 )
 
+// cloudSQLLoginScope is the OAuth2 scope of Cloud SQL IAM database
+// authentication tokens.
+const cloudSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+// defaultImpersonationScopes are the OAuth2 scopes of the tokens of
+// impersonated service accounts, unless configured otherwise: the Cloud SQL
+// Admin API to fetch the certificates of the instance, and IAM database
+// authentication.
+var defaultImpersonationScopes = []string{"https://www.googleapis.com/auth/sqlservice.admin", cloudSQLLoginScope}
+
+// DBGetter returns database connections. It is implemented by F, and by
+// fake databases in unit tests.
+type DBGetter interface {
+	GetDB(ctx context.Context) (*sql.DB, error)
+}
+
 // F is a function that returns a database connection.
 type F func(context.Context) (*sql.DB, error)
 
+// GetDB calls f.
+func (f F) GetDB(ctx context.Context) (*sql.DB, error) {
+	return f(ctx)
+}
+
 // GetDatabaseGetter returns a function that can be used to get a database connection.
 //
 // Remember to call db.Close() to cleanup the connection.
-func GetDatabaseGetter(dsn string) F {
+func GetDatabaseGetter(dsn string, opts gcpOptions, cache *gcpCache) F {
 	return func(ctx context.Context) (*sql.DB, error) {
-		return postgres.Open(ctx, dsn)
+		ts, err := cache.getTokenSource(ctx, func(ctx context.Context) (oauth2.TokenSource, error) {
+			creds, err := gcp.DefaultCredentials(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return creds.TokenSource, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error finding default credentials: %s", err)
+		}
+		return openCloudSQL(ctx, dsn, ts, opts, cache)
 	}
 }
 
 // GetDatabaseGetterWithImpersonation is similar to GetDatabaseGetter
-// but allows impersonating a service account.
-func GetDatabaseGetterWithImpersonation(dsn string, targetServiceAccountEmail string) F {
+// but allows impersonating a service account, with tokens granted scopes.
+func GetDatabaseGetterWithImpersonation(dsn string, targetServiceAccountEmail string, scopes []string, opts gcpOptions, cache *gcpCache) F {
 	return func(ctx context.Context) (*sql.DB, error) {
-		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-			TargetPrincipal: targetServiceAccountEmail,
-			Scopes:          []string{"https://www.googleapis.com/auth/sqlservice.admin"},
+		ts, err := cache.getTokenSource(ctx, func(ctx context.Context) (oauth2.TokenSource, error) {
+			return impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+				TargetPrincipal: targetServiceAccountEmail,
+				Scopes:          scopes,
+			}, opts.clientOptions()...)
 		})
 		if err != nil {
 			return nil, fmt.Errorf("error creating token source: %s", err)
 		}
-		client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), ts)
-		if err != nil {
-			return nil, fmt.Errorf("error creating HTTP client: %s", err)
-		}
-		certSource := cloudsql.NewCertSourceWithIAM(client, ts)
-		opener := gcppostgres.URLOpener{CertSource: certSource}
-		dbURL, err := url.Parse(dsn)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing database connection string: %s", err)
-		}
-		return opener.OpenPostgresURL(ctx, dbURL)
+		return openCloudSQL(ctx, dsn, ts, opts, cache)
+	}
+}
+
+// openCloudSQL opens the gcppostgres:// connection string dsn, fetching the
+// certificates of the instance with ts.
+func openCloudSQL(ctx context.Context, dsn string, ts oauth2.TokenSource, opts gcpOptions, cache *gcpCache) (*sql.DB, error) {
+	certSource, err := cache.getCertSource(ts, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating HTTP client: %s", err)
+	}
+	opener := gcppostgres.URLOpener{CertSource: certSource}
+	dbURL, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing database connection string: %s", err)
 	}
+	return opener.OpenPostgresURL(ctx, dbURL)
+}
+
+// CloudSQLIAMTokenSource returns a token source minting Cloud SQL IAM database
+// authentication tokens for the application default credentials, or for
+// targetServiceAccountEmail if not empty, granted scopes.
+func CloudSQLIAMTokenSource(ctx context.Context, targetServiceAccountEmail string, scopes []string, opts gcpOptions) (oauth2.TokenSource, error) {
+	if targetServiceAccountEmail == "" {
+		return google.DefaultTokenSource(ctx, cloudSQLLoginScope)
+	}
+	return impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: targetServiceAccountEmail,
+		Scopes:          scopes,
+	}, opts.clientOptions()...)
 }
 
 // GetStandardPostgresGetter returns a function that can be used to get a standard PostgreSQL connection.
 //
+// It connects through dialer instead of lib/pq if not nil, e.g. to a list of
+// hosts.
+//
 // Remember to call db.Close() to cleanup the connection.
-func GetStandardPostgresGetter(dsn string) F {
+func GetStandardPostgresGetter(dsn string, dialer *postgresDialer) F {
 	return func(ctx context.Context) (*sql.DB, error) {
-		db, err := sql.Open("postgres", dsn)
+		db, err := openStandardPostgres(dsn, dialer)
 		if err != nil {
 			return nil, fmt.Errorf("error opening database connection: %s", err)
 		}
@@ -64,9 +121,177 @@ func GetStandardPostgresGetter(dsn string) F {
 		// Test the connection
 		if err := db.PingContext(ctx); err != nil {
 			db.Close()
-			return nil, fmt.Errorf("error connecting to database: %s", err)
+			return nil, fmt.Errorf("error connecting to database: %w", err)
 		}
 
 		return db, nil
 	}
 }
+
+// GetStandardPostgresGetterWithIAM is similar to GetStandardPostgresGetter,
+// but authenticates with a Cloud SQL IAM database authentication token minted
+// for the application default credentials, or for targetServiceAccountEmail
+// granted scopes if not empty, e.g. to connect through the Cloud SQL Auth
+// Proxy.
+func GetStandardPostgresGetterWithIAM(dsn string, dialer *postgresDialer, targetServiceAccountEmail string, scopes []string, opts gcpOptions, cache *gcpCache) F {
+	return func(ctx context.Context) (*sql.DB, error) {
+		ts, err := cache.getTokenSource(ctx, func(ctx context.Context) (oauth2.TokenSource, error) {
+			return CloudSQLIAMTokenSource(ctx, targetServiceAccountEmail, scopes, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating token source: %s", err)
+		}
+		token, err := ts.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error minting IAM database authentication token: %s", err)
+		}
+		dbURL, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing database connection string: %s", err)
+		}
+		dbURL.User = url.UserPassword(dbURL.User.Username(), token.AccessToken)
+		return GetStandardPostgresGetter(dbURL.String(), dialer)(ctx)
+	}
+}
+
+// GetStandardPostgresGetterWithPasswordCommand is similar to
+// GetStandardPostgresGetter, but authenticates with the output of command,
+// run at each connection, e.g. a credential helper printing a short-lived
+// token.
+func GetStandardPostgresGetterWithPasswordCommand(dsn string, dialer *postgresDialer, command []string) F {
+	return func(ctx context.Context) (*sql.DB, error) {
+		password, err := runPasswordCommand(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+		dbURL, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing database connection string: %s", err)
+		}
+		dbURL.User = url.UserPassword(dbURL.User.Username(), password)
+		return GetStandardPostgresGetter(dbURL.String(), dialer)(ctx)
+	}
+}
+
+// openStandardPostgres opens dsn with lib/pq, connecting through dialer if
+// not nil. When the dialer negotiates TLS, lib/pq connects without SSL over
+// it.
+func openStandardPostgres(dsn string, dialer *postgresDialer) (*sql.DB, error) {
+	if dialer == nil {
+		return sql.Open("postgres", dsn)
+	}
+	if dialer.tlsConfig != nil {
+		dbURL, err := url.Parse(dsn)
+		if err != nil {
+			return nil, err
+		}
+		query := dbURL.Query()
+		query.Set("sslmode", "disable")
+		dbURL.RawQuery = query.Encode()
+		dsn = dbURL.String()
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(*dialer)
+	return sql.OpenDB(connector), nil
+}
+
+// runPasswordCommand returns the output of command, without the trailing
+// newline.
+func runPasswordCommand(ctx context.Context, command []string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error running password_command %s: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	password := strings.TrimRight(stdout.String(), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password_command %s printed no password", command[0])
+	}
+	return password, nil
+}
+
+// roleExists reports whether the given role exists in the database.
+func roleExists(ctx context.Context, db *sql.DB, role string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1);", role).Scan(&exists)
+	return exists, err
+}
+
+// readRoleSetting returns the value of the named configuration parameter set
+// on the role with ALTER ROLE ... SET. ok is false when the parameter is not
+// set, and sql.ErrNoRows is returned when the role does not exist.
+func readRoleSetting(ctx context.Context, db *sql.DB, role, name string) (value string, ok bool, err error) {
+	config, err := readRoleConfig(ctx, db, role)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok = config[strings.ToLower(name)]
+	return value, ok, nil
+}
+
+// readRoleConfig returns all configuration parameters set on the role with
+// ALTER ROLE ... SET, keyed by lowercase parameter name, or sql.ErrNoRows when
+// the role does not exist.
+func readRoleConfig(ctx context.Context, db *sql.DB, role string) (map[string]string, error) {
+	var config pq.StringArray
+	if err := db.QueryRowContext(ctx, "SELECT rolconfig FROM pg_roles WHERE rolname = $1;", role).Scan(&config); err != nil {
+		return nil, err
+	}
+	return parseRoleConfig(config), nil
+}
+
+// readRoleDatabaseConfig is like readRoleConfig, but returns the parameters
+// set on the role in database with ALTER ROLE ... IN DATABASE, or the ones set
+// in all databases if database is empty.
+func readRoleDatabaseConfig(ctx context.Context, db *sql.DB, role, database string) (map[string]string, error) {
+	if database == "" {
+		return readRoleConfig(ctx, db, role)
+	}
+	var exists bool
+	var config pq.StringArray
+	if err := db.QueryRowContext(ctx, `SELECT d.oid IS NOT NULL, s.setconfig
+FROM pg_roles r
+LEFT JOIN pg_database d ON d.datname = $2
+LEFT JOIN pg_db_role_setting s ON s.setrole = r.oid AND s.setdatabase = d.oid
+WHERE r.rolname = $1;`, role, database).Scan(&exists, &config); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("database %q does not exist", database)
+	}
+	return parseRoleConfig(config), nil
+}
+
+// parseRoleConfig parses the "name=value" entries of a rolconfig or
+// setconfig array, keyed by lowercase parameter name.
+func parseRoleConfig(config []string) map[string]string {
+	settings := make(map[string]string, len(config))
+	for _, setting := range config {
+		if k, v, found := strings.Cut(setting, "="); found {
+			settings[strings.ToLower(k)] = v
+		}
+	}
+	return settings
+}
+
+// sqlAlterRole returns the beginning of the ALTER ROLE statements changing the
+// configuration parameters of role in database, or in all databases if
+// database is empty.
+func sqlAlterRole(role, database string) string {
+	if database == "" {
+		return "ALTER ROLE " + quoteIdentifier(role)
+	}
+	return fmt.Sprintf("ALTER ROLE %s IN DATABASE %s", quoteIdentifier(role), quoteIdentifier(database))
+}
+
+// quoteIdentifier quotes name, e.g. a role name, for use as an identifier in
+// SQL statements. Unlike Go's %q verb, it follows PostgreSQL rules: embedded
+// double quotes are doubled and everything else, including uppercase letters,
+// spaces and unicode, is kept verbatim.
+func quoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
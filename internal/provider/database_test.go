@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"app", `"app"`},
+		{"AppUser", `"AppUser"`},
+		{"app user", `"app user"`},
+		{`app"user`, `"app""user"`},
+		{`"quoted"`, `"""quoted"""`},
+		{`back\slash`, `"back\slash"`},
+		{"sa@project.iam", `"sa@project.iam"`},
+		{"ロール", `"ロール"`},
+		{"tab\tname", "\"tab\tname\""},
+	}
+	for _, tt := range tests {
+		if got := quoteIdentifier(tt.name); got != tt.want {
+			t.Errorf("quoteIdentifier(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSqlStatementsQuoteRoleIdentifier(t *testing.T) {
+	const role = `Weird "Role" ü`
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{sqlEnableBypassRLS(role), `ALTER ROLE "Weird ""Role"" ü" BYPASSRLS;`},
+		{sqlDisableReplication(role), `ALTER ROLE "Weird ""Role"" ü" NOREPLICATION;`},
+		{sqlSetConnectionLimit(role, 10), `ALTER ROLE "Weird ""Role"" ü" CONNECTION LIMIT 10;`},
+		{sqlResetStatementTimeout(role), `ALTER ROLE "Weird ""Role"" ü" RESET statement_timeout;`},
+		{sqlResetAuditLog(role), `ALTER ROLE "Weird ""Role"" ü" RESET pgaudit.log;`},
+		{sqlRemoveSecurityLabel(role), `SECURITY LABEL FOR anon ON ROLE "Weird ""Role"" ü" IS NULL;`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %s, want %s", tt.got, tt.want)
+		}
+	}
+}
+
+func TestReadRoleSetting(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`FROM pg_roles`, []string{"rolconfig"},
+		[]driver.Value{[]byte(`{statement_timeout=5s,"search_path=a, b"}`)},
+	)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	if value, ok, err := readRoleSetting(ctx, db, "app", "search_path"); err != nil || !ok || value != "a, b" {
+		t.Errorf("readRoleSetting(search_path) = %q, %t, %v, want \"a, b\", true, nil", value, ok, err)
+	}
+	if _, ok, err := readRoleSetting(ctx, db, "app", "work_mem"); err != nil || ok {
+		t.Errorf("readRoleSetting(work_mem) = _, %t, %v, want false, nil", ok, err)
+	}
+
+	fake.ExpectQuery(`FROM pg_roles`, []string{"rolconfig"})
+	if _, _, err := readRoleSetting(ctx, db, "missing", "search_path"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("readRoleSetting() of a missing role error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestReadRoleDatabaseConfig(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().
+		ExpectQuery(`SELECT rolconfig FROM pg_roles`, []string{"rolconfig"},
+			[]driver.Value{[]byte(`{work_mem=4MB}`)},
+		).
+		ExpectQuery(`JOIN pg_db_role_setting`, []string{"exists", "setconfig"},
+			[]driver.Value{true, []byte(`{work_mem=64MB}`)},
+		)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	if config, err := readRoleDatabaseConfig(ctx, db, "app", ""); err != nil || config["work_mem"] != "4MB" {
+		t.Errorf("readRoleDatabaseConfig() in all databases = %v, %v, want work_mem=4MB", config, err)
+	}
+	if config, err := readRoleDatabaseConfig(ctx, db, "app", "analytics"); err != nil || config["work_mem"] != "64MB" {
+		t.Errorf("readRoleDatabaseConfig() in analytics = %v, %v, want work_mem=64MB", config, err)
+	}
+
+	// Roles without settings in the database have a NULL setconfig
+	fake.ExpectQuery(`JOIN pg_db_role_setting`, []string{"exists", "setconfig"}, []driver.Value{true, nil})
+	if config, err := readRoleDatabaseConfig(ctx, db, "app", "analytics"); err != nil || len(config) != 0 {
+		t.Errorf("readRoleDatabaseConfig() without settings = %v, %v, want none", config, err)
+	}
+
+	// Settings in a missing database are an error rather than unset
+	fake.ExpectQuery(`JOIN pg_db_role_setting`, []string{"exists", "setconfig"}, []driver.Value{false, nil})
+	if config, err := readRoleDatabaseConfig(ctx, db, "app", "missing"); err == nil {
+		t.Errorf("readRoleDatabaseConfig() in a missing database = %v, want error", config)
+	}
+}
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewDefaultTransactionDeferrableResource is a helper function to simplify the provider implementation.
+func NewDefaultTransactionDeferrableResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "default_transaction_deferrable",
+		description: `Manage default_transaction_deferrable for an existing role, e.g. so that long-running serializable read-only roles avoid serialization failures.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-DEFERRABLE) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "enabled",
+				Parameter:   "default_transaction_deferrable",
+				Description: "Whether new transactions are deferrable by default. Only affects serializable read-only transactions.",
+				Type:        settingBool,
+			},
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestDefaultTransactionDeferrableResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_default_transaction_deferrable" "test" {
+  role    = "example_user"
+  enabled = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_default_transaction_deferrable.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("pgrole_default_transaction_deferrable.test", "sql", `ALTER ROLE "example_user" SET default_transaction_deferrable = on;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_default_transaction_deferrable.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET default_transaction_deferrable = off;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
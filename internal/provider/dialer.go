@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// sslRequest is the message asking a PostgreSQL server to switch to TLS: its
+// length followed by the SSLRequest code.
+var sslRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// postgresDialer dials PostgreSQL servers for lib/pq, for the connections it
+// cannot make on its own: to the first host of addresses accepting them, and
+// over TLS negotiated with tlsConfig if not nil.
+type postgresDialer struct {
+	// addresses are the host:port of the hosts, tried in order, instead of
+	// the address of lib/pq if not empty.
+	addresses []string
+	tlsConfig *tls.Config
+}
+
+// Dial implements pq.Dialer.
+func (d postgresDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialTimeout implements pq.Dialer.
+func (d postgresDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+// DialContext implements pq.DialerContext, returning the connection to the
+// first host accepting it, once the TLS handshake is complete.
+func (d postgresDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	addresses := d.addresses
+	if len(addresses) == 0 {
+		addresses = []string{address}
+	}
+	var errs []error
+	for _, address := range addresses {
+		conn, err := d.dial(ctx, network, address)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", address, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// dial connects to the host at address, negotiating TLS if configured. The
+// certificate of the host is verified against its own name, unless the TLS
+// configuration sets another one.
+func (d postgresDialer) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if d.tlsConfig == nil {
+		return conn, nil
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(sslRequest); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response[0] != 'S' {
+		conn.Close()
+		return nil, errors.New("SSL is not enabled on the server")
+	}
+	config := d.tlsConfig
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+	client := tls.Client(conn, config)
+	if err := client.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error negotiating TLS: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	return client, nil
+}
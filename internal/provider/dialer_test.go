@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTLSServer accepts a connection answering the SSLRequest with response,
+// and completes the TLS handshake with cert, up to TLS 1.2, if it is 'S'. It
+// returns the address of the server.
+func fakeTLSServer(t *testing.T, cert tls.Certificate, response byte) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		request := make([]byte, len(sslRequest))
+		if _, err := io.ReadFull(conn, request); err != nil || string(request) != string(sslRequest) {
+			return
+		}
+		conn.Write([]byte{response})
+		if response == 'S' {
+			tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}, MaxVersion: tls.VersionTLS12}).Handshake()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestPostgresDialer(t *testing.T) {
+	cert, rootCert := testCertificate(t, "db.example.com")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PGSSLROOTCERT", rootCert)
+
+	tests := []struct {
+		name       string
+		sslmode    string
+		serverName string
+		minVersion uint16
+		response   byte
+		want       string
+	}{
+		{name: "expected server name", sslmode: "verify-full", serverName: "db.example.com", response: 'S'},
+		{name: "other server name", sslmode: "verify-full", serverName: "other.example.com", response: 'S', want: "certificate is valid for db.example.com"},
+		{name: "verify-ca ignores the name", sslmode: "verify-ca", serverName: "other.example.com", response: 'S'},
+		{name: "ssl disabled on the server", sslmode: "verify-full", serverName: "db.example.com", response: 'N', want: "SSL is not enabled"},
+		{name: "minimum version above the server", sslmode: "verify-full", serverName: "db.example.com", minVersion: tls.VersionTLS13, response: 'S', want: "protocol version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := connectionConfig{sslmode: tt.sslmode, tls: tlsOptions{serverName: tt.serverName, minVersion: tt.minVersion}}
+			config, err := c.tlsConfig()
+			if err != nil {
+				t.Fatalf("tlsConfig() error = %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			conn, err := postgresDialer{tlsConfig: config}.DialContext(ctx, "tcp", fakeTLSServer(t, cert, tt.response))
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("DialContext() error = %v", err)
+				}
+				conn.Close()
+				return
+			}
+			if err == nil {
+				conn.Close()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("DialContext() error = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresDialerHosts(t *testing.T) {
+	cert, rootCert := testCertificate(t, "db.example.com")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PGSSLROOTCERT", rootCert)
+
+	// An address refusing connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	down := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Without TLS, the connection to the first host accepting it is returned
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer up.Close()
+	conn, err := postgresDialer{addresses: []string{down, up.Addr().String()}}.DialContext(ctx, "tcp", "ignored:5432")
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	if got := conn.RemoteAddr().String(); got != up.Addr().String() {
+		t.Errorf("DialContext() connected to %s, want %s", got, up.Addr())
+	}
+	conn.Close()
+
+	// Each host is verified against its own name, here the IP address of the
+	// certificate
+	c := connectionConfig{host: "127.0.0.1,127.0.0.1", sslmode: "verify-full"}
+	config, err := c.tlsConfig()
+	if err != nil {
+		t.Fatalf("tlsConfig() error = %v", err)
+	}
+	conn, err = postgresDialer{addresses: []string{down, fakeTLSServer(t, cert, 'S')}, tlsConfig: config}.DialContext(ctx, "tcp", "ignored:5432")
+	if err != nil {
+		t.Fatalf("DialContext() with TLS error = %v", err)
+	}
+	conn.Close()
+
+	// Errors name every host
+	_, err = postgresDialer{addresses: []string{down, down}}.DialContext(ctx, "tcp", "ignored:5432")
+	if err == nil || strings.Count(err.Error(), down) < 2 {
+		t.Errorf("DialContext() error = %v, want an error for each host", err)
+	}
+}
@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// addDriftWarning adds a warning to diags when the value of attribute found in
+// the database differs from the one in the prior state, so that changes made
+// outside of Terraform are visible in the plan output.
+func addDriftWarning[T comparable](diags *diag.Diagnostics, role, attribute string, expected, actual T) {
+	if expected == actual {
+		return
+	}
+	diags.AddAttributeWarning(
+		path.Root(attribute),
+		"Drift detected",
+		fmt.Sprintf("The %s of role %s was changed outside of Terraform: expected %v, found %v.", attribute, role, expected, actual),
+	)
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestAddDriftWarning(t *testing.T) {
+	var diags diag.Diagnostics
+	addDriftWarning(&diags, "app", "statement_timeout", "30s", "30s")
+	if diags.WarningsCount() != 0 {
+		t.Errorf("addDriftWarning() without drift added %d warnings, want none", diags.WarningsCount())
+	}
+
+	addDriftWarning(&diags, "app", "connection_limit", int32(10), int32(-1))
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("addDriftWarning() with drift added %d warnings, want 1", diags.WarningsCount())
+	}
+	want := "The connection_limit of role app was changed outside of Terraform: expected 10, found -1."
+	if got := diags[0].Detail(); got != want {
+		t.Errorf("addDriftWarning() detail = %q, want %q", got, want)
+	}
+}
+
+// testResourceState returns the state of r holding model.
+func testResourceState(t *testing.T, r resource.Resource, model any) tfsdk.State {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := state.Set(ctx, model); diags.HasError() {
+		t.Fatalf("State.Set() error = %v", diags)
+	}
+	return state
+}
+
+func TestReadDrift(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"}, []driver.Value{true})
+	r := NewBypassRLSResource()
+	testConfigure(t, r, fake)
+
+	state := testResourceState(t, r, bypassrlsModel{
+		Role:    "app",
+		Enabled: false,
+		SQL:     types.StringValue(sqlSetBypassRLS("app", false)),
+	})
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() error = %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("Read() added %d warnings, want a drift warning", resp.Diagnostics.WarningsCount())
+	}
+
+	var refreshed bypassrlsModel
+	resp.State.Get(ctx, &refreshed)
+	if !refreshed.Enabled {
+		t.Error("Read() enabled = false, want the value found in the database")
+	}
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewEffectiveIOConcurrencyResource is a helper function to simplify the provider implementation.
+func NewEffectiveIOConcurrencyResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "effective_io_concurrency",
+		description: `Manage effective_io_concurrency and maintenance_io_concurrency for an existing role. Settings left unset are reset to the database default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-resource.html#GUC-EFFECTIVE-IO-CONCURRENCY) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "effective_io_concurrency",
+				Parameter:   "effective_io_concurrency",
+				Description: "The number of concurrent disk I/O operations the server expects to execute, between 0 and 1000.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.Between(0, 1000),
+				},
+			},
+			{
+				Attribute:   "maintenance_io_concurrency",
+				Parameter:   "maintenance_io_concurrency",
+				Description: "Similar to effective_io_concurrency, but used for maintenance work, between 0 and 1000.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.Between(0, 1000),
+				},
+			},
+		},
+	}
+}
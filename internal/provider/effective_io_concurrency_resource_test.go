@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestEffectiveIOConcurrencyResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_effective_io_concurrency" "test" {
+  role                     = "example_user"
+  effective_io_concurrency = 200
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_effective_io_concurrency.test", "effective_io_concurrency", "200"),
+					resource.TestCheckResourceAttr("pgrole_effective_io_concurrency.test", "sql", `ALTER ROLE "example_user" SET effective_io_concurrency = 200;
+ALTER ROLE "example_user" RESET maintenance_io_concurrency;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_effective_io_concurrency.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET effective_io_concurrency = 1;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*effectiveSettingDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*effectiveSettingDataSource)(nil)
+)
+
+// NewEffectiveSettingDataSource is a helper function to simplify the provider implementation.
+func NewEffectiveSettingDataSource() datasource.DataSource {
+	return &effectiveSettingDataSource{}
+}
+
+type effectiveSettingDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type effectiveSettingDataSourceModel struct {
+	Connection types.String `tfsdk:"connection"`
+	Role       string       `tfsdk:"role"`
+	Database   string       `tfsdk:"database"`
+	Name       string       `tfsdk:"name"`
+	Value      types.String `tfsdk:"value"`
+	Source     types.String `tfsdk:"source"`
+	Levels     types.Map    `tfsdk:"levels"`
+}
+
+// Levels a parameter may be set at, from the most to the least specific.
+const (
+	settingLevelRoleInDatabase = "role_in_database"
+	settingLevelRole           = "role"
+	settingLevelDatabase       = "database"
+	settingLevelServer         = "server"
+)
+
+// settingLevels are the levels a parameter may be set at, in precedence
+// order.
+var settingLevels = []string{settingLevelRoleInDatabase, settingLevelRole, settingLevelDatabase, settingLevelServer}
+
+// Metadata returns the data source type name.
+func (d *effectiveSettingDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_effective_setting"
+}
+
+// Schema defines the schema for the data source.
+func (d *effectiveSettingDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Resolves the value a configuration parameter takes in the sessions of a role connected to a database, and the level it comes from, following the precedence of PostgreSQL: ALTER ROLE ... IN DATABASE ... SET, then ALTER ROLE ... SET, then ALTER DATABASE ... SET, then the server configuration.
+
+Useful to debug why a setting is not applied, e.g. a statement_timeout of a role shadowed by one set for the role in a single database. Settings changed by the client, e.g. with SET or PGOPTIONS, are not visible.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the settings through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+			},
+			"database": schema.StringAttribute{
+				Description: "Name of the database the role connects to.",
+				Required:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "Name of the configuration parameter, e.g. \"statement_timeout\" or \"pgaudit.log\".",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(parameterNameRe, "must be a lowercase parameter name"),
+				},
+			},
+			"value": schema.StringAttribute{
+				Description: "The effective value of the parameter, as set at its source level, e.g. \"30s\". Null if the parameter is set at no level, e.g. a parameter of an extension that is not loaded.",
+				Computed:    true,
+			},
+			"source": schema.StringAttribute{
+				Description: "The level the effective value comes from, one of \"role_in_database\", \"role\", \"database\" or \"server\". Null if value is null.",
+				Computed:    true,
+			},
+			"levels": schema.MapAttribute{
+				Description: "The value set at each level setting the parameter, by level, including those shadowed by a more specific one. The server level is the value of the server configuration with its unit, e.g. \"30000ms\", or the built-in default if the role or the database of the provider connection override it.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *effectiveSettingDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *effectiveSettingDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data effectiveSettingDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	levels, err := readSettingLevels(ctx, db, data.Role, data.Database, data.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role or database not found",
+			fmt.Sprintf("Role %s or database %s does not exist.", data.Role, data.Database),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query settings",
+			fmt.Sprintf("Failed to query parameter %s for role %s in database %s: %s", data.Name, data.Role, data.Database, err),
+		)
+		return
+	}
+
+	data.Value, data.Source = types.StringNull(), types.StringNull()
+	if value, source, ok := effectiveSetting(levels); ok {
+		data.Value, data.Source = types.StringValue(value), types.StringValue(source)
+	}
+	data.Levels, _ = types.MapValueFrom(ctx, types.StringType, levels)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// sqlSettingLevels reads the settings of role $1 in database $2, of the role,
+// and of the database, followed by the server value of parameter $3 and its
+// unit, and no row if the role or the database does not exist. The reset value
+// of the session is its own default, unless it comes from settings of the
+// connecting role or database, in which case the built-in default is the best
+// approximation of the server one.
+const sqlSettingLevels = `
+SELECT
+	(SELECT s.setconfig FROM pg_db_role_setting s WHERE s.setrole = r.oid AND s.setdatabase = d.oid),
+	(SELECT s.setconfig FROM pg_db_role_setting s WHERE s.setrole = r.oid AND s.setdatabase = 0),
+	(SELECT s.setconfig FROM pg_db_role_setting s WHERE s.setrole = 0 AND s.setdatabase = d.oid),
+	(SELECT CASE WHEN source IN ('client', 'database', 'user', 'database user') THEN boot_val ELSE reset_val END
+		FROM pg_settings WHERE name = $3),
+	(SELECT unit FROM pg_settings WHERE name = $3)
+FROM pg_roles r, pg_database d
+WHERE r.rolname = $1 AND d.datname = $2;`
+
+// readSettingLevels returns the value of parameter set at each level for role
+// in database, by level, or sql.ErrNoRows if the role or the database does not
+// exist.
+func readSettingLevels(ctx context.Context, db *sql.DB, role, database, parameter string) (map[string]string, error) {
+	var roleDatabase, roleAll, databaseAll pq.StringArray
+	var server, unit sql.NullString
+	err := db.QueryRowContext(ctx, sqlSettingLevels, role, database, parameter).Scan(&roleDatabase, &roleAll, &databaseAll, &server, &unit)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := map[string]string{}
+	for i, config := range []pq.StringArray{roleDatabase, roleAll, databaseAll} {
+		if value, ok := parseRoleConfig(config)[parameter]; ok {
+			levels[settingLevels[i]] = value
+		}
+	}
+	if server.Valid {
+		levels[settingLevelServer] = withUnit(server.String, unit.String)
+	}
+	return levels, nil
+}
+
+// withUnit returns value, a number of unit as read from pg_settings, with its
+// unit, e.g. "30000ms" for "30000" in "ms", or "1024kB" for "128" in "8kB".
+// Other values are returned unchanged.
+func withUnit(value, unit string) string {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if unit == "" || err != nil {
+		return value
+	}
+	if m := unitValueRe.FindStringSubmatch(unit); m != nil {
+		multiple, _ := strconv.ParseInt(m[1], 10, 64)
+		return fmt.Sprintf("%d%s", v*multiple, m[2])
+	}
+	return value + unit
+}
+
+// effectiveSetting returns the value of the most specific level of levels and
+// that level, or false if the parameter is set at no level.
+func effectiveSetting(levels map[string]string) (value, source string, ok bool) {
+	for _, level := range settingLevels {
+		if value, ok := levels[level]; ok {
+			return value, level, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadSettingLevels(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"role_in_database", "role", "database", "server", "unit"}
+	tests := []struct {
+		name       string
+		row        []driver.Value
+		wantLevels map[string]string
+		wantValue  string
+		wantSource string
+	}{
+		{
+			name:       "role in database shadows role",
+			row:        []driver.Value{"{statement_timeout=5s}", "{statement_timeout=30s,work_mem=64MB}", nil, "0", "ms"},
+			wantLevels: map[string]string{"role_in_database": "5s", "role": "30s", "server": "0ms"},
+			wantValue:  "5s",
+			wantSource: "role_in_database",
+		},
+		{
+			name:       "database",
+			row:        []driver.Value{"{work_mem=64MB}", nil, "{statement_timeout=1min}", "0", "ms"},
+			wantLevels: map[string]string{"database": "1min", "server": "0ms"},
+			wantValue:  "1min",
+			wantSource: "database",
+		},
+		{
+			name:       "server",
+			row:        []driver.Value{nil, nil, nil, "0", "ms"},
+			wantLevels: map[string]string{"server": "0ms"},
+			wantValue:  "0ms",
+			wantSource: "server",
+		},
+		{
+			name:       "unknown parameter",
+			row:        []driver.Value{nil, nil, nil, nil, nil},
+			wantLevels: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakedb.New().ExpectQuery(`pg_db_role_setting`, columns, tt.row)
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			levels, err := readSettingLevels(ctx, db, "app", "app", "statement_timeout")
+			if err != nil {
+				t.Fatalf("readSettingLevels() error = %v", err)
+			}
+			if !reflect.DeepEqual(levels, tt.wantLevels) {
+				t.Errorf("readSettingLevels() = %v, want %v", levels, tt.wantLevels)
+			}
+			value, source, ok := effectiveSetting(levels)
+			if value != tt.wantValue || source != tt.wantSource || ok != (tt.wantSource != "") {
+				t.Errorf("effectiveSetting() = %q, %q, %v, want %q, %q", value, source, ok, tt.wantValue, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestReadSettingLevelsNotFound(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`pg_db_role_setting`, []string{"role_in_database", "role", "database", "server", "unit"})
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	if _, err := readSettingLevels(ctx, db, "missing", "app", "statement_timeout"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("readSettingLevels() error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestWithUnit(t *testing.T) {
+	tests := []struct {
+		value, unit, want string
+	}{
+		{"30000", "ms", "30000ms"},
+		{"128", "8kB", "1024kB"},
+		{"-1", "kB", "-1kB"},
+		{"on", "", "on"},
+		{"read committed", "", "read committed"},
+	}
+	for _, tt := range tests {
+		if got := withUnit(tt.value, tt.unit); got != tt.want {
+			t.Errorf("withUnit(%q, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveSettingDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "pgrole_statement_timeout" "test" {
+  role    = "test"
+  timeout = "30s"
+}
+
+data "pgrole_effective_setting" "test" {
+  role     = pgrole_statement_timeout.test.role
+  database = "postgres"
+  name     = "statement_timeout"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pgrole_effective_setting.test", "value", "30s"),
+					resource.TestCheckResourceAttr("data.pgrole_effective_setting.test", "source", "role"),
+					resource.TestCheckResourceAttr("data.pgrole_effective_setting.test", "levels.role", "30s"),
+					resource.TestCheckResourceAttrSet("data.pgrole_effective_setting.test", "levels.server"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// eventAuditModel describes the event_audit provider attribute.
+type eventAuditModel struct {
+	Table    types.String `tfsdk:"table"`
+	RunID    types.String `tfsdk:"run_id"`
+	Operator types.String `tfsdk:"operator"`
+}
+
+// providerEventAuditAttribute returns the schema of the event_audit provider
+// attribute.
+func providerEventAuditAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "Records every SQL statement applying changes into a table of the database it runs in, giving DBAs a durable change trail, including the termination of sessions by pgrole_login and by the drain of pgrole_connection_limit. The passwords set by pgrole_password are recorded masked. Each statement is recorded in its transaction, so that only the changes actually applied are recorded, and a change fails if it cannot be recorded. The table must exist in every database the provider changes, with the columns executed_at (timestamptz), run_id (text), operator (text) and statement (text), and the connecting role must be able to insert into it.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"table": schema.StringAttribute{
+				Description: "The table the statements are recorded into, as schema.table, e.g. \"audit.pgrole_events\".",
+				Required:    true,
+			},
+			"run_id": schema.StringAttribute{
+				Description: "Identifier of the Terraform run recorded with each statement, e.g. the ID of a CI pipeline. Defaults to the TFC_RUN_ID environment variable set by HCP Terraform, and to a random identifier generated for each run of the provider otherwise.",
+				Optional:    true,
+			},
+			"operator": schema.StringAttribute{
+				Description: "Identity of the operator recorded with each statement, e.g. the email of the person or the name of the CI service account applying the changes. Defaults to the OS user running Terraform.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// eventAudit records the SQL statements of the provider into a table. The
+// nil eventAudit records nothing.
+type eventAudit struct {
+	// insert is the statement inserting a record, with the run ID, the
+	// operator and the statement as parameters.
+	insert   string
+	runID    string
+	operator string
+}
+
+// newEventAudit returns the event audit configured by m, or nil if m is nil.
+func newEventAudit(m *eventAuditModel) (*eventAudit, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if m == nil {
+		return nil, diags
+	}
+
+	p := path.Root("event_audit")
+	if m.Table.IsUnknown() || m.RunID.IsUnknown() || m.Operator.IsUnknown() {
+		diags.AddAttributeError(p, "unknown event_audit", "unknown event_audit")
+		return nil, diags
+	}
+	schemaName, tableName, ok := strings.Cut(m.Table.ValueString(), ".")
+	if !ok || schemaName == "" || tableName == "" || strings.Contains(tableName, ".") {
+		diags.AddAttributeError(p.AtName("table"), "Invalid event_audit configuration", fmt.Sprintf("%q is not a table name of the form schema.table.", m.Table.ValueString()))
+		return nil, diags
+	}
+
+	a := &eventAudit{
+		insert: fmt.Sprintf(
+			"INSERT INTO %s.%s (executed_at, run_id, operator, statement) VALUES (now(), $1, $2, $3)",
+			quoteIdentifier(schemaName), quoteIdentifier(tableName),
+		),
+		runID:    m.RunID.ValueString(),
+		operator: m.Operator.ValueString(),
+	}
+	if m.RunID.IsNull() {
+		a.runID = defaultRunID()
+	}
+	if m.Operator.IsNull() {
+		a.operator = defaultOperator()
+	}
+	return a, diags
+}
+
+// defaultRunID returns the ID of the HCP Terraform run, or a random ID
+// shared by the statements of this run of the provider.
+func defaultRunID() string {
+	if id := os.Getenv("TFC_RUN_ID"); id != "" {
+		return id
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// defaultOperator returns the name of the OS user running the provider.
+func defaultOperator() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// record inserts the record of sqlstr in tx, so that it is committed with
+// the statement.
+func (a *eventAudit) record(ctx context.Context, tx *sql.Tx, sqlstr string) error {
+	if a == nil {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, a.insert, a.runID, a.operator, sqlstr); err != nil {
+		return fmt.Errorf("failed to record the statement in the event_audit table: %w", err)
+	}
+	return nil
+}
+
+// queryRowAudited runs query, which returns a single row scanned into dest,
+// for the statements with side effects that are not run by execWithRetry,
+// e.g. terminating sessions. With audit, query is recorded first, in the same
+// transaction, so that its effects are never left unrecorded.
+func queryRowAudited(ctx context.Context, db *sql.DB, audit *eventAudit, query string, dest ...any) error {
+	if audit == nil {
+		return db.QueryRowContext(ctx, query).Scan(dest...)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return canceled(ctx, err)
+	}
+	err = audit.record(ctx, tx, query)
+	if err == nil {
+		err = tx.QueryRowContext(ctx, query).Scan(dest...)
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			tflog.Warn(ctx, "Failed to roll back transaction", map[string]any{
+				"error": rollbackErr.Error(),
+			})
+		}
+		return canceled(ctx, err)
+	}
+	return canceled(ctx, tx.Commit())
+}
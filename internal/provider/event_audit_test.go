@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestExecWithRetryEventAudit(t *testing.T) {
+	ctx := context.Background()
+	sqlstr := `ALTER ROLE "app" SET work_mem = '64MB';`
+	audit, diags := newEventAudit(&eventAuditModel{
+		Table:    types.StringValue("audit.pgrole_events"),
+		RunID:    types.StringValue("run-1"),
+		Operator: types.StringValue("ci@example.com"),
+	})
+	if diags.HasError() {
+		t.Fatalf("newEventAudit() = %v", diags)
+	}
+	policy := defaultRetryPolicy
+	policy.audit = audit
+
+	fake := fakedb.New()
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		t.Fatalf("execWithRetry() error = %v", err)
+	}
+	want := []fakedb.Statement{
+		{SQL: "BEGIN"},
+		{SQL: sqlstr, Args: []driver.Value{}},
+		{
+			SQL:  `INSERT INTO "audit"."pgrole_events" (executed_at, run_id, operator, statement) VALUES (now(), $1, $2, $3)`,
+			Args: []driver.Value{"run-1", "ci@example.com", sqlstr},
+		},
+		{SQL: "COMMIT"},
+	}
+	if got := fake.Execs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("execWithRetry() ran %q, want %q", got, want)
+	}
+
+	fake = fakedb.New().ExpectError(`INSERT INTO "audit"`, errors.New(`relation "audit.pgrole_events" does not exist`))
+	db, _ = fake.GetDB(ctx)
+	defer db.Close()
+	err := execWithRetry(ctx, db, policy, sqlstr)
+	if err == nil || !strings.Contains(err.Error(), "event_audit") {
+		t.Fatalf("execWithRetry() error = %v, want an event_audit error", err)
+	}
+	var got []string
+	for _, s := range fake.Execs() {
+		got = append(got, s.SQL)
+	}
+	if want := []string{"BEGIN", sqlstr, "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("execWithRetry() ran %q, want %q", got, want)
+	}
+}
+
+func TestPasswordEventAuditMasked(t *testing.T) {
+	ctx := context.Background()
+	audit, _ := newEventAudit(&eventAuditModel{
+		Table:    types.StringValue("audit.pgrole_events"),
+		RunID:    types.StringValue("run-1"),
+		Operator: types.StringValue("ci@example.com"),
+	})
+	policy := defaultRetryPolicy
+	policy.audit = audit
+	fake := fakedb.New().ExpectQuery(`FROM pg_roles r, pg_roles t`,
+		[]string{"rolname", "rolsuper", "rolcreaterole", "rolbypassrls", "rolreplication", "cloudsqlsuperuser", "admin", "target_super", "server_version_num"},
+		[]driver.Value{"postgres", true, false, false, false, false, true, false, int64(160000)},
+	)
+	r := &passwordResource{
+		connect: func(string, string) DBGetter { return fake },
+		retry:   policy,
+	}
+
+	var diags diag.Diagnostics
+	m := passwordResourceModel{
+		Role:       "app",
+		PasswordWO: types.StringValue("Correct-Horse-1"),
+	}
+	if !r.setPassword(ctx, &diags, "create", &m) {
+		t.Fatalf("setPassword() error = %v", diags)
+	}
+	var recorded []driver.Value
+	for _, s := range fake.Execs() {
+		if strings.HasPrefix(s.SQL, "INSERT INTO") {
+			recorded = s.Args
+		}
+		if strings.Contains(s.SQL, "PASSWORD") && !strings.Contains(s.SQL, "SCRAM-SHA-256$") {
+			t.Errorf("setPassword() ran %q, want the verifier set", s.SQL)
+		}
+	}
+	if len(recorded) != 3 {
+		t.Fatalf("setPassword() recorded %v, want one event_audit record", recorded)
+	}
+	if got, want := recorded[2], `ALTER ROLE "app" PASSWORD '********';`; got != want {
+		t.Errorf("setPassword() recorded %q, want %q", got, want)
+	}
+}
+
+func TestNewEventAudit(t *testing.T) {
+	t.Setenv("TFC_RUN_ID", "run-abc")
+	audit, diags := newEventAudit(&eventAuditModel{
+		Table:    types.StringValue("audit.events"),
+		RunID:    types.StringNull(),
+		Operator: types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("newEventAudit() = %v", diags)
+	}
+	if audit.runID != "run-abc" {
+		t.Errorf("runID = %q, want the TFC_RUN_ID", audit.runID)
+	}
+
+	if audit, _ := newEventAudit(nil); audit != nil {
+		t.Errorf("newEventAudit(nil) = %+v, want nil", audit)
+	}
+
+	for _, table := range []string{"events", "audit.", "a.b.c"} {
+		_, diags := newEventAudit(&eventAuditModel{
+			Table:    types.StringValue(table),
+			RunID:    types.StringNull(),
+			Operator: types.StringNull(),
+		})
+		if !diags.HasError() {
+			t.Errorf("newEventAudit(%q) succeeded, want error", table)
+		}
+	}
+}
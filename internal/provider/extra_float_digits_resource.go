@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewExtraFloatDigitsResource is a helper function to simplify the provider implementation.
+func NewExtraFloatDigitsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "extra_float_digits",
+		description: `Manage extra_float_digits for an existing role, e.g. for ETL roles that require exact float round-tripping.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-EXTRA-FLOAT-DIGITS) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "digits",
+				Parameter:   "extra_float_digits",
+				Description: "The number of digits displayed for floating-point values, between -15 and 3. Positive values output the shortest-precise format.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.Between(-15, 3),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestExtraFloatDigitsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_extra_float_digits" "test" {
+  role   = "example_user"
+  digits = -2
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_extra_float_digits.test", "digits", "-2"),
+					resource.TestCheckResourceAttr("pgrole_extra_float_digits.test", "sql", `ALTER ROLE "example_user" SET extra_float_digits = -2;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_extra_float_digits.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET extra_float_digits = 3;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/certs"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+// gcpOptions are the settings of the Google Cloud clients used by Cloud SQL
+// connections and to mint IAM database authentication tokens.
+type gcpOptions struct {
+	// quotaProject is the project billed for the API calls instead of the
+	// project of the credentials, if not empty.
+	quotaProject string
+	// universeDomain is the domain of the Google Cloud universe of the
+	// instance, e.g. of a Trusted Partner Cloud, if not googleapis.com.
+	universeDomain string
+	// sqladminEndpoint and iamCredentialsEndpoint are the base URLs of the
+	// Cloud SQL Admin and IAM Credentials APIs, e.g. of Private Service
+	// Connect endpoints, if not empty.
+	sqladminEndpoint       string
+	iamCredentialsEndpoint string
+	// certFetchTimeout bounds the exchange of the certificates of the
+	// instance with the Cloud SQL Admin API, retries included.
+	certFetchTimeout time.Duration
+}
+
+// defaultCertFetchTimeout is the default of certFetchTimeout.
+const defaultCertFetchTimeout = 30 * time.Second
+
+// certFetchAttempts is the maximum number of attempts of each request to the
+// Cloud SQL Admin API, failing with a transient error, within
+// certFetchTimeout.
+const certFetchAttempts = 3
+
+// certFetchBackoff is the delay before the first retry of a request to the
+// Cloud SQL Admin API, doubled after each attempt.
+var certFetchBackoff = 500 * time.Millisecond
+
+// clientOptions returns the options of the Google API clients, e.g. of the
+// IAM Credentials API impersonating service accounts.
+func (o gcpOptions) clientOptions() []option.ClientOption {
+	var opts []option.ClientOption
+	if o.quotaProject != "" {
+		opts = append(opts, option.WithQuotaProject(o.quotaProject))
+	}
+	if o.universeDomain != "" {
+		opts = append(opts, option.WithUniverseDomain(o.universeDomain))
+	}
+	if o.iamCredentialsEndpoint != "" {
+		opts = append(opts, option.WithEndpoint(o.iamCredentialsEndpoint))
+	}
+	return opts
+}
+
+// certSource returns the source of the certificates of Cloud SQL instances,
+// fetched from the Cloud SQL Admin API with ts. Like every client of the
+// provider, it goes through the proxy set by HTTPS_PROXY, if any.
+func (o gcpOptions) certSource(ts oauth2.TokenSource) (*certs.RemoteCertSource, error) {
+	var transport http.RoundTripper = gcp.DefaultTransport()
+	if o.quotaProject != "" {
+		transport = quotaProjectTransport{base: transport, project: o.quotaProject}
+	}
+	transport = certFetchTransport{base: transport, timeout: o.certFetchTimeout}
+	client, err := gcp.NewHTTPClient(transport, ts)
+	if err != nil {
+		return nil, err
+	}
+	opts := certs.RemoteOpts{EnableIAMLogin: true, TokenSource: ts}
+	switch {
+	case o.sqladminEndpoint != "":
+		// API paths are resolved relative to the base path
+		opts.APIBasePath = strings.TrimSuffix(o.sqladminEndpoint, "/") + "/"
+	case o.universeDomain != "":
+		opts.APIBasePath = "https://sqladmin." + o.universeDomain + "/"
+	}
+	return certs.NewCertSourceOpts(&client.Client, opts), nil
+}
+
+// quotaProjectTransport bills the requests it sends to project.
+type quotaProjectTransport struct {
+	base    http.RoundTripper
+	project string
+}
+
+// RoundTrip adds the quota project header to req.
+func (t quotaProjectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Goog-User-Project", t.project)
+	return t.base.RoundTrip(req)
+}
+
+// certFetchTransport bounds the requests it sends by timeout, and retries
+// them on transient errors within it, so that a slow Cloud SQL Admin API
+// fails the connection with a clear error rather than stalling the apply.
+type certFetchTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip sends req, retrying on network errors, rate limits and server
+// errors.
+func (t certFetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	backoff := certFetchBackoff
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(ctx)
+		if attempt > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		transient := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		// Requests whose body cannot be sent again are not retried
+		if !transient || attempt == certFetchAttempts || (req.Body != nil && req.GetBody == nil) {
+			if err != nil {
+				cancel()
+				if ctx.Err() == context.DeadlineExceeded && req.Context().Err() == nil {
+					return nil, fmt.Errorf("the Cloud SQL Admin API did not respond within cert_fetch_timeout (%s), on attempt %d: %w", t.timeout, attempt, err)
+				}
+				return nil, err
+			}
+			resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			if req.Context().Err() == nil {
+				return nil, fmt.Errorf("the Cloud SQL Admin API did not respond within cert_fetch_timeout (%s), after %d attempts", t.timeout, attempt)
+			}
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// cancelOnClose cancels the context of a response when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context.
+func (b cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// gcpCache caches the token source and the certificate source of a
+// connection, shared by the getters of all its databases, since creating them
+// for each operation takes seconds: impersonated token sources mint a new
+// token, and certificate sources generate an RSA key. A nil cache caches
+// nothing.
+type gcpCache struct {
+	mu          sync.Mutex
+	tokenSource oauth2.TokenSource
+	certSource  *certs.RemoteCertSource
+}
+
+// getTokenSource returns the cached token source, created with newTokenSource
+// on first use.
+func (c *gcpCache) getTokenSource(ctx context.Context, newTokenSource func(context.Context) (oauth2.TokenSource, error)) (oauth2.TokenSource, error) {
+	if c == nil {
+		return newTokenSource(ctx)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokenSource == nil {
+		// Token sources keep their context to refresh tokens, past the
+		// operation creating them
+		ts, err := newTokenSource(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.tokenSource = ts
+	}
+	return c.tokenSource, nil
+}
+
+// getCertSource returns the cached certificate source, created with
+// opts.certSource on first use.
+func (c *gcpCache) getCertSource(ts oauth2.TokenSource, opts gcpOptions) (*certs.RemoteCertSource, error) {
+	if c == nil {
+		return opts.certSource(ts)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.certSource == nil {
+		certSource, err := opts.certSource(ts)
+		if err != nil {
+			return nil, err
+		}
+		c.certSource = certSource
+	}
+	return c.certSource, nil
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestQuotaProjectTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Goog-User-Project")
+	}))
+	defer server.Close()
+
+	client := http.Client{Transport: quotaProjectTransport{base: http.DefaultTransport, project: "billing-project"}}
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "billing-project" {
+		t.Errorf("X-Goog-User-Project = %q, want %q", got, "billing-project")
+	}
+	if req.Header.Get("X-Goog-User-Project") != "" {
+		t.Error("RoundTrip() modified the original request")
+	}
+}
+
+func TestGCPOptionsClientOptions(t *testing.T) {
+	tests := []struct {
+		opts gcpOptions
+		want int
+	}{
+		{opts: gcpOptions{}, want: 0},
+		{opts: gcpOptions{quotaProject: "billing-project"}, want: 1},
+		{opts: gcpOptions{quotaProject: "billing-project", universeDomain: "example.goog"}, want: 2},
+		{opts: gcpOptions{iamCredentialsEndpoint: "https://iamcredentials-psc.p.googleapis.com/"}, want: 1},
+	}
+	for _, tt := range tests {
+		if got := len(tt.opts.clientOptions()); got != tt.want {
+			t.Errorf("%+v.clientOptions() = %d options, want %d", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestGCPOptionsCertSource(t *testing.T) {
+	var path, project string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, project = r.URL.Path, r.Header.Get("X-Goog-User-Project")
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	opts := gcpOptions{quotaProject: "billing-project", sqladminEndpoint: server.URL + "/psc"}
+	certSource, err := opts.certSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	if err != nil {
+		t.Fatalf("certSource() error = %v", err)
+	}
+	if _, _, _, _, err := certSource.Remote("my-project:europe-west1:my-instance"); err == nil {
+		t.Fatal("Remote() error = nil, want the error of the fake API")
+	}
+	if !strings.HasPrefix(path, "/psc/sql/v1beta4/projects/my-project/") {
+		t.Errorf("Remote() requested %q, want the configured endpoint", path)
+	}
+	if project != "billing-project" {
+		t.Errorf("X-Goog-User-Project = %q, want %q", project, "billing-project")
+	}
+}
+
+func TestEndpointRe(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     bool
+	}{
+		{"https://sqladmin.googleapis.com/", true},
+		{"https://sqladmin-psc.p.googleapis.com", true},
+		{"http://localhost:8080/sqladmin/", true},
+		{"sqladmin.googleapis.com", false},
+		{"https://sqladmin.googleapis.com/?alt=json", false},
+	}
+	for _, tt := range tests {
+		if got := endpointRe.MatchString(tt.endpoint); got != tt.want {
+			t.Errorf("endpointRe.MatchString(%q) = %t, want %t", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestGCPCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	created := 0
+	newTokenSource := func(ctx context.Context) (oauth2.TokenSource, error) {
+		created++
+		if created == 1 {
+			return nil, errors.New("metadata server unavailable")
+		}
+		if ctx.Done() != nil {
+			t.Error("getTokenSource() created the token source with a cancelable context, want it to outlive the operation")
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}), nil
+	}
+
+	cache := &gcpCache{}
+	if _, err := cache.getTokenSource(ctx, newTokenSource); err == nil {
+		t.Fatal("getTokenSource() error = nil, want the error of the token source")
+	}
+	ts, err := cache.getTokenSource(ctx, newTokenSource)
+	if err != nil {
+		t.Fatalf("getTokenSource() error = %v", err)
+	}
+	cancel()
+	if again, _ := cache.getTokenSource(ctx, newTokenSource); again != ts || created != 2 {
+		t.Errorf("getTokenSource() created %d token sources, want the second one cached", created)
+	}
+
+	certSource, err := cache.getCertSource(ts, gcpOptions{})
+	if err != nil {
+		t.Fatalf("getCertSource() error = %v", err)
+	}
+	if again, _ := cache.getCertSource(ts, gcpOptions{}); again != certSource {
+		t.Error("getCertSource() created a new certificate source, want the cached one")
+	}
+
+	// Without a cache, each call creates them
+	var none *gcpCache
+	if _, err := none.getTokenSource(context.Background(), newTokenSource); err != nil || created != 3 {
+		t.Errorf("getTokenSource() without cache created %d token sources, want 3", created)
+	}
+}
+
+func TestCertFetchTransport(t *testing.T) {
+	backoff := certFetchBackoff
+	certFetchBackoff = time.Millisecond
+	t.Cleanup(func() { certFetchBackoff = backoff })
+
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		timeout      time.Duration
+		delay        time.Duration
+		wantAttempts int
+		wantStatus   int
+		wantErr      string
+	}{
+		{name: "success", wantAttempts: 1, wantStatus: http.StatusOK},
+		{name: "transient errors", failures: 2, status: http.StatusServiceUnavailable, wantAttempts: 3, wantStatus: http.StatusOK},
+		{name: "persistent errors", failures: 5, status: http.StatusTooManyRequests, wantAttempts: certFetchAttempts, wantStatus: http.StatusTooManyRequests},
+		{name: "client error", failures: 5, status: http.StatusForbidden, wantAttempts: 1, wantStatus: http.StatusForbidden},
+		{name: "timeout", timeout: 50 * time.Millisecond, delay: time.Second, wantAttempts: 1, wantErr: "did not respond within cert_fetch_timeout (50ms)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if body, _ := io.ReadAll(r.Body); string(body) != `{"public_key":"key"}` {
+					t.Errorf("request body = %q, want it sent again on each attempt", body)
+				}
+				if attempts <= tt.failures {
+					http.Error(w, "unavailable", tt.status)
+					return
+				}
+				select {
+				case <-r.Context().Done():
+				case <-time.After(tt.delay):
+				}
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			client := http.Client{Transport: certFetchTransport{base: http.DefaultTransport, timeout: timeout}}
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"public_key":"key"}`))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Post() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Post() error = %v", err)
+				}
+				io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("Post() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Post() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewGEQOSettingsResource is a helper function to simplify the provider implementation.
+func NewGEQOSettingsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "geqo_settings",
+		description: `Manage the genetic query optimizer (GEQO) settings of an existing role, e.g. for tuning analytical roles. Settings left unset are reset to the database default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-query.html#RUNTIME-CONFIG-QUERY-GEQO) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "geqo",
+				Parameter:   "geqo",
+				Description: "Whether genetic query optimization is enabled.",
+				Type:        settingBool,
+			},
+			{
+				Attribute:   "geqo_threshold",
+				Parameter:   "geqo_threshold",
+				Description: "The number of FROM items from which genetic query optimization is used.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(2),
+				},
+			},
+			{
+				Attribute:   "geqo_effort",
+				Parameter:   "geqo_effort",
+				Description: "The trade-off between planning time and plan quality, between 1 and 10.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.Between(1, 10),
+				},
+			},
+			{
+				Attribute:   "geqo_pool_size",
+				Parameter:   "geqo_pool_size",
+				Description: "The number of individuals in the genetic population, 0 to choose based on geqo_effort.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "geqo_generations",
+				Parameter:   "geqo_generations",
+				Description: "The number of generations of the algorithm, 0 to choose based on geqo_pool_size.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "geqo_selection_bias",
+				Parameter:   "geqo_selection_bias",
+				Description: "The selective pressure within the population, between 1.5 and 2.0.",
+				Type:        settingFloat64,
+				Float64Validators: []validator.Float64{
+					float64validator.Between(1.5, 2.0),
+				},
+			},
+			{
+				Attribute:   "geqo_seed",
+				Parameter:   "geqo_seed",
+				Description: "The initial value of the random number generator, between 0 and 1.",
+				Type:        settingFloat64,
+				Float64Validators: []validator.Float64{
+					float64validator.Between(0, 1),
+				},
+			},
+		},
+	}
+}
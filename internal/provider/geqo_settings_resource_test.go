@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestGEQOSettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_geqo_settings" "test" {
+  role                = "example_user"
+  geqo_threshold      = 14
+  geqo_selection_bias = 1.8
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_geqo_settings.test", "geqo_threshold", "14"),
+					resource.TestCheckResourceAttr("pgrole_geqo_settings.test", "geqo_selection_bias", "1.8"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_geqo_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET geqo_effort = 3;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
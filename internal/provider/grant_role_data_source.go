@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*grantRoleDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*grantRoleDataSource)(nil)
+)
+
+// NewGrantRoleDataSource is a helper function to simplify the provider implementation.
+func NewGrantRoleDataSource() datasource.DataSource {
+	return &grantRoleDataSource{}
+}
+
+type grantRoleDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type grantRoleDataSourceModel struct {
+	Connection types.String `tfsdk:"connection"`
+	Role       string       `tfsdk:"role"`
+	GrantRole  string       `tfsdk:"grant_role"`
+	Member     types.Bool   `tfsdk:"member"`
+	Inherits   types.Bool   `tfsdk:"inherits"`
+	Direct     types.Bool   `tfsdk:"direct"`
+}
+
+// grantRoleMembership is the membership of a role in another one.
+type grantRoleMembership struct {
+	Member   bool
+	Inherits bool
+	Direct   bool
+}
+
+// Metadata returns the data source type name.
+func (d *grantRoleDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_grant_role"
+}
+
+// Schema defines the schema for the data source.
+func (d *grantRoleDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Checks whether a role is a member of another role, directly or through other memberships, using [pg_has_role](https://www.postgresql.org/docs/current/functions-info.html#FUNCTIONS-INFO-ACCESS-TABLE).
+
+Useful for policy checks in CI, e.g. failing a plan with a precondition when an application role is a member of an administration role.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the roles through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"role": schema.StringAttribute{
+				Description: "Name of the role whose membership is checked.",
+				Required:    true,
+			},
+			"grant_role": schema.StringAttribute{
+				Description: "Name of the role that role may be a member of.",
+				Required:    true,
+			},
+			"member": schema.BoolAttribute{
+				Description: "Whether role is a member of grant_role, directly or transitively, or is grant_role itself.",
+				Computed:    true,
+			},
+			"inherits": schema.BoolAttribute{
+				Description: "Whether role holds the privileges of grant_role without SET ROLE, i.e. every membership on the way is inherited.",
+				Computed:    true,
+			},
+			"direct": schema.BoolAttribute{
+				Description: "Whether grant_role is granted to role directly.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *grantRoleDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *grantRoleDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data grantRoleDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	membership, err := readGrantRole(ctx, db, data.Role, data.GrantRole)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Role %s or role %s does not exist.", data.Role, data.GrantRole),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role membership",
+			fmt.Sprintf("Failed to query the membership of role %s in role %s: %s", data.Role, data.GrantRole, err),
+		)
+		return
+	}
+
+	data.Member = types.BoolValue(membership.Member)
+	data.Inherits = types.BoolValue(membership.Inherits)
+	data.Direct = types.BoolValue(membership.Direct)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// sqlGrantRole checks the membership of role $1 in role $2. Joining pg_roles
+// returns no row instead of failing when either role does not exist.
+const sqlGrantRole = `
+SELECT
+	pg_has_role(r.oid, g.oid, 'MEMBER'),
+	pg_has_role(r.oid, g.oid, 'USAGE'),
+	EXISTS (SELECT 1 FROM pg_auth_members m WHERE m.member = r.oid AND m.roleid = g.oid)
+FROM pg_roles r, pg_roles g
+WHERE r.rolname = $1 AND g.rolname = $2;`
+
+// readGrantRole returns the membership of role in grantRole, or sql.ErrNoRows
+// if either role does not exist.
+func readGrantRole(ctx context.Context, db *sql.DB, role, grantRole string) (grantRoleMembership, error) {
+	var m grantRoleMembership
+	err := db.QueryRowContext(ctx, sqlGrantRole, role, grantRole).Scan(&m.Member, &m.Inherits, &m.Direct)
+	return m, err
+}
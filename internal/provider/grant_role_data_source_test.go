@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadGrantRole(t *testing.T) {
+	columns := []string{"member", "usage", "direct"}
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		want    grantRoleMembership
+		wantErr error
+	}{
+		{
+			name: "direct",
+			rows: [][]driver.Value{{true, true, true}},
+			want: grantRoleMembership{Member: true, Inherits: true, Direct: true},
+		},
+		{
+			name: "transitive without inheritance",
+			rows: [][]driver.Value{{true, false, false}},
+			want: grantRoleMembership{Member: true},
+		},
+		{
+			name: "not a member",
+			rows: [][]driver.Value{{false, false, false}},
+		},
+		{
+			name:    "missing role",
+			wantErr: sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := fakedb.New().ExpectQuery(`pg_has_role`, columns, tt.rows...).GetDB(ctx)
+			if err != nil {
+				t.Fatalf("GetDB() error = %v", err)
+			}
+			defer db.Close()
+
+			got, err := readGrantRole(ctx, db, "app", "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readGrantRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readGrantRole() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
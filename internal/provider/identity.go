@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// roleIdentityModel describes the identity shared by all resources: the role,
+// the named provider connection if any and, for database-scoped resources,
+// the database.
+type roleIdentityModel struct {
+	Role       string       `tfsdk:"role"`
+	Connection types.String `tfsdk:"connection"`
+	Database   types.String `tfsdk:"database"`
+}
+
+// roleIdentitySchema returns the identity schema shared by all resources.
+func roleIdentitySchema() identityschema.Schema {
+	return identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"role": identityschema.StringAttribute{
+				Description:       "Name of the role.",
+				RequiredForImport: true,
+			},
+			"connection": identityschema.StringAttribute{
+				Description:       "Name of the provider connection, for resources not using the connection of the provider block.",
+				OptionalForImport: true,
+			},
+			"database": identityschema.StringAttribute{
+				Description:       "Name of the database, for database-scoped resources.",
+				OptionalForImport: true,
+			},
+		},
+	}
+}
+
+// setRoleIdentity stores the identity of a resource that applies to the role
+// in all databases, managed through the named connection or the provider one
+// if null.
+func setRoleIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, connection types.String, role string) diag.Diagnostics {
+	return setRoleDatabaseIdentity(ctx, identity, connection, role, "")
+}
+
+// setRoleDatabaseIdentity stores the identity of a resource that applies to
+// the role in database, or in all databases if database is empty.
+func setRoleDatabaseIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, connection types.String, role, database string) diag.Diagnostics {
+	if identity == nil {
+		return nil
+	}
+	m := roleIdentityModel{
+		Role:       role,
+		Connection: connection,
+		Database:   types.StringNull(),
+	}
+	if database != "" {
+		m.Database = types.StringValue(database)
+	}
+	return identity.Set(ctx, m)
+}
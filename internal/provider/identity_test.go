@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestImportStateIdentity(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"}, []driver.Value{false})
+
+	resp := testImportState(t, NewBypassRLSResource(), fake, "", &roleIdentityModel{
+		Role:     "sa@project.iam",
+		Database: types.StringNull(),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState() error = %v", resp.Diagnostics)
+	}
+
+	var role types.String
+	resp.State.GetAttribute(ctx, path.Root("role"), &role)
+	if role.ValueString() != "sa@project.iam" {
+		t.Errorf("ImportState() role = %s, want the role of the identity", role)
+	}
+	var identity roleIdentityModel
+	resp.Identity.Get(ctx, &identity)
+	if identity.Role != "sa@project.iam" || !identity.Database.IsNull() {
+		t.Errorf("ImportState() identity = %+v, want role sa@project.iam in all databases", identity)
+	}
+}
+
+func TestImportStateIdentityConnection(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"}, []driver.Value{false})
+
+	resp := testImportState(t, NewBypassRLSResource(), fake, "", &roleIdentityModel{
+		Role:       "app",
+		Connection: types.StringValue("replica"),
+		Database:   types.StringNull(),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState() error = %v", resp.Diagnostics)
+	}
+
+	var connection types.String
+	resp.State.GetAttribute(ctx, path.Root("connection"), &connection)
+	if connection.ValueString() != "replica" {
+		t.Errorf("ImportState() connection = %s, want the connection of the identity", connection)
+	}
+	var identity roleIdentityModel
+	resp.Identity.Get(ctx, &identity)
+	if identity.Connection.ValueString() != "replica" {
+		t.Errorf("ImportState() identity = %+v, want connection replica", identity)
+	}
+}
+
+func TestImportStateIdentityRejectsDatabase(t *testing.T) {
+	fake := fakedb.New().ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"}, []driver.Value{false})
+
+	resp := testImportState(t, NewBypassRLSResource(), fake, "", &roleIdentityModel{
+		Role:     "app",
+		Database: types.StringValue("mydb"),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Error("ImportState() with a database scope succeeded, want error for a role-wide resource")
+	}
+}
+
+func TestSetRoleDatabaseIdentity(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		database string
+		want     types.String
+	}{
+		{"", types.StringNull()},
+		{"mydb", types.StringValue("mydb")},
+	}
+	for _, tt := range tests {
+		schema := roleIdentitySchema()
+		resourceIdentity := &tfsdk.ResourceIdentity{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil)}
+		if diags := setRoleDatabaseIdentity(ctx, resourceIdentity, types.StringNull(), "app", tt.database); diags.HasError() {
+			t.Fatalf("setRoleDatabaseIdentity() error = %v", diags)
+		}
+		var identity roleIdentityModel
+		resourceIdentity.Get(ctx, &identity)
+		if identity.Role != "app" || !identity.Database.Equal(tt.want) {
+			t.Errorf("setRoleDatabaseIdentity(%q) = %+v, want database %s", tt.database, identity, tt.want)
+		}
+	}
+
+	// Identities are optional, e.g. before Terraform 1.12
+	if diags := setRoleDatabaseIdentity(ctx, nil, types.StringNull(), "app", "mydb"); diags.HasError() {
+		t.Errorf("setRoleDatabaseIdentity() without identity error = %v", diags)
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// parseImportID parses an import ID of the form "role" or "role@database".
+//
+// Role names may contain "@" themselves (e.g. Cloud SQL IAM users), so the ID
+// is split at the last "@". Use resolveImportID to disambiguate against the
+// roles that actually exist.
+func parseImportID(id string) (role, database string, err error) {
+	if id == "" {
+		return "", "", errors.New("import ID must not be empty")
+	}
+	i := strings.LastIndex(id, "@")
+	if i < 0 {
+		return id, "", nil
+	}
+	role, database = id[:i], id[i+1:]
+	if role == "" || database == "" {
+		return "", "", fmt.Errorf("invalid import ID %q, expected \"role\" or \"role@database\"", id)
+	}
+	return role, database, nil
+}
+
+// resolveImportID returns the role and database scope referenced by an
+// import ID. If a role named exactly id exists, it takes precedence over the
+// "role@database" form. The returned database is empty for role-wide imports.
+func resolveImportID(ctx context.Context, db *sql.DB, id string) (role, database string, err error) {
+	exists, err := roleExists(ctx, db, id)
+	if err != nil {
+		return "", "", err
+	}
+	if exists {
+		return id, "", nil
+	}
+
+	role, database, err = parseImportID(id)
+	if err != nil || database == "" {
+		return role, database, err
+	}
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1);", database).Scan(&exists); err != nil {
+		return "", "", err
+	}
+	if !exists {
+		return "", "", fmt.Errorf("database %q does not exist", database)
+	}
+	return role, database, nil
+}
+
+// importConnection returns the named provider connection of the resource
+// being imported, which can only be given by the resource identity of
+// Terraform 1.12+ import blocks. It is null for the provider connection.
+func importConnection(ctx context.Context, req resource.ImportStateRequest) (types.String, diag.Diagnostics) {
+	if req.ID != "" || req.Identity == nil {
+		return types.StringNull(), nil
+	}
+	var identity roleIdentityModel
+	diags := req.Identity.Get(ctx, &identity)
+	return identity.Connection, diags
+}
+
+// importRole resolves the role being imported, either from the import ID or,
+// for Terraform 1.12+ import blocks, from the resource identity. It is used by
+// resources that apply to the role as a whole and rejects database scopes.
+func importRole(ctx context.Context, db *sql.DB, req resource.ImportStateRequest) (string, diag.Diagnostics) {
+	role, database, diags := importRoleDatabase(ctx, db, req)
+	if diags.HasError() || database == "" {
+		return role, diags
+	}
+	if req.ID == "" {
+		diags.AddAttributeError(
+			path.Root("database"),
+			"Unsupported import identity",
+			"This resource applies to the role in all databases and cannot be imported with a database scope, remove database from the import identity.",
+		)
+		return "", diags
+	}
+	diags.AddError(
+		"Unsupported import ID",
+		fmt.Sprintf("This resource applies to the role in all databases and cannot be imported with a database scope, use %q as import ID instead of %q.", role, req.ID),
+	)
+	return "", diags
+}
+
+// importRoleDatabase resolves the role and the database scope being imported,
+// like importRole, for resources that can be scoped to a database. The
+// database is empty for role-wide imports.
+func importRoleDatabase(ctx context.Context, db *sql.DB, req resource.ImportStateRequest) (role, database string, diags diag.Diagnostics) {
+	if req.ID == "" && req.Identity != nil {
+		var identity roleIdentityModel
+		diags.Append(req.Identity.Get(ctx, &identity)...)
+		return identity.Role, identity.Database.ValueString(), diags
+	}
+
+	role, database, err := resolveImportID(ctx, db, req.ID)
+	if err != nil {
+		diags.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Failed to resolve import ID %q: %s", req.ID, err),
+		)
+		return "", "", diags
+	}
+	return role, database, diags
+}
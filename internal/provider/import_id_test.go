@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestParseImportID(t *testing.T) {
+	tests := []struct {
+		id       string
+		role     string
+		database string
+		wantErr  bool
+	}{
+		{id: "app", role: "app"},
+		{id: "app@mydb", role: "app", database: "mydb"},
+		{id: "sa@project.iam@mydb", role: "sa@project.iam", database: "mydb"},
+		{id: "", wantErr: true},
+		{id: "@mydb", wantErr: true},
+		{id: "app@", wantErr: true},
+	}
+	for _, tt := range tests {
+		role, database, err := parseImportID(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseImportID(%q) error = %v, wantErr %t", tt.id, err, tt.wantErr)
+			continue
+		}
+		if role != tt.role || database != tt.database {
+			t.Errorf("parseImportID(%q) = (%q, %q), want (%q, %q)", tt.id, role, database, tt.role, tt.database)
+		}
+	}
+}
+
+func TestResolveImportID(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		id         string
+		roleExists bool
+		dbExists   bool
+		role       string
+		database   string
+		wantErr    bool
+	}{
+		{"role", "app", true, false, "app", "", false},
+		{"role with @", "sa@project.iam", true, false, "sa@project.iam", "", false},
+		{"role in database", "app@mydb", false, true, "app", "mydb", false},
+		{"missing database", "app@mydb", false, false, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakedb.New().
+				ExpectQuery(`FROM pg_roles`, []string{"exists"}, []driver.Value{tt.roleExists}).
+				ExpectQuery(`FROM pg_database`, []string{"exists"}, []driver.Value{tt.dbExists})
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			role, database, err := resolveImportID(ctx, db, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveImportID(%q) error = %v, wantErr %t", tt.id, err, tt.wantErr)
+			}
+			if role != tt.role || database != tt.database {
+				t.Errorf("resolveImportID(%q) = (%q, %q), want (%q, %q)", tt.id, role, database, tt.role, tt.database)
+			}
+		})
+	}
+}
+
+// testImportState imports r with the given import ID, or with identity if
+// the ID is empty, against fake, and returns the response.
+func testImportState(t *testing.T, r resource.Resource, fake *fakedb.DB, id string, identity *roleIdentityModel) *resource.ImportStateResponse {
+	t.Helper()
+	ctx := context.Background()
+	testConfigure(t, r, fake)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	var identityResp resource.IdentitySchemaResponse
+	r.(resource.ResourceWithIdentity).IdentitySchema(ctx, resource.IdentitySchemaRequest{}, &identityResp)
+	identityType := identityResp.IdentitySchema.Type().TerraformType(ctx)
+
+	req := resource.ImportStateRequest{ID: id}
+	if identity != nil {
+		req.Identity = &tfsdk.ResourceIdentity{Schema: identityResp.IdentitySchema, Raw: tftypes.NewValue(identityType, nil)}
+		if diags := req.Identity.Set(ctx, identity); diags.HasError() {
+			t.Fatalf("Identity.Set() error = %v", diags)
+		}
+	}
+	resp := &resource.ImportStateResponse{
+		State:    tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)},
+		Identity: &tfsdk.ResourceIdentity{Schema: identityResp.IdentitySchema, Raw: tftypes.NewValue(identityType, nil)},
+	}
+	r.(resource.ResourceWithImportState).ImportState(ctx, req, resp)
+	return resp
+}
+
+// testConfigure configures r with a provider connected to fake.
+func testConfigure(t *testing.T, r resource.Resource, fake *fakedb.DB) {
+	t.Helper()
+	data := &providerData{
+		db:      fake,
+		connect: func(string, string) DBGetter { return fake },
+		retry:   defaultRetryPolicy,
+	}
+	var resp resource.ConfigureResponse
+	r.(resource.ResourceWithConfigure).Configure(context.Background(), resource.ConfigureRequest{ProviderData: data}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure() error = %v", resp.Diagnostics)
+	}
+}
+
+func TestImportStateReadsValues(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().
+		ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_roles`, []string{"exists"}, []driver.Value{true}).
+		ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"}, []driver.Value{true}).
+		ExpectQuery(`rolconnlimit`, []string{"rolconnlimit"}, []driver.Value{int64(25)})
+
+	resp := testImportState(t, NewBypassRLSResource(), fake, "app", nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState() error = %v", resp.Diagnostics)
+	}
+	var enabled types.Bool
+	resp.State.GetAttribute(ctx, path.Root("enabled"), &enabled)
+	if !enabled.ValueBool() {
+		t.Errorf("ImportState() enabled = %s, want true from the database", enabled)
+	}
+
+	resp = testImportState(t, NewConnectionLimitResource(), fake, "app", nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState() error = %v", resp.Diagnostics)
+	}
+	var limit types.Int64
+	resp.State.GetAttribute(ctx, path.Root("connection_limit"), &limit)
+	if limit.ValueInt64() != 25 {
+		t.Errorf("ImportState() connection_limit = %s, want 25 from the database", limit)
+	}
+}
+
+func TestImportStateMissingRole(t *testing.T) {
+	fake := fakedb.New().
+		ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_roles`, []string{"exists"}, []driver.Value{true}).
+		ExpectQuery(`rolbypassrls`, []string{"rolbypassrls"})
+
+	resp := testImportState(t, NewBypassRLSResource(), fake, "app", nil)
+	if !resp.Diagnostics.HasError() {
+		t.Error("ImportState() of a missing role succeeded, want error")
+	}
+}
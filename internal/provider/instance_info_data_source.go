@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*instanceInfoDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*instanceInfoDataSource)(nil)
+)
+
+// NewInstanceInfoDataSource is a helper function to simplify the provider implementation.
+func NewInstanceInfoDataSource() datasource.DataSource {
+	return &instanceInfoDataSource{}
+}
+
+type instanceInfoDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type instanceInfoDataSourceModel struct {
+	Connection             types.String `tfsdk:"connection"`
+	Platform               types.String `tfsdk:"platform"`
+	Managed                types.Bool   `tfsdk:"managed"`
+	Version                types.String `tfsdk:"version"`
+	VersionNum             types.Int64  `tfsdk:"version_num"`
+	SharedPreloadLibraries []string     `tfsdk:"shared_preload_libraries"`
+	MaxConnections         types.Int64  `tfsdk:"max_connections"`
+}
+
+// instanceInfo are the facts about the server read by the data source.
+type instanceInfo struct {
+	Platform               string
+	Version                string
+	VersionNum             int64
+	SharedPreloadLibraries []string
+	MaxConnections         int64
+}
+
+// Platforms reported by the data source. Managed services are recognized by
+// the administration roles they create.
+const (
+	platformCloudSQL    = "cloudsql"
+	platformRDS         = "rds"
+	platformAzure       = "azure"
+	platformSelfManaged = "self_managed"
+)
+
+// Metadata returns the data source type name.
+func (d *instanceInfoDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_instance_info"
+}
+
+// Schema defines the schema for the data source.
+func (d *instanceInfoDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Reads instance-level facts about the server: the platform it runs on (Cloud SQL, Amazon RDS, Azure Database for PostgreSQL or self-managed), its version, shared_preload_libraries and max_connections.
+
+Useful for conditional logic, e.g. only managing settings that a managed service allows, or that need an extension loaded through shared_preload_libraries.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the instance through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"platform": schema.StringAttribute{
+				Description: "The platform of the instance, one of \"cloudsql\", \"rds\", \"azure\" or \"self_managed\". Managed services are recognized by the administration roles they create, e.g. cloudsqlsuperuser.",
+				Computed:    true,
+			},
+			"managed": schema.BoolAttribute{
+				Description: "Whether the instance runs on a managed service, on which the connecting role is typically not a true superuser.",
+				Computed:    true,
+			},
+			"version": schema.StringAttribute{
+				Description: "The version of the server, e.g. \"16.4\".",
+				Computed:    true,
+			},
+			"version_num": schema.Int64Attribute{
+				Description: "The version of the server as a number, e.g. 160004, for comparisons.",
+				Computed:    true,
+			},
+			"shared_preload_libraries": schema.ListAttribute{
+				Description: "The libraries preloaded at server start, e.g. [\"pg_stat_statements\", \"pgaudit\"]. Null when the connecting role is not allowed to read the setting, i.e. is neither a superuser nor a member of pg_read_all_settings.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"max_connections": schema.Int64Attribute{
+				Description: "The maximum number of concurrent connections to the server.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *instanceInfoDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *instanceInfoDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data instanceInfoDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	info, err := readInstanceInfo(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query instance",
+			"Failed to query instance: "+err.Error(),
+		)
+		return
+	}
+
+	data.Platform = types.StringValue(info.Platform)
+	data.Managed = types.BoolValue(info.Platform != platformSelfManaged)
+	data.Version = types.StringValue(info.Version)
+	data.VersionNum = types.Int64Value(info.VersionNum)
+	data.SharedPreloadLibraries = info.SharedPreloadLibraries
+	data.MaxConnections = types.Int64Value(info.MaxConnections)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// sqlInstanceInfo reads the facts of the instance. shared_preload_libraries
+// is read from pg_settings, which hides it from unprivileged roles instead of
+// failing like current_setting.
+const sqlInstanceInfo = `
+SELECT
+	current_setting('server_version'),
+	current_setting('server_version_num')::int,
+	(SELECT setting FROM pg_settings WHERE name = 'shared_preload_libraries'),
+	current_setting('max_connections')::int,
+	EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'cloudsqlsuperuser'),
+	EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'rds_superuser'),
+	EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'azure_pg_admin');`
+
+// readInstanceInfo returns the facts about the instance db is connected to.
+func readInstanceInfo(ctx context.Context, db *sql.DB) (instanceInfo, error) {
+	var info instanceInfo
+	var libraries sql.NullString
+	var cloudSQL, rds, azure bool
+	err := db.QueryRowContext(ctx, sqlInstanceInfo).Scan(&info.Version, &info.VersionNum, &libraries, &info.MaxConnections, &cloudSQL, &rds, &azure)
+	if err != nil {
+		return info, err
+	}
+
+	switch {
+	case cloudSQL:
+		info.Platform = platformCloudSQL
+	case rds:
+		info.Platform = platformRDS
+	case azure:
+		info.Platform = platformAzure
+	default:
+		info.Platform = platformSelfManaged
+	}
+	if libraries.Valid {
+		info.SharedPreloadLibraries = splitLibraries(libraries.String)
+	}
+	return info, nil
+}
+
+// splitLibraries splits a comma-separated list of libraries, as found in
+// shared_preload_libraries, whose elements may be double-quoted.
+func splitLibraries(s string) []string {
+	libraries := []string{}
+	for _, library := range strings.Split(s, ",") {
+		library = strings.Trim(strings.TrimSpace(library), `"`)
+		if library != "" {
+			libraries = append(libraries, library)
+		}
+	}
+	return libraries
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadInstanceInfo(t *testing.T) {
+	columns := []string{"server_version", "server_version_num", "setting", "max_connections", "cloudsql", "rds", "azure"}
+	tests := []struct {
+		name string
+		row  []driver.Value
+		want instanceInfo
+	}{
+		{
+			name: "cloud sql",
+			row:  []driver.Value{"16.4", int64(160004), "pg_stat_statements, \"pgaudit\"", int64(100), true, false, false},
+			want: instanceInfo{Platform: platformCloudSQL, Version: "16.4", VersionNum: 160004, SharedPreloadLibraries: []string{"pg_stat_statements", "pgaudit"}, MaxConnections: 100},
+		},
+		{
+			name: "rds",
+			row:  []driver.Value{"15.7", int64(150007), "rdsutils", int64(400), false, true, false},
+			want: instanceInfo{Platform: platformRDS, Version: "15.7", VersionNum: 150007, SharedPreloadLibraries: []string{"rdsutils"}, MaxConnections: 400},
+		},
+		{
+			name: "azure",
+			row:  []driver.Value{"14.12", int64(140012), "", int64(50), false, false, true},
+			want: instanceInfo{Platform: platformAzure, Version: "14.12", VersionNum: 140012, SharedPreloadLibraries: []string{}, MaxConnections: 50},
+		},
+		{
+			name: "self-managed without pg_read_all_settings",
+			row:  []driver.Value{"17.0", int64(170000), nil, int64(100), false, false, false},
+			want: instanceInfo{Platform: platformSelfManaged, Version: "17.0", VersionNum: 170000, MaxConnections: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := fakedb.New().ExpectQuery(`current_setting\('server_version'\)`, columns, tt.row)
+			db, err := fake.GetDB(ctx)
+			if err != nil {
+				t.Fatalf("GetDB() error = %v", err)
+			}
+			defer db.Close()
+
+			got, err := readInstanceInfo(ctx, db)
+			if err != nil {
+				t.Fatalf("readInstanceInfo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readInstanceInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstanceInfoDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "pgrole_instance_info" "test" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pgrole_instance_info.test", "platform", "self_managed"),
+					resource.TestCheckResourceAttr("data.pgrole_instance_info.test", "managed", "false"),
+					resource.TestCheckResourceAttrSet("data.pgrole_instance_info.test", "version"),
+					resource.TestCheckResourceAttrSet("data.pgrole_instance_info.test", "max_connections"),
+				),
+			},
+		},
+	})
+}
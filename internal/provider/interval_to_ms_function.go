@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = (*intervalToMsFunction)(nil)
+
+// NewIntervalToMsFunction is a helper function to simplify the provider implementation.
+func NewIntervalToMsFunction() function.Function {
+	return &intervalToMsFunction{}
+}
+
+type intervalToMsFunction struct{}
+
+// Metadata returns the function name.
+func (f *intervalToMsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "interval_to_ms"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *intervalToMsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Convert a PostgreSQL duration to milliseconds",
+		MarkdownDescription: "Converts a PostgreSQL duration into a whole number of milliseconds, rounded to the nearest, e.g. to compare timeouts in preconditions. Accepts the values of time parameters such as statement_timeout, e.g. \"30s\", \"5min\" or \"100\", a bare number being milliseconds, and intervals, e.g. \"1 hour 30 minutes\", \"1 day 02:00:00\" or \"00:05:00\". Months and years are rejected, since their length varies.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "duration",
+				MarkdownDescription: "The duration to convert, e.g. \"30s\" or \"1 hour\".",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+// Run converts the duration.
+func (f *intervalToMsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var duration string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &duration))
+	if resp.Error != nil {
+		return
+	}
+
+	ms, err := intervalToMs(duration)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, ms))
+}
+
+// intervalFieldUnits are the units of the fields of intervals, as parameter
+// units, by their spellings accepted by PostgreSQL.
+var intervalFieldUnits = map[string]string{
+	"microsecond": "us", "microseconds": "us", "us": "us", "usec": "us", "usecs": "us",
+	"millisecond": "ms", "milliseconds": "ms", "ms": "ms", "msec": "ms", "msecs": "ms",
+	"second": "s", "seconds": "s", "s": "s", "sec": "s", "secs": "s",
+	"minute": "min", "minutes": "min", "m": "min", "min": "min", "mins": "min",
+	"hour": "h", "hours": "h", "h": "h", "hr": "h", "hrs": "h",
+	"day": "d", "days": "d", "d": "d",
+	"week": "w", "weeks": "w", "w": "w",
+}
+
+// intervalFieldRe matches a field of an interval, e.g. "1 hour" or "-1.5h".
+var intervalFieldRe = regexp.MustCompile(`^([+-]?[0-9]+(?:\.[0-9]+)?)\s*([a-z]+)`)
+
+// intervalClockRe matches the time of day part of an interval, e.g.
+// "02:00:00" or "-00:05".
+var intervalClockRe = regexp.MustCompile(`^([+-]?)([0-9]+):([0-9]{2})(?::([0-9]{2}(?:\.[0-9]+)?))?`)
+
+// intervalToMs converts duration, the value of a time parameter or an
+// interval, to milliseconds.
+func intervalToMs(duration string) (int64, error) {
+	if v, ok := convertUnit(duration, "ms"); ok {
+		return roundMs(v)
+	}
+
+	s := strings.ToLower(strings.TrimSpace(duration))
+	s = strings.TrimSpace(strings.TrimPrefix(s, "@"))
+	sign := 1.0
+	if rest, ok := strings.CutSuffix(s, " ago"); ok {
+		s, sign = strings.TrimSpace(rest), -1
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q: must be a number optionally followed by a unit, e.g. \"30s\", or an interval, e.g. \"1 hour 30 minutes\"", duration)
+	}
+
+	var total float64
+	for s != "" {
+		if m := intervalClockRe.FindStringSubmatch(s); m != nil {
+			h, _ := strconv.ParseFloat(m[2], 64)
+			min, _ := strconv.ParseFloat(m[3], 64)
+			sec, _ := strconv.ParseFloat("0"+m[4], 64)
+			v := h*3600e3 + min*60e3 + sec*1e3
+			if m[1] == "-" {
+				v = -v
+			}
+			total += v
+			s = strings.TrimSpace(s[len(m[0]):])
+			continue
+		}
+		m := intervalFieldRe.FindStringSubmatch(s)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q: must be a number optionally followed by a unit, e.g. \"30s\", or an interval, e.g. \"1 hour 30 minutes\"", duration)
+		}
+		unit, ok := intervalFieldUnits[m[2]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q: unsupported unit %q, must be one of microseconds, milliseconds, seconds, minutes, hours, days or weeks", duration, m[2])
+		}
+		v, _ := strconv.ParseFloat(m[1], 64)
+		if unit == "w" {
+			v, unit = v*7, "d"
+		}
+		total += v * unitFactors[1][unit] / unitFactors[1]["ms"]
+		s = strings.TrimSpace(strings.TrimPrefix(s[len(m[0]):], ","))
+	}
+	return roundMs(sign * total)
+}
+
+// roundMs rounds v to the nearest millisecond, failing if it overflows.
+func roundMs(v float64) (int64, error) {
+	v = math.Round(v)
+	if v >= math.MaxInt64 || v <= math.MinInt64 {
+		return 0, fmt.Errorf("duration of %g milliseconds is out of range", v)
+	}
+	return int64(v), nil
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIntervalToMs(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     int64
+		wantErr  bool
+	}{
+		{duration: "100", want: 100},
+		{duration: "30s", want: 30000},
+		{duration: "5min", want: 300000},
+		{duration: "1.5h", want: 5400000},
+		{duration: "1500us", want: 2},
+		{duration: "1 hour 30 minutes", want: 5400000},
+		{duration: "1 day 02:00:00", want: 93600000},
+		{duration: "00:05", want: 300000},
+		{duration: "00:00:01.5", want: 1500},
+		{duration: "2 weeks", want: 1209600000},
+		{duration: "@ 1 min ago", want: -60000},
+		{duration: "1 month", wantErr: true},
+		{duration: "30 parsecs", wantErr: true},
+		{duration: "soon", wantErr: true},
+		{duration: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.duration, func(t *testing.T) {
+			got, err := intervalToMs(tt.duration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("intervalToMs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("intervalToMs() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntervalToMsFunction(t *testing.T) {
+	ctx := context.Background()
+	req := function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue("2min")}),
+	}
+	resp := function.RunResponse{
+		Result: function.NewResultData(types.Int64Unknown()),
+	}
+	NewIntervalToMsFunction().Run(ctx, req, &resp)
+	if resp.Error != nil {
+		t.Fatalf("Run() error = %v", resp.Error)
+	}
+	if got := resp.Result.Value(); !got.Equal(types.Int64Value(120000)) {
+		t.Errorf("Run() = %v, want 120000", got)
+	}
+}
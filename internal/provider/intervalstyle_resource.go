@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewIntervalStyleResource is a helper function to simplify the provider implementation.
+func NewIntervalStyleResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "intervalstyle",
+		description: `Manage IntervalStyle for an existing role.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-INTERVALSTYLE) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "style",
+				Parameter:   "IntervalStyle",
+				Description: "The display format of interval values, one of \"postgres\", \"postgres_verbose\", \"sql_standard\" or \"iso_8601\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("postgres", "postgres_verbose", "sql_standard", "iso_8601"),
+				},
+			},
+		},
+	}
+}
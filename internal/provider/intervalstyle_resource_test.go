@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestIntervalStyleResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_intervalstyle" "test" {
+  role  = "example_user"
+  style = "iso_8601"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_intervalstyle.test", "style", "iso_8601"),
+					resource.TestCheckResourceAttr("pgrole_intervalstyle.test", "sql", `ALTER ROLE "example_user" SET IntervalStyle = 'iso_8601';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_intervalstyle.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET IntervalStyle = 'postgres';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+)
+
+// readKeyring returns the password stored in the credential store of the OS
+// for service and account. It is a variable for unit tests.
+var readKeyring = readOSKeyring
+
+// resolveKeyring sets the password of c to the one stored in the credential
+// store of the OS, if configured with password_keyring, for the account of
+// its username.
+func (c *connectionConfig) resolveKeyring(ctx context.Context) error {
+	if c.passwordKeyring == "" {
+		return nil
+	}
+	password, err := readKeyring(ctx, c.passwordKeyring, c.username)
+	if err != nil {
+		return fmt.Errorf("error reading the password of %s for service %s from the keyring: %w", c.username, c.passwordKeyring, err)
+	}
+	if password == "" {
+		return fmt.Errorf("the keyring entry of %s for service %s has no password", c.username, c.passwordKeyring)
+	}
+	c.password = password
+	return nil
+}
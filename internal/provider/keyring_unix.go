@@ -0,0 +1,25 @@
+//go:build !darwin && !windows
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// readOSKeyring reads the secret of service and account from the Secret
+// Service, e.g. GNOME Keyring or KWallet, with the secret-tool command of
+// libsecret. Secrets are looked up by their service and username
+// attributes, like those stored by other keyring libraries.
+func readOSKeyring(ctx context.Context, service, account string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "username", account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
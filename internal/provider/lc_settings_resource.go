@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewLCSettingsResource is a helper function to simplify the provider implementation.
+func NewLCSettingsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "lc_settings",
+		description: `Manage the locale settings (lc_messages, lc_monetary, lc_numeric and lc_time) of an existing role. Settings left unset are reset to the database default.
+
+Setting lc_messages requires the connecting role to be a superuser.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#RUNTIME-CONFIG-CLIENT-FORMAT) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "lc_messages",
+				Parameter:   "lc_messages",
+				Description: "The language in which messages are displayed, e.g. \"en_US.UTF-8\".",
+			},
+			{
+				Attribute:   "lc_monetary",
+				Parameter:   "lc_monetary",
+				Description: "The locale used for formatting monetary amounts.",
+			},
+			{
+				Attribute:   "lc_numeric",
+				Parameter:   "lc_numeric",
+				Description: "The locale used for formatting numbers.",
+			},
+			{
+				Attribute:   "lc_time",
+				Parameter:   "lc_time",
+				Description: "The locale used for formatting dates and times.",
+			},
+		},
+	}
+}
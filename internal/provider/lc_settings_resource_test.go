@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestLCSettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_lc_settings" "test" {
+  role        = "example_user"
+  lc_monetary = "C"
+  lc_numeric  = "C"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_lc_settings.test", "lc_monetary", "C"),
+					resource.TestCheckResourceAttr("pgrole_lc_settings.test", "lc_numeric", "C"),
+					resource.TestCheckResourceAttr("pgrole_lc_settings.test", "sql", `ALTER ROLE "example_user" RESET lc_messages;
+ALTER ROLE "example_user" SET lc_monetary = 'C';
+ALTER ROLE "example_user" SET lc_numeric = 'C';
+ALTER ROLE "example_user" RESET lc_time;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_lc_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET lc_time = 'C';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
@@ -0,0 +1,515 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = (*loginResource)(nil)
+	_ resource.ResourceWithConfigure   = (*loginResource)(nil)
+	_ resource.ResourceWithImportState = (*loginResource)(nil)
+	_ resource.ResourceWithIdentity    = (*loginResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*loginResource)(nil)
+	_ resource.ResourceWithMoveState   = (*loginResource)(nil)
+)
+
+// NewLoginResource is a helper function to simplify the provider implementation.
+func NewLoginResource() resource.Resource {
+	return &loginResource{}
+}
+
+type loginResource struct {
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+}
+
+// Metadata returns the resource type name.
+func (r *loginResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_login"
+}
+
+// Schema defines the schema for the resource.
+func (r *loginResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manage LOGIN status for an existing role. Disabling LOGIN only refuses new sessions, unless terminate_sessions is set.",
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether to enable LOGIN for the role. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"terminate_sessions": schema.BoolAttribute{
+				Description: "Whether disabling LOGIN also terminates the active sessions of the role, in the same apply. Requires the pg_signal_backend role. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"adopt":                 adoptAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
+		},
+	}
+}
+
+type loginModel struct {
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	Enabled            bool         `tfsdk:"enabled"`
+	TerminateSessions  bool         `tfsdk:"terminate_sessions"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	Adopt              bool         `tfsdk:"adopt"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *loginResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *loginResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *loginResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role types.String
+	var enabled types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("enabled"), &enabled)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || enabled.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetLogin(role.ValueString(), enabled.ValueBool())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *loginResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan
+	var plan loginModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create the resource
+	sqlstr := sqlSetLogin(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
+
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readLogin(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readLogin(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
+	if !plan.Enabled {
+		r.terminateSessions(ctx, &resp.Diagnostics, db, plan)
+	}
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *loginResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state loginModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the actual LOGIN state in postgres
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	enabled, err := readLogin(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query LOGIN status",
+			fmt.Sprintf("Failed to query LOGIN status for role %s: %s", state.Role, err),
+		)
+		return
+	}
+	tflog.Debug(ctx, "Read LOGIN for role", map[string]any{
+		"role": state.Role,
+		"got":  enabled,
+		"want": state.Enabled,
+	})
+
+	logApplied(ctx, req.Private, state.Role, state.SQL.ValueString())
+	addDriftWarning(&resp.Diagnostics, state.Role, "enabled", state.Enabled, enabled)
+
+	// Overwrite the state with the actual state
+	state.Enabled = enabled
+
+	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *loginResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve value from plan
+	var plan loginModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update resource state with updated values
+	sqlstr := sqlSetLogin(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
+
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordMissingOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readLogin(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readLogin(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
+	if !plan.Enabled {
+		r.terminateSessions(ctx, &resp.Diagnostics, db, plan)
+	}
+
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *loginResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	var state loginModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Delete the resource, or restore the original value
+	sqlstr := sqlDisableLogin(state.Role)
+	var original bool
+	if state.Adopt && getOriginal(ctx, &resp.Diagnostics, req.Private, &original) {
+		sqlstr = sqlSetLogin(state.Role, original)
+	}
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
+	r.terminateSessions(ctx, &resp.Diagnostics, db, state)
+}
+
+func (r *loginResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enabled, err := readLogin(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query LOGIN status",
+			fmt.Sprintf("Failed to query LOGIN status for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enabled"), enabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("terminate_sessions"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetLogin(role, enabled))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_role resource of the community
+// PostgreSQL provider into this resource.
+func (r *loginResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlRoleState
+				if !readPostgresqlState(req, resp, "postgresql_role", &source) {
+					return
+				}
+
+				target := loginModel{
+					Role:    source.Name,
+					Enabled: source.Login,
+					SQL:     types.StringValue(sqlSetLogin(source.Name, source.Login)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.Name)...)
+			},
+		},
+	}
+}
+
+// terminateSessions terminates the sessions of the role once its LOGIN is
+// disabled, if terminate_sessions is set. LOGIN is already disabled, so
+// failures are reported as warnings.
+func (r *loginResource) terminateSessions(ctx context.Context, diags *diag.Diagnostics, db *sql.DB, m loginModel) {
+	if !m.TerminateSessions {
+		return
+	}
+
+	terminated, err := terminateSessions(ctx, db, r.retry.audit, m.Role)
+	if err != nil {
+		diags.AddWarning(
+			"Failed to terminate sessions",
+			fmt.Sprintf("LOGIN is disabled for role %s, but its sessions could not be terminated: %s", m.Role, err),
+		)
+		return
+	}
+	tflog.Info(ctx, "Terminated sessions of role", map[string]any{
+		"role":       m.Role,
+		"terminated": terminated,
+	})
+}
+
+// readLogin returns the LOGIN status of the role, or sql.ErrNoRows if the
+// role does not exist.
+func readLogin(ctx context.Context, db *sql.DB, role string) (bool, error) {
+	var enabled bool
+	err := db.QueryRowContext(ctx, "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1;", role).Scan(&enabled)
+	return enabled, err
+}
+
+func sqlSetLogin(role string, enabled bool) string {
+	if enabled {
+		return sqlEnableLogin(role)
+	}
+	return sqlDisableLogin(role)
+}
+
+func sqlEnableLogin(role string) string {
+	return fmt.Sprintf("ALTER ROLE %s LOGIN;", quoteIdentifier(role))
+}
+
+func sqlDisableLogin(role string) string {
+	return fmt.Sprintf("ALTER ROLE %s NOLOGIN;", quoteIdentifier(role))
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestLoginResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "pgrole_login" "test" {
+  role    = "test"
+  enabled = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_login.test", "role", "test"),
+					resource.TestCheckResourceAttr("pgrole_login.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("pgrole_login.test", "terminate_sessions", "false"),
+					resource.TestCheckResourceAttr("pgrole_login.test", "sql", `ALTER ROLE "test" LOGIN;`),
+				),
+			},
+			// Disabling LOGIN terminates the sessions of the role
+			{
+				Config: providerConfig + `
+resource "pgrole_login" "test" {
+  role               = "test"
+  enabled            = false
+  terminate_sessions = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_login.test", "enabled", "false"),
+					resource.TestCheckResourceAttr("pgrole_login.test", "terminate_sessions", "true"),
+					resource.TestCheckResourceAttr("pgrole_login.test", "sql", `ALTER ROLE "test" NOLOGIN;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_login.test",
+				ImportState:                          true,
+				ImportStateId:                        "test",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+				ImportStateVerifyIgnore:              []string{"terminate_sessions"},
+			},
+		},
+	})
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*membershipGraphDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*membershipGraphDataSource)(nil)
+)
+
+// NewMembershipGraphDataSource is a helper function to simplify the provider implementation.
+func NewMembershipGraphDataSource() datasource.DataSource {
+	return &membershipGraphDataSource{}
+}
+
+type membershipGraphDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type membershipGraphDataSourceModel struct {
+	Connection types.String          `tfsdk:"connection"`
+	Edges      []membershipEdgeModel `tfsdk:"edges"`
+	DOT        string                `tfsdk:"dot"`
+}
+
+// membershipEdgeModel is a row of pg_auth_members: member is a member of role.
+type membershipEdgeModel struct {
+	Role        string `tfsdk:"role"`
+	Member      string `tfsdk:"member"`
+	Grantor     string `tfsdk:"grantor"`
+	AdminOption bool   `tfsdk:"admin_option"`
+}
+
+// Metadata returns the data source type name.
+func (d *membershipGraphDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_membership_graph"
+}
+
+// Schema defines the schema for the data source.
+func (d *membershipGraphDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Reads the full role membership graph of the server from [pg_auth_members](https://www.postgresql.org/docs/current/catalog-pg-auth-members.html), as a list of edges and as a [DOT](https://graphviz.org/doc/info/lang.html) graph.
+
+Useful to visualize and audit role inheritance, e.g. by writing the DOT graph to a file with ` + "`local_file`" + ` and rendering it with Graphviz.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the memberships through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"edges": schema.ListNestedAttribute{
+				Description: "The memberships, sorted by role, member and grantor.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"role": schema.StringAttribute{
+							Description: "Name of the role granted.",
+							Computed:    true,
+						},
+						"member": schema.StringAttribute{
+							Description: "Name of the role it is granted to.",
+							Computed:    true,
+						},
+						"grantor": schema.StringAttribute{
+							Description: "Name of the role that granted the membership.",
+							Computed:    true,
+						},
+						"admin_option": schema.BoolAttribute{
+							Description: "Whether member can grant role to others.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"dot": schema.StringAttribute{
+				Description: "The memberships as a DOT digraph with an edge from each member to the role granted to it. Memberships WITH ADMIN OPTION are labelled \"admin\".",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *membershipGraphDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *membershipGraphDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data membershipGraphDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	edges, err := readMembershipEdges(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role memberships",
+			"Failed to query role memberships: "+err.Error(),
+		)
+		return
+	}
+
+	data.Edges = edges
+	data.DOT = membershipDOT(edges)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+const sqlMembershipEdges = `
+SELECT r.rolname, m.rolname, g.rolname, a.admin_option
+FROM pg_auth_members a
+JOIN pg_roles r ON r.oid = a.roleid
+JOIN pg_roles m ON m.oid = a.member
+JOIN pg_roles g ON g.oid = a.grantor
+ORDER BY 1, 2, 3;`
+
+// readMembershipEdges returns the memberships of all roles, sorted.
+func readMembershipEdges(ctx context.Context, db *sql.DB) ([]membershipEdgeModel, error) {
+	rows, err := db.QueryContext(ctx, sqlMembershipEdges)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []membershipEdgeModel{}
+	for rows.Next() {
+		var e membershipEdgeModel
+		if err := rows.Scan(&e.Role, &e.Member, &e.Grantor, &e.AdminOption); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// membershipDOT renders edges as a DOT digraph. Since PostgreSQL 16 the same
+// membership may be granted by several grantors; it is drawn once, labelled
+// "admin" if any of the grants has the admin option.
+func membershipDOT(edges []membershipEdgeModel) string {
+	type key struct{ role, member string }
+	var keys []key
+	admin := map[key]bool{}
+	for _, e := range edges {
+		k := key{e.Role, e.Member}
+		if _, ok := admin[k]; !ok {
+			keys = append(keys, k)
+		}
+		admin[k] = admin[k] || e.AdminOption
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph roles {\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  %s -> %s", strconv.Quote(k.member), strconv.Quote(k.role))
+		if admin[k] {
+			b.WriteString(` [label="admin"]`)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
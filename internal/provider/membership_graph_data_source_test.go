@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadMembershipEdges(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`FROM pg_auth_members`, []string{"role", "member", "grantor", "admin_option"},
+		[]driver.Value{"admin", "alice", "postgres", true},
+		[]driver.Value{"admin", "alice", "bob", false},
+		[]driver.Value{"readers", "admin", "postgres", false},
+	)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	edges, err := readMembershipEdges(ctx, db)
+	if err != nil {
+		t.Fatalf("readMembershipEdges() error = %v", err)
+	}
+	want := []membershipEdgeModel{
+		{Role: "admin", Member: "alice", Grantor: "postgres", AdminOption: true},
+		{Role: "admin", Member: "alice", Grantor: "bob"},
+		{Role: "readers", Member: "admin", Grantor: "postgres"},
+	}
+	if !reflect.DeepEqual(edges, want) {
+		t.Fatalf("readMembershipEdges() = %+v, want %+v", edges, want)
+	}
+
+	wantDOT := `digraph roles {
+  "alice" -> "admin" [label="admin"];
+  "admin" -> "readers";
+}
+`
+	if got := membershipDOT(edges); got != wantDOT {
+		t.Errorf("membershipDOT() = %q, want %q", got, wantDOT)
+	}
+}
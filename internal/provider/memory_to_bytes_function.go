@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = (*memoryToBytesFunction)(nil)
+
+// NewMemoryToBytesFunction is a helper function to simplify the provider implementation.
+func NewMemoryToBytesFunction() function.Function {
+	return &memoryToBytesFunction{}
+}
+
+type memoryToBytesFunction struct{}
+
+// Metadata returns the function name.
+func (f *memoryToBytesFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "memory_to_bytes"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *memoryToBytesFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Convert a PostgreSQL memory size to bytes",
+		MarkdownDescription: "Converts the value of a PostgreSQL memory parameter, e.g. \"64MB\" or \"512kB\", into a number of bytes, e.g. to compare work_mem and temp_file_limit in preconditions. Units are B, kB, MB, GB and TB, multiples of 1024. A bare number is in kB, the default unit of work_mem and temp_file_limit. See bytes_to_memory for the inverse.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "size",
+				MarkdownDescription: "The memory size to convert, e.g. \"64MB\".",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+// Run converts the memory size.
+func (f *memoryToBytesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var size string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &size))
+	if resp.Error != nil {
+		return
+	}
+
+	bytes, err := memoryToBytes(size)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, bytes))
+}
+
+// memoryToBytes converts size, the value of a memory parameter whose default
+// unit is kB, to bytes.
+func memoryToBytes(size string) (int64, error) {
+	if !memorySizeRe.MatchString(size) {
+		return 0, fmt.Errorf("invalid memory size %q: must be a number optionally followed by one of the units B, kB, MB, GB or TB, e.g. \"64MB\"", size)
+	}
+	v, _ := convertUnit(size, "kB")
+	v *= unitFactors[0]["kB"]
+	if v >= math.MaxInt64 {
+		return 0, fmt.Errorf("memory size %q is out of range", size)
+	}
+	return int64(v), nil
+}
+
+// memoryUnits are the units of memory parameters, largest first.
+var memoryUnits = []string{"TB", "GB", "MB", "kB", "B"}
+
+// bytesToMemory returns bytes as a memory parameter value, in the largest unit
+// dividing it, e.g. "64MB".
+func bytesToMemory(bytes int64) string {
+	for _, unit := range memoryUnits {
+		factor := int64(unitFactors[0][unit])
+		if bytes != 0 && bytes%factor == 0 {
+			return fmt.Sprintf("%d%s", bytes/factor, unit)
+		}
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestMemoryToBytes(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{size: "64MB", want: 64 << 20},
+		{size: "512kB", want: 512 << 10},
+		{size: "100B", want: 100},
+		{size: "2 GB", want: 2 << 30},
+		{size: "1TB", want: 1 << 40},
+		{size: "4096", want: 4 << 20},
+		{size: "64mb", wantErr: true},
+		{size: "1.5GB", wantErr: true},
+		{size: "-1", wantErr: true},
+		{size: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := memoryToBytes(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("memoryToBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("memoryToBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBytesToMemory(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0B"},
+		{100, "100B"},
+		{1536, "1536B"},
+		{64 << 20, "64MB"},
+		{3 << 30, "3GB"},
+		{2 << 40, "2TB"},
+	}
+	for _, tt := range tests {
+		if got := bytesToMemory(tt.bytes); got != tt.want {
+			t.Errorf("bytesToMemory(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+		if back, err := memoryToBytes(bytesToMemory(tt.bytes)); err != nil || back != tt.bytes {
+			t.Errorf("memoryToBytes(bytesToMemory(%d)) = %d, %v", tt.bytes, back, err)
+		}
+	}
+}
+
+func TestMemoryFunctions(t *testing.T) {
+	ctx := context.Background()
+	resp := function.RunResponse{
+		Result: function.NewResultData(types.Int64Unknown()),
+	}
+	NewMemoryToBytesFunction().Run(ctx, function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue("64MB")}),
+	}, &resp)
+	if resp.Error != nil || !resp.Result.Value().Equal(types.Int64Value(64<<20)) {
+		t.Errorf("memory_to_bytes() = %v, %v", resp.Result.Value(), resp.Error)
+	}
+
+	resp = function.RunResponse{
+		Result: function.NewResultData(types.StringUnknown()),
+	}
+	NewBytesToMemoryFunction().Run(ctx, function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.Int64Value(512 << 10)}),
+	}, &resp)
+	if resp.Error != nil || !resp.Result.Value().Equal(types.StringValue("512kB")) {
+		t.Errorf("bytes_to_memory() = %v, %v", resp.Result.Value(), resp.Error)
+	}
+}
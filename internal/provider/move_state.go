@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// postgresqlProviderAddress is the address of the community PostgreSQL
+// provider whose resources can be moved into pgrole resources.
+const postgresqlProviderAddress = "registry.terraform.io/cyrilgdn/postgresql"
+
+// postgresqlRoleState holds the attributes of a postgresql_role resource that
+// map onto pgrole resources.
+type postgresqlRoleState struct {
+	Name                   string `json:"name"`
+	BypassRowLevelSecurity bool   `json:"bypass_row_level_security"`
+	Login                  bool   `json:"login"`
+	Replication            bool   `json:"replication"`
+	ConnectionLimit        int64  `json:"connection_limit"`
+	StatementTimeout       int64  `json:"statement_timeout"`
+}
+
+// postgresqlSecurityLabelState holds the attributes of a
+// postgresql_security_label resource.
+type postgresqlSecurityLabelState struct {
+	ObjectType    string `json:"object_type"`
+	ObjectName    string `json:"object_name"`
+	LabelProvider string `json:"label_provider"`
+	Label         string `json:"label"`
+}
+
+// readPostgresqlState decodes the raw state of a resource of type typeName
+// from the community PostgreSQL provider into target. It returns false if the
+// source resource is not of that type, so that other state movers can be
+// tried, or if decoding failed.
+func readPostgresqlState(req resource.MoveStateRequest, resp *resource.MoveStateResponse, typeName string, target any) bool {
+	if req.SourceTypeName != typeName || !strings.EqualFold(req.SourceProviderAddress, postgresqlProviderAddress) {
+		return false
+	}
+	if req.SourceRawState == nil {
+		resp.Diagnostics.AddError(
+			"Unable to move resource state",
+			fmt.Sprintf("Source state of %s is missing.", typeName),
+		)
+		return false
+	}
+	if err := json.Unmarshal(req.SourceRawState.JSON, target); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to move resource state",
+			fmt.Sprintf("Failed to decode source state of %s: %s", typeName, err),
+		)
+		return false
+	}
+	return true
+}
+
+// statementTimeoutFromMilliseconds converts the statement_timeout of a
+// postgresql_role, in milliseconds, to the format of pgrole_statement_timeout.
+func statementTimeoutFromMilliseconds(ms int64) (string, error) {
+	if ms%1000 != 0 {
+		return "", fmt.Errorf("statement_timeout of %dms is not a whole number of seconds", ms)
+	}
+	return fmt.Sprintf("%ds", ms/1000), nil
+}
@@ -0,0 +1,25 @@
+package provider
+
+import "testing"
+
+func TestStatementTimeoutFromMilliseconds(t *testing.T) {
+	tests := []struct {
+		ms      int64
+		want    string
+		wantErr bool
+	}{
+		{ms: 0, want: "0s"},
+		{ms: 30000, want: "30s"},
+		{ms: 1500, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := statementTimeoutFromMilliseconds(tt.ms)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("statementTimeoutFromMilliseconds(%d) error = %v, wantErr %t", tt.ms, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("statementTimeoutFromMilliseconds(%d) = %q, want %q", tt.ms, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,567 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = (*namespaceSettingsResource)(nil)
+	_ resource.ResourceWithConfigure   = (*namespaceSettingsResource)(nil)
+	_ resource.ResourceWithImportState = (*namespaceSettingsResource)(nil)
+	_ resource.ResourceWithIdentity    = (*namespaceSettingsResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*namespaceSettingsResource)(nil)
+)
+
+// namespaceSettingsResource manages arbitrary configuration parameters of a
+// role within the namespaces of an extension, e.g. "citus.*", given as a map
+// of parameter name to value. Parameters removed from the map are RESET, and
+// in exclusive mode so are the parameters of the namespaces set outside of
+// Terraform.
+//
+// Each resource is declared in its own file, e.g. citus_settings_resource.go.
+type namespaceSettingsResource struct {
+	// typeName is the resource type name without the provider prefix,
+	// e.g. "citus_settings".
+	typeName    string
+	description string
+	// namespaces are the allowed parameter name prefixes, without the dot.
+	// When empty, any parameter is allowed.
+	namespaces []string
+	// check, if set, validates the settings against the database before
+	// they are applied, adding errors to diags.
+	check func(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, values map[string]attr.Value) bool
+
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+	parameterGuard   parameterGuard
+}
+
+type namespaceSettingsModel struct {
+	Role               string            `tfsdk:"role"`
+	Connection         types.String      `tfsdk:"connection"`
+	Database           types.String      `tfsdk:"database"`
+	Settings           map[string]string `tfsdk:"settings"`
+	Exclusive          bool              `tfsdk:"exclusive"`
+	DeletionProtection bool              `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool              `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String      `tfsdk:"sql"`
+	Retry              *retryModel       `tfsdk:"retry"`
+}
+
+// Metadata returns the resource type name.
+func (r *namespaceSettingsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.typeName
+}
+
+// parameterNameRe matches lowercase parameter names, optionally qualified by
+// the namespace of an extension, e.g. "citus.max_adaptive_executor_pool_size".
+var parameterNameRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// parameterRe returns the regular expression matching the names of the
+// parameters the resource may manage.
+func (r *namespaceSettingsResource) parameterRe() *regexp.Regexp {
+	if len(r.namespaces) == 0 {
+		return parameterNameRe
+	}
+	quoted := make([]string, len(r.namespaces))
+	for i, namespace := range r.namespaces {
+		quoted[i] = regexp.QuoteMeta(namespace)
+	}
+	return regexp.MustCompile(`^(` + strings.Join(quoted, "|") + `)\.[a-z0-9_]+$`)
+}
+
+// Schema defines the schema for the resource.
+func (r *namespaceSettingsResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	settingsDescription := "Map of parameter name to value. Names must be lowercase."
+	exclusiveDescription := "Whether parameters of the role missing from settings are reset to the database default, including those set outside of Terraform. Do not combine with other resources managing settings of the same role. Defaults to false."
+	if len(r.namespaces) > 0 {
+		namespaces := strings.Join(r.namespaces, " or ")
+		settingsDescription = fmt.Sprintf("Map of parameter name to value. Names must be lowercase and in the %s namespace.", namespaces)
+		exclusiveDescription = fmt.Sprintf("Whether parameters of the role in the %s namespace missing from settings are reset to the database default, including those set outside of Terraform. Defaults to false.", namespaces)
+	}
+
+	resp.Schema = schema.Schema{
+		Description: r.description,
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings": schema.MapAttribute{
+				Description: settingsDescription + " Parameters removed from the map are reset to the database default.",
+				ElementType: types.StringType,
+				Required:    true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.RegexMatches(r.parameterRe(), "must be a lowercase parameter name in an allowed namespace")),
+				},
+			},
+			"exclusive": schema.BoolAttribute{
+				Description: exclusiveDescription,
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"connection":            connectionAttribute(),
+			"database":              databaseAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
+		},
+	}
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *namespaceSettingsResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *namespaceSettingsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+	r.parameterGuard = data.parameterGuard
+}
+
+// ModifyPlan previews the SQL statements that the apply will run.
+func (r *namespaceSettingsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role, database types.String
+	var settings types.Map
+	var exclusive types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("database"), &database)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("settings"), &settings)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("exclusive"), &exclusive)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !settings.IsUnknown() {
+		names := make([]string, 0, len(settings.Elements()))
+		for name := range settings.Elements() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		resp.Diagnostics.Append(r.parameterGuard.check(path.Root("settings"), names...)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if role.IsUnknown() || database.IsUnknown() || !mapKnown(settings) {
+		return
+	}
+	// In exclusive mode, the parameters to reset are only known once the
+	// role is read on create
+	if req.State.Raw.IsNull() && (exclusive.IsUnknown() || exclusive.ValueBool()) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), types.StringUnknown())...)
+		return
+	}
+	var planned map[string]string
+	resp.Diagnostics.Append(settings.ElementsAs(ctx, &planned, false)...)
+
+	var prior map[string]string
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("settings"), &prior)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlApplyNamespaceSettings(role.ValueString(), database.ValueString(), planned, prior)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+}
+
+// mapKnown reports whether m and all its elements are known.
+func mapKnown(m types.Map) bool {
+	if m.IsUnknown() {
+		return false
+	}
+	for _, v := range m.Elements() {
+		if v.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *namespaceSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan
+	var plan namespaceSettingsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// In exclusive mode, reset the parameters set outside of Terraform
+	var unmanaged map[string]string
+	if plan.Exclusive {
+		var ok bool
+		if unmanaged, ok = r.readUnmanaged(ctx, &resp.Diagnostics, plan.Connection.ValueString(), plan.Role, plan.Database.ValueString(), plan.Settings); !ok {
+			return
+		}
+	}
+
+	sqlstr := sqlApplyNamespaceSettings(plan.Role, plan.Database.ValueString(), plan.Settings, unmanaged)
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "create", plan, true, sqlstr) {
+		return
+	}
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, plan.Connection, plan.Role, plan.Database.ValueString())...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *namespaceSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state namespaceSettingsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read the current values from the database
+	db, err := r.connect(state.Connection.ValueString(), state.Database.ValueString()).GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	config, err := readRoleDatabaseConfig(ctx, db, state.Role, state.Database.ValueString())
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query role settings",
+			fmt.Sprintf("Failed to query role settings for role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	// Overwrite the state with the actual values of the managed parameters
+	settings := make(map[string]string, len(state.Settings))
+	for name, expected := range state.Settings {
+		actual, ok := config[name]
+		if ok {
+			settings[name] = actual
+		}
+		addDriftWarning(&resp.Diagnostics, state.Role, "settings", name+"="+expected, name+"="+actual)
+	}
+	// In exclusive mode, parameters set outside of Terraform are drift too,
+	// so that the next apply resets them
+	if state.Exclusive {
+		re := r.parameterRe()
+		for _, name := range sortedKeys(config) {
+			if _, ok := state.Settings[name]; ok || !re.MatchString(name) {
+				continue
+			}
+			settings[name] = config[name]
+			addDriftWarning(&resp.Diagnostics, state.Role, "settings", "no "+name, name+"="+config[name])
+		}
+	}
+	state.Settings = settings
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, state.Connection, state.Role, state.Database.ValueString())...)
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *namespaceSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan and prior state
+	var plan, state namespaceSettingsModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlApplyNamespaceSettings(plan.Role, plan.Database.ValueString(), plan.Settings, state.Settings)
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "update", plan, true, sqlstr) {
+		return
+	}
+
+	// Set state to updated value
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, plan.Connection, plan.Role, plan.Database.ValueString())...)
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *namespaceSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	var state namespaceSettingsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	r.exec(ctx, &resp.Diagnostics, "delete", state, false, sqlApplyNamespaceSettings(state.Role, state.Database.ValueString(), nil, state.Settings))
+}
+
+func (r *namespaceSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, database, diags := importRoleDatabase(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := readRoleDatabaseConfig(ctx, db, role, database)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role settings",
+			fmt.Sprintf("Failed to query role settings for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	// Import every parameter of the allowed namespaces
+	re := r.parameterRe()
+	settings := map[string]string{}
+	for name, value := range config {
+		if re.MatchString(name) {
+			settings[name] = value
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("settings"), settings)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	if database != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database"), database)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("exclusive"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlApplyNamespaceSettings(role, database, settings, nil))...)
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, connection, role, database)...)
+}
+
+// readUnmanaged returns the parameters of role in database, or in all
+// databases if empty, in the namespaces of the resource that are missing from
+// settings, adding any error to diags. It connects through the named
+// connection, or the provider one if empty.
+func (r *namespaceSettingsResource) readUnmanaged(ctx context.Context, diags *diag.Diagnostics, connection, role, database string, settings map[string]string) (map[string]string, bool) {
+	db, err := r.connect(connection, database).GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return nil, false
+	}
+	defer db.Close()
+
+	config, err := readRoleDatabaseConfig(ctx, db, role, database)
+	if err != nil {
+		diags.AddError(
+			"Failed to query role settings",
+			fmt.Sprintf("Failed to query role settings for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return nil, false
+	}
+
+	re := r.parameterRe()
+	unmanaged := map[string]string{}
+	for name, value := range config {
+		if _, ok := settings[name]; !ok && re.MatchString(name) {
+			unmanaged[name] = value
+		}
+	}
+	return unmanaged, true
+}
+
+// exec runs sqlstr for the role of m during operation, e.g. "create", adding
+// any error to diags. When check is true, the settings of m are checked before.
+func (r *namespaceSettingsResource) exec(ctx context.Context, diags *diag.Diagnostics, operation string, m namespaceSettingsModel, check bool, sqlstr string) bool {
+	db, err := r.connect(m.Connection.ValueString(), m.Database.ValueString()).GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(m.Retry)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return false
+	}
+	if !checkPrivileges(ctx, db, diags, m.Role, "") {
+		return false
+	}
+	if check {
+		for _, name := range sortedKeys(m.Settings) {
+			if !checkParameterPrivilege(ctx, db, diags, "settings", name) {
+				return false
+			}
+		}
+		if r.check != nil {
+			values := make(map[string]attr.Value, len(m.Settings))
+			for name, value := range m.Settings {
+				values[name] = types.StringValue(value)
+			}
+			if !r.check(ctx, db, diags, values) {
+				return false
+			}
+		}
+	}
+	if sqlstr == "" {
+		return true
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(diags, operation, m.Role, err)
+		return false
+	}
+	if check && r.verifyWrites {
+		actual, err := readRoleDatabaseConfig(ctx, db, m.Role, m.Database.ValueString())
+		var discrepancies []string
+		for _, name := range sortedKeys(m.Settings) {
+			discrepancies = append(discrepancies, discrepancy(name, m.Settings[name], actual[name]))
+		}
+		return verifyWrite(diags, m.Role, err, discrepancies...)
+	}
+	return true
+}
+
+// sqlApplyNamespaceSettings returns the statements setting the parameters of
+// settings in database, or in all databases if empty, and resetting those of
+// prior that are no longer set.
+func sqlApplyNamespaceSettings(role, database string, settings, prior map[string]string) string {
+	var statements []string
+	for _, name := range sortedKeys(prior) {
+		if _, ok := settings[name]; !ok {
+			statements = append(statements, sqlResetRoleSetting(role, database, name))
+		}
+	}
+	for _, name := range sortedKeys(settings) {
+		statements = append(statements, sqlSetRoleSetting(role, database, name, pq.QuoteLiteral(settings[name])))
+	}
+	return strings.Join(statements, "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestNamespaceSettingsSQL(t *testing.T) {
+	prior := map[string]string{
+		"citus.enable_repartition_joins": "on",
+		"citus.log_remote_commands":      "on",
+	}
+	settings := map[string]string{
+		"citus.enable_repartition_joins":        "off",
+		"citus.max_adaptive_executor_pool_size": "4",
+	}
+
+	want := `ALTER ROLE "app" RESET citus.log_remote_commands;
+ALTER ROLE "app" SET citus.enable_repartition_joins = 'off';
+ALTER ROLE "app" SET citus.max_adaptive_executor_pool_size = '4';`
+	if got := sqlApplyNamespaceSettings("app", "", settings, prior); got != want {
+		t.Errorf("sqlApplyNamespaceSettings() = %q, want %q", got, want)
+	}
+
+	want = `ALTER ROLE "app" RESET citus.enable_repartition_joins;
+ALTER ROLE "app" RESET citus.log_remote_commands;`
+	if got := sqlApplyNamespaceSettings("app", "", nil, prior); got != want {
+		t.Errorf("sqlApplyNamespaceSettings() = %q, want %q", got, want)
+	}
+
+	want = `ALTER ROLE "app" IN DATABASE "analytics" SET citus.enable_repartition_joins = 'off';
+ALTER ROLE "app" IN DATABASE "analytics" SET citus.max_adaptive_executor_pool_size = '4';`
+	if got := sqlApplyNamespaceSettings("app", "analytics", settings, nil); got != want {
+		t.Errorf("sqlApplyNamespaceSettings() = %q, want %q", got, want)
+	}
+}
+
+func TestNamespaceSettingsParameterRe(t *testing.T) {
+	r := &namespaceSettingsResource{namespaces: []string{"citus"}}
+	re := r.parameterRe()
+	for name, want := range map[string]bool{
+		"citus.enable_repartition_joins": true,
+		"citus.Enable":                   false,
+		"citus.":                         false,
+		"citusx.enable":                  false,
+		"timescaledb.max_open_chunks":    false,
+		"work_mem":                       false,
+	} {
+		if got := re.MatchString(name); got != want {
+			t.Errorf("parameterRe().MatchString(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestNamespaceSettingsParameterReAnyNamespace(t *testing.T) {
+	r := &namespaceSettingsResource{}
+	re := r.parameterRe()
+	for name, want := range map[string]bool{
+		"work_mem":                       true,
+		"citus.enable_repartition_joins": true,
+		"Work_mem":                       false,
+		"work_mem; DROP ROLE app":        false,
+		"a.b.c":                          false,
+	} {
+		if got := re.MatchString(name); got != want {
+			t.Errorf("parameterRe().MatchString(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewParallelQuerySettingsResource is a helper function to simplify the provider implementation.
+func NewParallelQuerySettingsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "parallel_query_settings",
+		description: `Manage the parallel query settings of an existing role. Settings left unset are reset to the database default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-query.html#RUNTIME-CONFIG-QUERY-CONSTANTS) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "max_parallel_workers_per_gather",
+				Parameter:   "max_parallel_workers_per_gather",
+				Description: "The maximum number of workers started by a single Gather or Gather Merge node, 0 to disable parallel query.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.Between(0, 1024),
+				},
+			},
+			{
+				Attribute:   "parallel_setup_cost",
+				Parameter:   "parallel_setup_cost",
+				Description: "The planner's estimate of the cost of launching parallel worker processes.",
+				Type:        settingFloat64,
+				Float64Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "parallel_tuple_cost",
+				Parameter:   "parallel_tuple_cost",
+				Description: "The planner's estimate of the cost of transferring one tuple from a parallel worker process.",
+				Type:        settingFloat64,
+				Float64Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "min_parallel_table_scan_size",
+				Parameter:   "min_parallel_table_scan_size",
+				Description: "The minimum amount of table data for a parallel scan to be considered, e.g. \"8MB\".",
+				StringValidators: []validator.String{
+					memoryValidator{},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestParallelQuerySettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_parallel_query_settings" "test" {
+  role                            = "example_user"
+  max_parallel_workers_per_gather = 4
+  min_parallel_table_scan_size    = "64MB"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_parallel_query_settings.test", "max_parallel_workers_per_gather", "4"),
+					resource.TestCheckResourceAttr("pgrole_parallel_query_settings.test", "min_parallel_table_scan_size", "64MB"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_parallel_query_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET max_parallel_workers_per_gather = 0;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
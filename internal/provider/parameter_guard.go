@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	pathpkg "path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// parameterGuard constrains the configuration parameters resources may set
+// on roles, so that platform teams can delegate the provider to application
+// teams. Parameters are matched against shell patterns, e.g. "citus.*",
+// case-insensitively like PostgreSQL, e.g. IntervalStyle matches
+// "intervalstyle". The zero value allows every parameter.
+type parameterGuard struct {
+	// allowed are the lowercase patterns of the only parameters allowed, if
+	// not nil.
+	allowed []string
+	// denied are the lowercase patterns of the parameters never allowed, even
+	// if allowed.
+	denied []string
+}
+
+// newParameterGuard returns the guard of the allowed_parameters and
+// denied_parameters patterns of the provider, or an error on the first
+// malformed one.
+func newParameterGuard(allowed, denied []string) (parameterGuard, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	for attribute, patterns := range map[string][]string{"allowed_parameters": allowed, "denied_parameters": denied} {
+		for _, pattern := range patterns {
+			if _, err := pathpkg.Match(pattern, ""); err != nil {
+				diags.AddAttributeError(
+					path.Root(attribute),
+					"Invalid parameter pattern",
+					fmt.Sprintf("%q is not a valid pattern: %s", pattern, err),
+				)
+			}
+		}
+	}
+	return parameterGuard{allowed: toLower(allowed), denied: toLower(denied)}, diags
+}
+
+// toLower returns patterns in lowercase, or nil if nil.
+func toLower(patterns []string) []string {
+	if patterns == nil {
+		return nil
+	}
+	lower := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lower[i] = strings.ToLower(pattern)
+	}
+	return lower
+}
+
+// allows reports whether resources may set parameter.
+func (g parameterGuard) allows(parameter string) bool {
+	parameter = strings.ToLower(parameter)
+	if g.allowed != nil && !matchesAny(g.allowed, parameter) {
+		return false
+	}
+	return !matchesAny(g.denied, parameter)
+}
+
+// check returns an error on attribute for each of parameters that resources
+// may not set.
+func (g parameterGuard) check(attribute path.Path, parameters ...string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, parameter := range parameters {
+		if !g.allows(parameter) {
+			diags.AddAttributeError(
+				attribute,
+				"Parameter not allowed",
+				fmt.Sprintf("Parameter %s cannot be managed with this provider configuration: it is constrained by the allowed_parameters and denied_parameters of the provider.", parameter),
+			)
+		}
+	}
+	return diags
+}
+
+// matchesAny reports whether name matches any of patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := pathpkg.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
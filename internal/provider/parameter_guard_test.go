@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestParameterGuardAllows(t *testing.T) {
+	tests := []struct {
+		guard parameterGuard
+		name  string
+		want  bool
+	}{
+		{guard: parameterGuard{}, name: "session_replication_role", want: true},
+		{guard: parameterGuard{allowed: []string{"work_mem", "citus.*"}}, name: "work_mem", want: true},
+		{guard: parameterGuard{allowed: []string{"work_mem", "citus.*"}}, name: "citus.log_remote_commands", want: true},
+		{guard: parameterGuard{allowed: []string{"work_mem", "citus.*"}}, name: "statement_timeout", want: false},
+		{guard: parameterGuard{allowed: []string{}}, name: "work_mem", want: false},
+		{guard: parameterGuard{denied: []string{"pgaudit.*"}}, name: "pgaudit.log", want: false},
+		{guard: parameterGuard{denied: []string{"pgaudit.*"}}, name: "work_mem", want: true},
+		{guard: parameterGuard{allowed: []string{"*"}, denied: []string{"session_replication_role"}}, name: "session_replication_role", want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.guard.allows(tt.name); got != tt.want {
+			t.Errorf("%+v.allows(%q) = %t, want %t", tt.guard, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParameterGuardCaseInsensitive(t *testing.T) {
+	guard, diags := newParameterGuard(nil, []string{"intervalstyle", "Citus.*"})
+	if diags.HasError() {
+		t.Fatalf("newParameterGuard() error = %v", diags)
+	}
+	for _, name := range []string{"IntervalStyle", "intervalstyle", "citus.log_remote_commands"} {
+		if guard.allows(name) {
+			t.Errorf("allows(%q) = true, want denied", name)
+		}
+	}
+
+	guard, _ = newParameterGuard([]string{"intervalstyle"}, nil)
+	if !guard.allows("IntervalStyle") {
+		t.Error(`allows("IntervalStyle") = false, want allowed by "intervalstyle"`)
+	}
+	if guard.allows("DateStyle") {
+		t.Error(`allows("DateStyle") = true, want denied`)
+	}
+}
+
+func TestNewParameterGuard(t *testing.T) {
+	if _, diags := newParameterGuard([]string{"work_mem", "citus.*"}, []string{"pgaudit.*"}); diags.HasError() {
+		t.Errorf("newParameterGuard() error = %v", diags)
+	}
+	_, diags := newParameterGuard(nil, []string{"pgaudit.["})
+	if !diags.HasError() || diags[0].Summary() != "Invalid parameter pattern" {
+		t.Errorf("newParameterGuard() = %v, want an invalid pattern error", diags)
+	}
+}
+
+func TestParameterGuardModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	guard := parameterGuard{denied: []string{"bytea_output", "citus.*"}}
+
+	modifyPlan := func(r resource.ResourceWithModifyPlan, values map[string]tftypes.Value) *resource.ModifyPlanResponse {
+		var schemaResp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+		typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+		all := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+		for name, attrType := range typ.AttributeTypes {
+			all[name] = tftypes.NewValue(attrType, nil)
+		}
+		all["role"] = tftypes.NewValue(tftypes.String, "app")
+		for name, value := range values {
+			all[name] = value
+		}
+		raw := tftypes.NewValue(typ, all)
+		resp := &resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw}}
+		r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw},
+			Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: raw},
+			State:  tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(typ, nil)},
+		}, resp)
+		return resp
+	}
+	wantDenied := func(t *testing.T, resp *resource.ModifyPlanResponse, parameter string) {
+		t.Helper()
+		if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Parameter not allowed" || !strings.Contains(resp.Diagnostics[0].Detail(), parameter) {
+			t.Errorf("ModifyPlan() = %v, want %s to be denied", resp.Diagnostics, parameter)
+		}
+	}
+
+	byteaOutput := NewByteaOutputResource().(*roleSettingsResource)
+	byteaOutput.parameterGuard = guard
+	wantDenied(t, modifyPlan(byteaOutput, map[string]tftypes.Value{"format": tftypes.NewValue(tftypes.String, "hex")}), "bytea_output")
+	fromCurrent := tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "format")})
+	wantDenied(t, modifyPlan(byteaOutput, map[string]tftypes.Value{"from_current": fromCurrent}), "bytea_output")
+
+	configMap := NewConfigMapResource().(*namespaceSettingsResource)
+	configMap.parameterGuard = guard
+	settings := func(names ...string) tftypes.Value {
+		values := map[string]tftypes.Value{}
+		for _, name := range names {
+			values[name] = tftypes.NewValue(tftypes.String, "on")
+		}
+		return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, values)
+	}
+	wantDenied(t, modifyPlan(configMap, map[string]tftypes.Value{"settings": settings("work_mem", "citus.log_remote_commands")}), "citus.log_remote_commands")
+	if resp := modifyPlan(configMap, map[string]tftypes.Value{"settings": settings("work_mem")}); resp.Diagnostics.HasError() {
+		t.Errorf("ModifyPlan() error = %v", resp.Diagnostics)
+	}
+}
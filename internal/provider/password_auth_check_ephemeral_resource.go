@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = (*passwordAuthCheckEphemeralResource)(nil)
+	_ ephemeral.EphemeralResourceWithConfigure = (*passwordAuthCheckEphemeralResource)(nil)
+)
+
+// NewPasswordAuthCheckEphemeralResource is a helper function to simplify the provider implementation.
+func NewPasswordAuthCheckEphemeralResource() ephemeral.EphemeralResource {
+	return &passwordAuthCheckEphemeralResource{}
+}
+
+type passwordAuthCheckEphemeralResource struct {
+	connectAs func(connection, database, role, password string) DBGetter
+}
+
+// Metadata returns the ephemeral resource type name.
+func (r *passwordAuthCheckEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_password_auth_check"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *passwordAuthCheckEphemeralResource) Schema(_ context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Checks that a role can log in with a password, by opening a test connection as the role with the settings of the provider connection, without ever persisting the password in the plan or state.
+
+Useful in rotation pipelines to verify the new credential before retiring the old one, e.g. in a postcondition. The password may come from other ephemeral values, such as ` + "`pgrole_password`" + ` or a secret manager. This is an ephemeral resource rather than a data source since data sources cannot take ephemeral values. A refused authentication is reported by success, while failures to reach the server are errors.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection whose settings are used, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"database": schema.StringAttribute{
+				Description: "Name of the database to connect to. Defaults to the database of the connection.",
+				Optional:    true,
+			},
+			"role": schema.StringAttribute{
+				Description: "Name of the role to log in as.",
+				Required:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "The password to check.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"success": schema.BoolAttribute{
+				Description: "Whether the role logged in with the password.",
+				Computed:    true,
+			},
+			"error": schema.StringAttribute{
+				Description: "The reason the server refused the login, e.g. password authentication failed, or null on success.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+type passwordAuthCheckModel struct {
+	Connection types.String `tfsdk:"connection"`
+	Database   types.String `tfsdk:"database"`
+	Role       string       `tfsdk:"role"`
+	Password   string       `tfsdk:"password"`
+	Success    types.Bool   `tfsdk:"success"`
+	Error      types.String `tfsdk:"error"`
+}
+
+// Configure adds the provider configured client to the ephemeral resource.
+func (r *passwordAuthCheckEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connectAs = data.connectAs
+}
+
+// Open logs in as the role.
+func (r *passwordAuthCheckEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data passwordAuthCheckModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := checkPasswordAuth(ctx, r.connectAs(data.Connection.ValueString(), data.Database.ValueString(), data.Role, data.Password))
+	switch {
+	case err == nil:
+		data.Success, data.Error = types.BoolValue(true), types.StringNull()
+	case isAuthenticationFailure(err):
+		tflog.Info(ctx, "Password authentication refused", map[string]any{
+			"role":  data.Role,
+			"error": err.Error(),
+		})
+		data.Success, data.Error = types.BoolValue(false), types.StringValue(err.Error())
+	default:
+		resp.Diagnostics.AddError(
+			"Failed to check password",
+			fmt.Sprintf("Failed to connect as role %s to check its password: %s", data.Role, err),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// checkPasswordAuth opens a connection with getter, and returns the error
+// refusing it if any.
+func checkPasswordAuth(ctx context.Context, getter DBGetter) error {
+	db, err := getter.GetDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Connections are opened lazily by the Cloud SQL connector
+	return db.PingContext(ctx)
+}
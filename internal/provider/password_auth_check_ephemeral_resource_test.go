@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/lib/pq"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestPasswordAuthCheckOpen(t *testing.T) {
+	refused := &pq.Error{Code: "28P01", Message: `password authentication failed for user "app"`}
+	tests := []struct {
+		name        string
+		err         error
+		wantSuccess bool
+		wantError   string
+		wantDiag    bool
+	}{
+		{name: "success", wantSuccess: true},
+		{name: "refused", err: fmt.Errorf("error connecting to database: %w", refused), wantError: "error connecting to database: pq: password authentication failed for user \"app\""},
+		{name: "unreachable", err: errors.New("dial tcp: connection refused"), wantDiag: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRole, gotPassword string
+			data := &providerData{
+				connectAs: func(connection, database, role, password string) DBGetter {
+					gotRole, gotPassword = role, password
+					if tt.err != nil {
+						return F(func(context.Context) (*sql.DB, error) { return nil, tt.err })
+					}
+					return fakedb.New()
+				},
+			}
+			resp := testOpenEphemeral(t, NewPasswordAuthCheckEphemeralResource(), data, map[string]tftypes.Value{
+				"role":     tftypes.NewValue(tftypes.String, "app"),
+				"password": tftypes.NewValue(tftypes.String, "s3cret"),
+			})
+			if gotRole != "app" || gotPassword != "s3cret" {
+				t.Errorf("connectAs() role, password = %q, %q, want app, s3cret", gotRole, gotPassword)
+			}
+			if tt.wantDiag {
+				if !resp.Diagnostics.HasError() {
+					t.Error("Open() error = nil, want an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Open() error = %v", resp.Diagnostics)
+			}
+
+			var result passwordAuthCheckModel
+			resp.Diagnostics.Append(resp.Result.Get(context.Background(), &result)...)
+			if result.Success.ValueBool() != tt.wantSuccess || result.Error.ValueString() != tt.wantError {
+				t.Errorf("Open() = %v, %v, want %v, %q", result.Success, result.Error, tt.wantSuccess, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource = (*passwordEphemeralResource)(nil)
+)
+
+const (
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordNumeric = "0123456789"
+	// passwordSpecial leaves out quotes, backslashes and characters with a
+	// meaning in connection URLs or strings, e.g. "#", "%", "&" and ";", so
+	// that passwords are safe to paste.
+	passwordSpecial = "!$*()-_{}<>.,~^"
+
+	defaultPasswordLength = 32
+)
+
+// NewPasswordEphemeralResource is a helper function to simplify the provider implementation.
+func NewPasswordEphemeralResource() ephemeral.EphemeralResource {
+	return &passwordEphemeralResource{}
+}
+
+type passwordEphemeralResource struct{}
+
+// Metadata returns the ephemeral resource type name.
+func (r *passwordEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_password"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *passwordEphemeralResource) Schema(_ context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Generates a random role password, without ever persisting it in the plan or state.
+
+Pass the result to a write-only argument, e.g. of a secret manager or a role resource, so that passwords can be rotated without secrets touching state. At least one character of each enabled character class is included.`,
+		Attributes: map[string]schema.Attribute{
+			"length": schema.Int64Attribute{
+				Description: "The length of the password. Defaults to 32.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.Between(8, 1024),
+				},
+			},
+			"lower": schema.BoolAttribute{
+				Description: "Whether to include lowercase letters. Defaults to true.",
+				Optional:    true,
+			},
+			"upper": schema.BoolAttribute{
+				Description: "Whether to include uppercase letters. Defaults to true.",
+				Optional:    true,
+			},
+			"numeric": schema.BoolAttribute{
+				Description: "Whether to include digits. Defaults to true.",
+				Optional:    true,
+			},
+			"special": schema.BoolAttribute{
+				Description: "Whether to include special characters. Defaults to true.",
+				Optional:    true,
+			},
+			"override_special": schema.StringAttribute{
+				Description: "The special characters to use instead of the default set. Only printable ASCII characters are allowed.",
+				Optional:    true,
+				Validators:  []validator.String{asciiValidator{}},
+			},
+			"result": schema.StringAttribute{
+				Description: "The generated password.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+type passwordModel struct {
+	Length          types.Int64  `tfsdk:"length"`
+	Lower           types.Bool   `tfsdk:"lower"`
+	Upper           types.Bool   `tfsdk:"upper"`
+	Numeric         types.Bool   `tfsdk:"numeric"`
+	Special         types.Bool   `tfsdk:"special"`
+	OverrideSpecial types.String `tfsdk:"override_special"`
+	Result          types.String `tfsdk:"result"`
+}
+
+// passwordPolicy describes the passwords to generate.
+type passwordPolicy struct {
+	Length  int
+	Classes []string
+}
+
+// policy returns the password policy of the model, applying defaults.
+func (m passwordModel) policy() passwordPolicy {
+	p := passwordPolicy{Length: defaultPasswordLength}
+	if !m.Length.IsNull() {
+		p.Length = int(m.Length.ValueInt64())
+	}
+	special := passwordSpecial
+	if !m.OverrideSpecial.IsNull() {
+		special = m.OverrideSpecial.ValueString()
+	}
+	for _, class := range []struct {
+		enabled types.Bool
+		chars   string
+	}{
+		{m.Lower, passwordLower},
+		{m.Upper, passwordUpper},
+		{m.Numeric, passwordNumeric},
+		{m.Special, special},
+	} {
+		if class.enabled.IsNull() || class.enabled.ValueBool() {
+			if class.chars != "" {
+				p.Classes = append(p.Classes, class.chars)
+			}
+		}
+	}
+	return p
+}
+
+// generatePassword returns a random password with at least one character of
+// each class of the policy.
+func generatePassword(p passwordPolicy) (string, error) {
+	if len(p.Classes) == 0 {
+		return "", errors.New("at least one character class must be enabled")
+	}
+	if p.Length < len(p.Classes) {
+		return "", errors.New("length is too short to include every character class")
+	}
+
+	all := ""
+	for _, class := range p.Classes {
+		all += class
+	}
+
+	password := make([]rune, p.Length)
+	for i := range password {
+		chars := all
+		if i < len(p.Classes) {
+			chars = p.Classes[i]
+		}
+		c, err := randomChar(chars)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	// Shuffle so that the guaranteed characters are not always first.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+// randomChar returns a random character of chars, which may be multibyte.
+func randomChar(chars string) (rune, error) {
+	runes := []rune(chars)
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(runes))))
+	if err != nil {
+		return 0, err
+	}
+	return runes[n.Int64()], nil
+}
+
+// Open generates the password.
+func (r *passwordEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data passwordModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	password, err := generatePassword(data.policy())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to generate password",
+			"Failed to generate password: "+err.Error(),
+		)
+		return
+	}
+
+	data.Result = types.StringValue(password)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
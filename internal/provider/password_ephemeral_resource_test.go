@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	policy := passwordModel{
+		Length:          types.Int64Value(12),
+		Lower:           types.BoolNull(),
+		Upper:           types.BoolValue(false),
+		Numeric:         types.BoolNull(),
+		Special:         types.BoolNull(),
+		OverrideSpecial: types.StringValue("!"),
+	}.policy()
+
+	for range 100 {
+		password, err := generatePassword(policy)
+		if err != nil {
+			t.Fatalf("generatePassword() error = %v", err)
+		}
+		if len(password) != 12 {
+			t.Errorf("generatePassword() = %q, want 12 characters", password)
+		}
+		if strings.ContainsAny(password, passwordUpper) {
+			t.Errorf("generatePassword() = %q, want no uppercase letters", password)
+		}
+		for _, class := range []string{passwordLower, passwordNumeric, "!"} {
+			if !strings.ContainsAny(password, class) {
+				t.Errorf("generatePassword() = %q, want at least one of %q", password, class)
+			}
+		}
+	}
+
+	if _, err := generatePassword(passwordPolicy{Length: 8}); err == nil {
+		t.Error("generatePassword() without character classes succeeded, want error")
+	}
+}
+
+func TestGeneratePasswordMultibyte(t *testing.T) {
+	password, err := generatePassword(passwordPolicy{Length: 16, Classes: []string{"é€"}})
+	if err != nil {
+		t.Fatalf("generatePassword() error = %v", err)
+	}
+	if !utf8.ValidString(password) || utf8.RuneCountInString(password) != 16 {
+		t.Errorf("generatePassword() = %q, want 16 valid characters", password)
+	}
+}
+
+func TestPasswordSpecialURLSafe(t *testing.T) {
+	if strings.ContainsAny(passwordSpecial, `:/?#[]@%&=+;'"\`) {
+		t.Errorf("passwordSpecial = %q, want no characters with a meaning in connection URLs or strings", passwordSpecial)
+	}
+}
+
+func TestASCIIValidator(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		value     types.String
+		wantError bool
+	}{
+		{types.StringValue("s3cr3t!~"), false},
+		{types.StringNull(), false},
+		{types.StringUnknown(), false},
+		{types.StringValue("pässword"), true},
+		{types.StringValue("tab\there"), true},
+	}
+	for _, tt := range tests {
+		var resp validator.StringResponse
+		asciiValidator{}.ValidateString(ctx, validator.StringRequest{ConfigValue: tt.value}, &resp)
+		if got := resp.Diagnostics.HasError(); got != tt.wantError {
+			t.Errorf("asciiValidator(%s) error = %v, want %v", tt.value, got, tt.wantError)
+		}
+	}
+}
+
+func TestPasswordOpen(t *testing.T) {
+	resp := testOpenEphemeral(t, NewPasswordEphemeralResource(), nil, map[string]tftypes.Value{
+		"length":  tftypes.NewValue(tftypes.Number, 20),
+		"special": tftypes.NewValue(tftypes.Bool, false),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Open() error = %v", resp.Diagnostics)
+	}
+
+	var result passwordModel
+	resp.Diagnostics.Append(resp.Result.Get(context.Background(), &result)...)
+	password := result.Result.ValueString()
+	if len(password) != 20 || strings.ContainsAny(password, passwordSpecial) {
+		t.Errorf("Open() result = %q, want 20 characters without special characters", password)
+	}
+}
@@ -0,0 +1,482 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = (*passwordExpiryNotifierResource)(nil)
+	_ resource.ResourceWithConfigure   = (*passwordExpiryNotifierResource)(nil)
+	_ resource.ResourceWithImportState = (*passwordExpiryNotifierResource)(nil)
+	_ resource.ResourceWithIdentity    = (*passwordExpiryNotifierResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*passwordExpiryNotifierResource)(nil)
+)
+
+// NewPasswordExpiryNotifierResource is a helper function to simplify the provider implementation.
+func NewPasswordExpiryNotifierResource() resource.Resource {
+	return &passwordExpiryNotifierResource{}
+}
+
+type passwordExpiryNotifierResource struct {
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+}
+
+// validUntilInfinity is the VALID UNTIL of passwords that never expire.
+const validUntilInfinity = "infinity"
+
+// Metadata returns the resource type name.
+func (r *passwordExpiryNotifierResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_password_expiry_notifier"
+}
+
+// Schema defines the schema for the resource.
+func (r *passwordExpiryNotifierResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Manage the VALID UNTIL expiry of the password of an existing role, and expose how long is left before it lapses. See PostgreSQL [ALTER ROLE](https://www.postgresql.org/docs/current/sql-alterrole.html).
+
+The days_until_expiry and expired attributes are computed at plan time, so that outputs and preconditions can fail a pipeline before the credentials lapse. Plans also warn once fewer than warn_days are left.`,
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"valid_until": schema.StringAttribute{
+				Description: "The time the password of the role expires, as an RFC 3339 timestamp, e.g. \"2026-12-31T00:00:00Z\", or \"infinity\" for a password that never expires.",
+				Required:    true,
+				Validators: []validator.String{
+					validUntilValidator{},
+				},
+			},
+			"warn_days": schema.Int64Attribute{
+				Description: "Number of days before the expiry from which plans warn that the password is about to expire. Defaults to 14.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(14),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"days_until_expiry": schema.Int64Attribute{
+				Description: "Number of whole days left before the password expires, negative once it has expired, or null if it never expires.",
+				Computed:    true,
+			},
+			"expired": schema.BoolAttribute{
+				Description: "Whether the password has expired.",
+				Computed:    true,
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
+		},
+	}
+}
+
+type passwordExpiryNotifierModel struct {
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	ValidUntil         string       `tfsdk:"valid_until"`
+	WarnDays           int64        `tfsdk:"warn_days"`
+	DaysUntilExpiry    types.Int64  `tfsdk:"days_until_expiry"`
+	Expired            types.Bool   `tfsdk:"expired"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// setExpiry sets the computed attributes of m from its valid_until at now.
+func (m *passwordExpiryNotifierModel) setExpiry(now time.Time) {
+	until, ok := parseValidUntil(m.ValidUntil)
+	if !ok || m.ValidUntil == validUntilInfinity {
+		m.DaysUntilExpiry = types.Int64Null()
+		m.Expired = types.BoolValue(false)
+		return
+	}
+	m.DaysUntilExpiry = types.Int64Value(int64(math.Floor(until.Sub(now).Hours() / 24)))
+	m.Expired = types.BoolValue(!now.Before(until))
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *passwordExpiryNotifierResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *passwordExpiryNotifierResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan computes the expiry attributes, warns about passwords about to
+// expire, and previews the SQL statement that the apply will run.
+func (r *passwordExpiryNotifierResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role, validUntil types.String
+	var warnDays types.Int64
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("valid_until"), &validUntil)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("warn_days"), &warnDays)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || validUntil.IsUnknown() || warnDays.IsUnknown() {
+		return
+	}
+
+	m := passwordExpiryNotifierModel{Role: role.ValueString(), ValidUntil: validUntil.ValueString(), WarnDays: warnDays.ValueInt64()}
+	m.setExpiry(time.Now())
+	addExpiryWarning(&resp.Diagnostics, m)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("days_until_expiry"), m.DaysUntilExpiry)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expired"), m.Expired)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlSetValidUntil(m.Role, m.ValidUntil))...)
+}
+
+// addExpiryWarning adds a warning to diags if the password of m has expired
+// or expires within its warn_days.
+func addExpiryWarning(diags *diag.Diagnostics, m passwordExpiryNotifierModel) {
+	switch {
+	case m.Expired.ValueBool():
+		diags.AddAttributeWarning(
+			path.Root("valid_until"),
+			"Password expired",
+			fmt.Sprintf("The password of role %s expired at %s: the role can no longer log in with it. Rotate the password and move valid_until forward.", m.Role, m.ValidUntil),
+		)
+	case !m.DaysUntilExpiry.IsNull() && m.DaysUntilExpiry.ValueInt64() < m.WarnDays:
+		diags.AddAttributeWarning(
+			path.Root("valid_until"),
+			"Password about to expire",
+			fmt.Sprintf("The password of role %s expires in %d days, at %s. Rotate the password and move valid_until forward.", m.Role, m.DaysUntilExpiry.ValueInt64(), m.ValidUntil),
+		)
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *passwordExpiryNotifierResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan
+	var plan passwordExpiryNotifierModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlSetValidUntil(plan.Role, plan.ValidUntil)
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "create", plan, sqlstr) {
+		return
+	}
+	plan.setExpiry(time.Now())
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *passwordExpiryNotifierResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state passwordExpiryNotifierModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	validUntil, err := readValidUntil(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query VALID UNTIL",
+			fmt.Sprintf("Failed to query VALID UNTIL for role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	// Keep the configured spelling of the same time, e.g. in another time
+	// zone
+	if !sameValidUntil(state.ValidUntil, validUntil) {
+		addDriftWarning(&resp.Diagnostics, state.Role, "valid_until", state.ValidUntil, validUntil)
+		state.ValidUntil = validUntil
+	}
+	state.setExpiry(time.Now())
+
+	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *passwordExpiryNotifierResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve value from plan
+	var plan passwordExpiryNotifierModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlSetValidUntil(plan.Role, plan.ValidUntil)
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "update", plan, sqlstr) {
+		return
+	}
+	plan.setExpiry(time.Now())
+
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete lifts the expiry of the password, and removes the Terraform state on
+// success.
+func (r *passwordExpiryNotifierResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	var state passwordExpiryNotifierModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	r.exec(ctx, &resp.Diagnostics, "delete", state, sqlSetValidUntil(state.Role, validUntilInfinity))
+}
+
+func (r *passwordExpiryNotifierResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validUntil, err := readValidUntil(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query VALID UNTIL",
+			fmt.Sprintf("Failed to query VALID UNTIL for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	m := passwordExpiryNotifierModel{
+		Role:       role,
+		Connection: connection,
+		ValidUntil: validUntil,
+		WarnDays:   14,
+		SQL:        types.StringValue(sqlSetValidUntil(role, validUntil)),
+	}
+	m.setExpiry(time.Now())
+	resp.Diagnostics.Append(resp.State.Set(ctx, m)...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// exec runs sqlstr on the role of m during operation, e.g. "create", and
+// returns false with an error in diags if it failed.
+func (r *passwordExpiryNotifierResource) exec(ctx context.Context, diags *diag.Diagnostics, operation string, m passwordExpiryNotifierModel, sqlstr string) bool {
+	db, err := r.connect(m.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(m.Retry)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return false
+	}
+	if !checkPrivileges(ctx, db, diags, m.Role, "") {
+		return false
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(diags, operation, m.Role, err)
+		return false
+	}
+	if r.verifyWrites && operation != "delete" {
+		actual, err := readValidUntil(ctx, db, m.Role)
+		want := m.ValidUntil
+		if sameValidUntil(want, actual) {
+			want = actual
+		}
+		if !verifyWrite(diags, m.Role, err, discrepancy("valid_until", want, actual)) {
+			return false
+		}
+	}
+	return true
+}
+
+// readValidUntil returns the VALID UNTIL of the role as an RFC 3339 timestamp
+// in UTC, or "infinity" if its password never expires, or sql.ErrNoRows if the
+// role does not exist.
+func readValidUntil(ctx context.Context, db *sql.DB, role string) (string, error) {
+	var validUntil string
+	err := db.QueryRowContext(ctx, `SELECT CASE WHEN rolvaliduntil IS NULL OR NOT isfinite(rolvaliduntil) THEN 'infinity'
+	ELSE to_char(rolvaliduntil AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') END
+FROM pg_roles WHERE rolname = $1;`, role).Scan(&validUntil)
+	return validUntil, err
+}
+
+// parseValidUntil parses a valid_until value, returning false if it is
+// neither an RFC 3339 timestamp nor "infinity".
+func parseValidUntil(s string) (time.Time, bool) {
+	if s == validUntilInfinity {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
+
+// sameValidUntil reports whether a and b are the same valid_until, possibly
+// spelled in different time zones.
+func sameValidUntil(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ta, okA := parseValidUntil(a)
+	tb, okB := parseValidUntil(b)
+	return okA && okB && a != validUntilInfinity && b != validUntilInfinity && ta.Equal(tb)
+}
+
+func sqlSetValidUntil(role, validUntil string) string {
+	return fmt.Sprintf("ALTER ROLE %s VALID UNTIL %s;", quoteIdentifier(role), pq.QuoteLiteral(validUntil))
+}
+
+// validUntilValidator validates that a string is a valid_until value.
+type validUntilValidator struct{}
+
+func (v validUntilValidator) Description(_ context.Context) string {
+	return `value must be an RFC 3339 timestamp or "infinity"`
+}
+
+func (v validUntilValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v validUntilValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, ok := parseValidUntil(req.ConfigValue.ValueString()); !ok {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid valid_until",
+			fmt.Sprintf("%q is neither an RFC 3339 timestamp, e.g. \"2026-12-31T00:00:00Z\", nor \"infinity\".", req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestPasswordExpiryNotifierSetExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		validUntil string
+		days       types.Int64
+		expired    bool
+	}{
+		{"infinity", types.Int64Null(), false},
+		{"2026-04-30T12:00:00Z", types.Int64Value(30), false},
+		{"2026-04-01T11:59:59Z", types.Int64Value(0), false},
+		{"2026-03-31T14:00:00+02:00", types.Int64Value(0), true},
+		{"2026-03-01T00:00:00Z", types.Int64Value(-31), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.validUntil, func(t *testing.T) {
+			m := passwordExpiryNotifierModel{ValidUntil: tt.validUntil}
+			m.setExpiry(now)
+			if !m.DaysUntilExpiry.Equal(tt.days) || m.Expired.ValueBool() != tt.expired {
+				t.Errorf("setExpiry() = %v, %v, want %v, %v", m.DaysUntilExpiry, m.Expired, tt.days, tt.expired)
+			}
+		})
+	}
+}
+
+func TestSameValidUntil(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"infinity", "infinity", true},
+		{"2026-12-31T00:00:00Z", "2026-12-31T00:00:00Z", true},
+		{"2026-12-31T02:00:00+02:00", "2026-12-31T00:00:00Z", true},
+		{"2026-12-31T00:00:00Z", "2027-01-01T00:00:00Z", false},
+		{"infinity", "2026-12-31T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		if got := sameValidUntil(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameValidUntil(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordExpiryNotifierResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "pgrole_password_expiry_notifier" "test" {
+  role        = "test"
+  valid_until = "2099-12-31T00:00:00Z"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "role", "test"),
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "valid_until", "2099-12-31T00:00:00Z"),
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "warn_days", "14"),
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "expired", "false"),
+					resource.TestCheckResourceAttrSet("pgrole_password_expiry_notifier.test", "days_until_expiry"),
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "sql", `ALTER ROLE "test" VALID UNTIL '2099-12-31T00:00:00Z';`),
+				),
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + `
+resource "pgrole_password_expiry_notifier" "test" {
+  role        = "test"
+  valid_until = "infinity"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "valid_until", "infinity"),
+					resource.TestCheckResourceAttr("pgrole_password_expiry_notifier.test", "expired", "false"),
+					resource.TestCheckNoResourceAttr("pgrole_password_expiry_notifier.test", "days_until_expiry"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_password_expiry_notifier.test",
+				ImportState:                          true,
+				ImportStateId:                        "test",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+		},
+	})
+}
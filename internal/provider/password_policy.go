@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// passwordPolicyModel describes the password_policy attribute of the provider.
+type passwordPolicyModel struct {
+	MinLength        types.Int64 `tfsdk:"min_length"`
+	RequireLower     types.Bool  `tfsdk:"require_lower"`
+	RequireUpper     types.Bool  `tfsdk:"require_upper"`
+	RequireNumeric   types.Bool  `tfsdk:"require_numeric"`
+	RequireSpecial   types.Bool  `tfsdk:"require_special"`
+	DisallowRoleName types.Bool  `tfsdk:"disallow_role_name"`
+}
+
+// passwordRules are the rules passwords set by pgrole_password must follow.
+// The zero value allows any password.
+type passwordRules struct {
+	MinLength        int
+	RequireLower     bool
+	RequireUpper     bool
+	RequireNumeric   bool
+	RequireSpecial   bool
+	DisallowRoleName bool
+}
+
+// rules returns the password rules of the model, which may be nil.
+func (m *passwordPolicyModel) rules() passwordRules {
+	if m == nil {
+		return passwordRules{}
+	}
+	return passwordRules{
+		MinLength:        int(m.MinLength.ValueInt64()),
+		RequireLower:     m.RequireLower.ValueBool(),
+		RequireUpper:     m.RequireUpper.ValueBool(),
+		RequireNumeric:   m.RequireNumeric.ValueBool(),
+		RequireSpecial:   m.RequireSpecial.ValueBool(),
+		DisallowRoleName: m.DisallowRoleName.ValueBool(),
+	}
+}
+
+// violations returns the rules that password, to be set for role, breaks.
+func (r passwordRules) violations(role, password string) []string {
+	var lower, upper, numeric, special bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsDigit(c):
+			numeric = true
+		default:
+			special = true
+		}
+	}
+
+	var violations []string
+	if n := len([]rune(password)); n < r.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long, got %d", r.MinLength, n))
+	}
+	if r.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if r.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if r.RequireNumeric && !numeric {
+		violations = append(violations, "must contain a digit")
+	}
+	if r.RequireSpecial && !special {
+		violations = append(violations, "must contain a special character")
+	}
+	if r.DisallowRoleName && role != "" && strings.Contains(strings.ToLower(password), strings.ToLower(role)) {
+		violations = append(violations, "must not contain the role name")
+	}
+	return violations
+}
+
+// providerPasswordPolicyAttribute returns the schema of the provider
+// password_policy attribute.
+func providerPasswordPolicyAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "Password policy enforced at plan time on the passwords set by pgrole_password, before they reach the database.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"min_length": schema.Int64Attribute{
+				Description: "Minimum number of characters.",
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"require_lower": schema.BoolAttribute{
+				Description: "Whether passwords must contain a lowercase letter.",
+				Optional:    true,
+			},
+			"require_upper": schema.BoolAttribute{
+				Description: "Whether passwords must contain an uppercase letter.",
+				Optional:    true,
+			},
+			"require_numeric": schema.BoolAttribute{
+				Description: "Whether passwords must contain a digit.",
+				Optional:    true,
+			},
+			"require_special": schema.BoolAttribute{
+				Description: "Whether passwords must contain a character that is neither a letter nor a digit.",
+				Optional:    true,
+			},
+			"disallow_role_name": schema.BoolAttribute{
+				Description: "Whether passwords must not contain the name of the role, ignoring case.",
+				Optional:    true,
+			},
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPasswordRulesViolations(t *testing.T) {
+	rules := passwordRules{
+		MinLength:        12,
+		RequireLower:     true,
+		RequireUpper:     true,
+		RequireNumeric:   true,
+		RequireSpecial:   true,
+		DisallowRoleName: true,
+	}
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{"Correct-Horse-42", nil},
+		{"Short-1a", []string{"must be at least 12 characters long, got 8"}},
+		{"lowercase-only-42", []string{"must contain an uppercase letter"}},
+		{"UPPERCASE-ONLY-42", []string{"must contain a lowercase letter"}},
+		{"No-Digits-At-All", []string{"must contain a digit"}},
+		{"NoSpecialCharacters42", []string{"must contain a special character"}},
+		{"My-APP-Password-42", []string{"must not contain the role name"}},
+	}
+	for _, tt := range tests {
+		if got := rules.violations("app", tt.password); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("violations(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+
+	if got := (passwordRules{}).violations("app", "app"); got != nil {
+		t.Errorf("violations() without policy = %q, want none", got)
+	}
+}
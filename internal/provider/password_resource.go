@@ -0,0 +1,493 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = (*passwordResource)(nil)
+	_ resource.ResourceWithConfigure   = (*passwordResource)(nil)
+	_ resource.ResourceWithImportState = (*passwordResource)(nil)
+	_ resource.ResourceWithIdentity    = (*passwordResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*passwordResource)(nil)
+)
+
+const (
+	// scramIterations is the iteration count of SCRAM-SHA-256 verifiers,
+	// the default of PostgreSQL.
+	scramIterations = 4096
+	scramSaltLength = 16
+)
+
+// NewPasswordResource is a helper function to simplify the provider implementation.
+func NewPasswordResource() resource.Resource {
+	return &passwordResource{}
+}
+
+type passwordResource struct {
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	rules            passwordRules
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+}
+
+// Metadata returns the resource type name.
+func (r *passwordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_password"
+}
+
+// Schema defines the schema for the resource.
+func (r *passwordResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Manage the password of an existing role, without ever persisting it in the plan or state. Requires Terraform >= 1.11.
+
+The password is given through the write-only ` + "`password_wo`" + ` argument, e.g. from the ` + "`pgrole_password`" + ` ephemeral resource, and changed whenever ` + "`password_wo_version`" + ` changes, or once ` + "`rotation_days`" + ` have passed since the last change. It is checked against the ` + "`password_policy`" + ` of the provider at plan time, and sent to the database as a SCRAM-SHA-256 verifier, so that the plaintext never appears in server logs.`,
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"password_wo": schema.StringAttribute{
+				Description: "The password of the role. Write-only, it is never stored in the plan or state. Only printable ASCII characters are allowed.",
+				Required:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Validators:  []validator.String{asciiValidator{}},
+			},
+			"password_wo_version": schema.Int64Attribute{
+				Description: "Version of the password. Change it to set password_wo again, since changes of write-only arguments alone do not trigger an update.",
+				Optional:    true,
+			},
+			"rotation_days": schema.Int64Attribute{
+				Description: "Number of days after which the password is changed again by the next apply, e.g. with a new password from the pgrole_password ephemeral resource.",
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"last_rotated": schema.StringAttribute{
+				Description: "The time the password was last changed by Terraform, in RFC 3339 format.",
+				Computed:    true,
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
+		},
+	}
+}
+
+type passwordResourceModel struct {
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	PasswordWO         types.String `tfsdk:"password_wo"`
+	PasswordWOVersion  types.Int64  `tfsdk:"password_wo_version"`
+	RotationDays       types.Int64  `tfsdk:"rotation_days"`
+	LastRotated        types.String `tfsdk:"last_rotated"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *passwordResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *passwordResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.rules = data.passwordRules
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan checks the password against the password policy and previews
+// the SQL statement that the apply will run.
+func (r *passwordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role, password types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &password)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() {
+		return
+	}
+
+	if !password.IsUnknown() {
+		if violations := r.rules.violations(role.ValueString(), password.ValueString()); len(violations) > 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_wo"),
+				"Password policy violation",
+				fmt.Sprintf("The password of role %s breaks the password policy of the provider: it %s.", role.ValueString(), strings.Join(violations, ", ")),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlSetPasswordMasked(role.ValueString()))...)
+
+	// Keep last_rotated unless the password is changed, forcing a change
+	// once the rotation is due
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var plan, state passwordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || plan.PasswordWOVersion.IsUnknown() || plan.RotationDays.IsUnknown() {
+		return
+	}
+	lastRotated := state.LastRotated
+	if rotate(plan, state, time.Now()) {
+		lastRotated = types.StringUnknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_rotated"), lastRotated)...)
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *passwordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan, and the write-only password from config
+	var plan passwordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &plan.PasswordWO)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.setPassword(ctx, &resp.Diagnostics, "create", &plan) {
+		return
+	}
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Read refreshes the Terraform state with the latest data. The password
+// cannot be read back, so only the existence of the role is checked.
+func (r *passwordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state passwordResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	if _, err := readRoleConfig(ctx, db, state.Role); errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query role",
+			fmt.Sprintf("Failed to query role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *passwordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve value from plan, and the write-only password from config
+	var plan passwordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &plan.PasswordWO)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state passwordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The plan keeps last_rotated unless the password is to be changed, so
+	// that changes of the other attributes, e.g. deletion_protection, leave
+	// the password as is
+	if !plan.LastRotated.IsUnknown() {
+		plan.PasswordWO = types.StringNull()
+		plan.LastRotated = state.LastRotated
+	} else if !r.setPassword(ctx, &resp.Diagnostics, "update", &plan) {
+		return
+	}
+
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *passwordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	var state passwordResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlResetPassword(state.Role)); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
+}
+
+func (r *passwordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := readRoleConfig(ctx, db, role); errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role",
+			fmt.Sprintf("Failed to query role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetPasswordMasked(role))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// setPassword sets the password of the role of m during operation, adding
+// any error to diags, and clears the write-only password from m.
+func (r *passwordResource) setPassword(ctx context.Context, diags *diag.Diagnostics, operation string, m *passwordResourceModel) bool {
+	password := m.PasswordWO.ValueString()
+	m.PasswordWO = types.StringNull()
+	m.SQL = types.StringValue(sqlSetPasswordMasked(m.Role))
+	m.LastRotated = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+
+	verifier, err := scramSHA256Verifier(password)
+	if err != nil {
+		diags.AddError(
+			"Failed to hash password",
+			"Failed to hash password: "+err.Error(),
+		)
+		return false
+	}
+
+	db, err := r.connect(m.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(m.Retry)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return false
+	}
+	if !checkPrivileges(ctx, db, diags, m.Role, "") {
+		return false
+	}
+	if err := execMaskedWithRetry(ctx, db, policy, sqlSetPassword(m.Role, verifier), sqlSetPasswordMasked(m.Role)); err != nil {
+		addSQLError(diags, operation, m.Role, err)
+		return false
+	}
+	return true
+}
+
+// rotate returns whether the password must be changed by the update from
+// state to plan: when its version changed, or when the rotation is due at now.
+func rotate(plan, state passwordResourceModel, now time.Time) bool {
+	if !plan.PasswordWOVersion.Equal(state.PasswordWOVersion) {
+		return true
+	}
+	if plan.RotationDays.IsNull() {
+		return false
+	}
+	// Imported passwords have an unknown age
+	lastRotated, err := time.Parse(time.RFC3339, state.LastRotated.ValueString())
+	if err != nil {
+		return true
+	}
+	return now.Sub(lastRotated) >= time.Duration(plan.RotationDays.ValueInt64())*24*time.Hour
+}
+
+// scramSHA256Verifier returns the SCRAM-SHA-256 verifier of password with a
+// random salt, as stored by PostgreSQL in pg_authid.rolpassword.
+func scramSHA256Verifier(password string) (string, error) {
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return scramSHA256(password, salt, scramIterations)
+}
+
+// scramSHA256 returns the SCRAM-SHA-256 verifier of password, see RFC 5803.
+// Unlike PostgreSQL, the password is not normalized with SASLprep, which
+// leaves ASCII passwords unchanged, so password_wo only accepts those.
+func scramSHA256(password string, salt []byte, iterations int) (string, error) {
+	saltedPassword, err := pbkdf2.Key(sha256.New, password, salt, iterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	clientKey := hmacSHA256(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	serverKey := hmacSHA256(saltedPassword, "Server Key")
+
+	b64 := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("SCRAM-SHA-256$%d:%s$%s:%s", iterations, b64(salt), b64(storedKey[:]), b64(serverKey)), nil
+}
+
+func hmacSHA256(key []byte, message string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(message))
+	return h.Sum(nil)
+}
+
+func sqlSetPassword(role, verifier string) string {
+	return fmt.Sprintf("ALTER ROLE %s PASSWORD %s;", quoteIdentifier(role), pq.QuoteLiteral(verifier))
+}
+
+// sqlSetPasswordMasked returns the statement setting the password of role,
+// with the password masked, for the sql attribute and the event_audit table.
+func sqlSetPasswordMasked(role string) string {
+	return fmt.Sprintf("ALTER ROLE %s PASSWORD '********';", quoteIdentifier(role))
+}
+
+func sqlResetPassword(role string) string {
+	return fmt.Sprintf("ALTER ROLE %s PASSWORD NULL;", quoteIdentifier(role))
+}
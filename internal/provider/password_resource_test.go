@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestScramSHA256(t *testing.T) {
+	salt, _ := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	got, err := scramSHA256("pencil", salt, 4096)
+	if err != nil {
+		t.Fatalf("scramSHA256() error = %v", err)
+	}
+	want := "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU="
+	if got != want {
+		t.Errorf("scramSHA256() = %q, want %q", got, want)
+	}
+}
+
+func TestPasswordRotate(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	model := func(version, days int64, lastRotated string) passwordResourceModel {
+		m := passwordResourceModel{
+			PasswordWOVersion: types.Int64Value(version),
+			RotationDays:      types.Int64Null(),
+			LastRotated:       types.StringNull(),
+		}
+		if days > 0 {
+			m.RotationDays = types.Int64Value(days)
+		}
+		if lastRotated != "" {
+			m.LastRotated = types.StringValue(lastRotated)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name        string
+		plan, state passwordResourceModel
+		want        bool
+	}{
+		{"unchanged", model(1, 0, ""), model(1, 0, "2025-01-01T00:00:00Z"), false},
+		{"version changed", model(2, 0, ""), model(1, 0, "2026-03-31T00:00:00Z"), true},
+		{"rotation not due", model(1, 30, ""), model(1, 30, "2026-03-02T12:00:01Z"), false},
+		{"rotation due", model(1, 30, ""), model(1, 30, "2026-03-01T12:00:00Z"), true},
+		{"rotation enabled", model(1, 30, ""), model(1, 0, "2026-01-01T00:00:00Z"), true},
+		{"imported", model(1, 30, ""), model(1, 0, ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rotate(tt.plan, tt.state, now); got != tt.want {
+				t.Errorf("rotate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordResource(t *testing.T) {
+	config := func(version int, password string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "pgrole_password" "test" {
+  role                = "example_user"
+  password_wo         = %q
+  password_wo_version = %d
+}
+`, password, version)
+	}
+	// The password policy is added to the provider block of providerConfig
+	policyConfig := strings.Replace(config(1, "example_user-1"), "\n}\n", `
+  password_policy = {
+    min_length         = 12
+    disallow_role_name = true
+  }
+}
+`, 1)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The password policy is enforced at plan time
+			{
+				Config:      policyConfig,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`must not contain the role name`),
+			},
+			// Create and Read testing
+			{
+				Config: config(1, "Correct-Horse-1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("pgrole_password.test", "password_wo"),
+					resource.TestCheckResourceAttr("pgrole_password.test", "sql", `ALTER ROLE "example_user" PASSWORD '********';`),
+					resource.TestCheckResourceAttrSet("pgrole_password.test", "last_rotated"),
+				),
+			},
+			// The role can log in with the password
+			{
+				Config: config(1, "Correct-Horse-1") + strings.Replace(testAccProviderConfigAs(t, "example_user", "Correct-Horse-1"), "{", "{\n  alias    = \"example_user\"", 1) + `
+data "pgrole_roles" "test" {
+  provider = pgrole.example_user
+}
+`,
+			},
+			// Changing the version sets the password again
+			{
+				Config: config(2, "Correct-Horse-2"),
+				Check:  resource.TestCheckResourceAttr("pgrole_password.test", "password_wo_version", "2"),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_password.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+				ImportStateVerifyIgnore:              []string{"password_wo_version", "last_rotated"},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
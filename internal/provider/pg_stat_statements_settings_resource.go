@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewPgStatStatementsSettingsResource is a helper function to simplify the provider implementation.
+func NewPgStatStatementsSettingsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "pg_stat_statements_settings",
+		description: `Manage pg_stat_statements.track for an existing role, e.g. so that only the statements of selected roles are tracked.
+
+The pg_stat_statements extension must be installed in the database, which is checked before applying.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/pgstatstatements.html#PGSTATSTATEMENTS-CONFIG-PARAMS) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "track",
+				Parameter:   "pg_stat_statements.track",
+				Description: "Which statements are tracked, one of \"none\", \"top\" or \"all\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("none", "top", "all"),
+				},
+			},
+		},
+		check: requireExtension("pg_stat_statements"),
+	}
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestPgStatStatementsSettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_pg_stat_statements_settings" "test" {
+  role  = "example_user"
+  track = "all"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: testAccExecSQL(t, `DROP EXTENSION IF EXISTS pg_stat_statements;`),
+		CheckDestroy: func(*terraform.State) error {
+			testAccExecSQL(t, `DROP EXTENSION IF EXISTS pg_stat_statements;`)()
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The extension is required
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`The pg_stat_statements extension is not installed`),
+			},
+			// Create and Read testing
+			{
+				PreConfig: testAccExecSQL(t, `CREATE EXTENSION pg_stat_statements;`),
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_pg_stat_statements_settings.test", "track", "all"),
+					resource.TestCheckResourceAttr("pgrole_pg_stat_statements_settings.test", "sql", `ALTER ROLE "example_user" SET pg_stat_statements.track = 'all';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_pg_stat_statements_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET pg_stat_statements.track = 'none';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
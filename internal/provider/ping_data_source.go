@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*pingDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*pingDataSource)(nil)
+)
+
+// NewPingDataSource is a helper function to simplify the provider implementation.
+func NewPingDataSource() datasource.DataSource {
+	return &pingDataSource{}
+}
+
+type pingDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type pingDataSourceModel struct {
+	Connection  types.String `tfsdk:"connection"`
+	ConnectMs   types.Int64  `tfsdk:"connect_ms"`
+	LatencyMs   types.Int64  `tfsdk:"latency_ms"`
+	Version     types.String `tfsdk:"version"`
+	VersionNum  types.Int64  `tfsdk:"version_num"`
+	CurrentUser types.String `tfsdk:"current_user"`
+	Database    types.String `tfsdk:"database"`
+	InRecovery  types.Bool   `tfsdk:"in_recovery"`
+}
+
+// pingInfo is what the data source reads from the server.
+type pingInfo struct {
+	Latency     time.Duration
+	Version     string
+	VersionNum  int64
+	CurrentUser string
+	Database    string
+	InRecovery  bool
+}
+
+// Metadata returns the data source type name.
+func (d *pingDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ping"
+}
+
+// Schema defines the schema for the data source.
+func (d *pingDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Checks that the provider can connect to the server and run a query, and reports the latency and the identity of the server.
+
+Useful as a depends_on gate for the resources of a newly provisioned instance, so that they are only applied once the server accepts connections, and failures point at connectivity rather than at a resource.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to check, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"connect_ms": schema.Int64Attribute{
+				Description: "Time taken to connect, in milliseconds.",
+				Computed:    true,
+			},
+			"latency_ms": schema.Int64Attribute{
+				Description: "Round trip time of a query once connected, in milliseconds.",
+				Computed:    true,
+			},
+			"version": schema.StringAttribute{
+				Description: "The version of the server, e.g. \"16.4\".",
+				Computed:    true,
+			},
+			"version_num": schema.Int64Attribute{
+				Description: "The version of the server as a number, e.g. 160004, for comparisons.",
+				Computed:    true,
+			},
+			"current_user": schema.StringAttribute{
+				Description: "The role the provider is connected as.",
+				Computed:    true,
+			},
+			"database": schema.StringAttribute{
+				Description: "The database the provider is connected to.",
+				Computed:    true,
+			},
+			"in_recovery": schema.BoolAttribute{
+				Description: "Whether the server is a standby, on which roles cannot be changed.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *pingDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *pingDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data pingDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err == nil {
+		// Connections are opened lazily by the Cloud SQL connector
+		err = db.PingContext(ctx)
+		defer db.Close()
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	connect := time.Since(start)
+
+	info, err := readPing(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query server",
+			"Failed to query server: "+err.Error(),
+		)
+		return
+	}
+
+	data.ConnectMs = types.Int64Value(connect.Milliseconds())
+	data.LatencyMs = types.Int64Value(info.Latency.Milliseconds())
+	data.Version = types.StringValue(info.Version)
+	data.VersionNum = types.Int64Value(info.VersionNum)
+	data.CurrentUser = types.StringValue(info.CurrentUser)
+	data.Database = types.StringValue(info.Database)
+	data.InRecovery = types.BoolValue(info.InRecovery)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+const sqlPing = `
+SELECT
+	current_setting('server_version'),
+	current_setting('server_version_num')::int,
+	current_user,
+	current_database(),
+	pg_is_in_recovery();`
+
+// readPing returns the identity of the server db is connected to, and the
+// round trip time of the query reading it.
+func readPing(ctx context.Context, db *sql.DB) (pingInfo, error) {
+	var info pingInfo
+	start := time.Now()
+	err := db.QueryRowContext(ctx, sqlPing).Scan(&info.Version, &info.VersionNum, &info.CurrentUser, &info.Database, &info.InRecovery)
+	info.Latency = time.Since(start)
+	return info, err
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadPing(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`pg_is_in_recovery`,
+		[]string{"server_version", "server_version_num", "current_user", "current_database", "pg_is_in_recovery"},
+		[]driver.Value{"16.4", int64(160004), "postgres", "app", false},
+	)
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	got, err := readPing(ctx, db)
+	if err != nil {
+		t.Fatalf("readPing() error = %v", err)
+	}
+	got.Latency = 0
+	want := pingInfo{Version: "16.4", VersionNum: 160004, CurrentUser: "postgres", Database: "app"}
+	if got != want {
+		t.Errorf("readPing() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPingDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "pgrole_ping" "test" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pgrole_ping.test", "in_recovery", "false"),
+					resource.TestCheckResourceAttrSet("data.pgrole_ping.test", "latency_ms"),
+					resource.TestCheckResourceAttrSet("data.pgrole_ping.test", "connect_ms"),
+					resource.TestCheckResourceAttrSet("data.pgrole_ping.test", "version"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Keys of the private state of resources, which keeps metadata out of the
+// schema: Terraform stores it along with the state, but never shows it.
+const (
+	// privateOriginalKey holds the value the role had before an adopting
+	// resource was created.
+	privateOriginalKey = "original"
+	// privateRestoreValueKey holds the restore_value of a resource when it
+	// was created.
+	privateRestoreValueKey = "restore_value"
+	// privateSQLHashKey holds the SHA-256 of the statements run by the last
+	// apply.
+	privateSQLHashKey = "sql_sha256"
+	// privateServerVersionKey holds the server_version_num of the server the
+	// resource was created on.
+	privateServerVersionKey = "server_version_num"
+)
+
+// privateState is the private state of a resource, e.g. the Private field of
+// resource.CreateResponse.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// setPrivate stores value as JSON under key in private.
+func setPrivate(ctx context.Context, diags *diag.Diagnostics, private privateState, key string, value any) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		diags.AddError("Failed to set private state", fmt.Sprintf("Failed to encode %s: %s", key, err))
+		return false
+	}
+	setDiags := private.SetKey(ctx, key, data)
+	diags.Append(setDiags...)
+	return !setDiags.HasError()
+}
+
+// getRestoreValue reads the restore_value captured on create into value, and
+// returns false if there is none. An invalid value is reported as a warning
+// on diags, for the resource to fall back to the value in its state.
+func getRestoreValue(ctx context.Context, diags *diag.Diagnostics, private privateState, value any) bool {
+	ok, err := getPrivate(ctx, private, privateRestoreValueKey, value)
+	if err != nil {
+		diags.AddWarning(
+			"Failed to read the restore value",
+			fmt.Sprintf("The restore_value recorded in the private state is invalid, the value in the state is used instead: %s", err),
+		)
+	}
+	return ok
+}
+
+// getPrivate reads the JSON value under key in private into value, and
+// returns false if there is none or it is invalid.
+func getPrivate(ctx context.Context, private privateState, key string, value any) (bool, error) {
+	if private == nil {
+		return false, nil
+	}
+	data, diags := private.GetKey(ctx, key)
+	if diags.HasError() {
+		return false, fmt.Errorf("failed to get %s: %v", key, diags)
+	}
+	if len(data) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// sqlHash returns the hex SHA-256 of sqlstr.
+func sqlHash(sqlstr string) string {
+	sum := sha256.Sum256([]byte(sqlstr))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordApplied records the hash of sqlstr, just applied, in private, along
+// with the version of the server on the first apply. The version is only
+// logged if it cannot be read, since it is informational.
+func recordApplied(ctx context.Context, diags *diag.Diagnostics, private privateState, db *sql.DB, sqlstr string) {
+	if !setPrivate(ctx, diags, private, privateSQLHashKey, sqlHash(sqlstr)) {
+		return
+	}
+
+	var version int
+	if ok, _ := getPrivate(ctx, private, privateServerVersionKey, &version); ok {
+		return
+	}
+	if err := db.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int;").Scan(&version); err != nil {
+		tflog.Warn(ctx, "Failed to query server version", map[string]any{"error": err.Error()})
+		return
+	}
+	setPrivate(ctx, diags, private, privateServerVersionKey, version)
+}
+
+// logApplied logs the metadata recorded by recordApplied, for the refresh of
+// role.
+func logApplied(ctx context.Context, private privateState, role, sqlstr string) {
+	var hash string
+	var version int
+	hashOK, _ := getPrivate(ctx, private, privateSQLHashKey, &hash)
+	versionOK, _ := getPrivate(ctx, private, privateServerVersionKey, &version)
+	if !hashOK && !versionOK {
+		return
+	}
+	tflog.Debug(ctx, "Read private state", map[string]any{
+		"role":                       role,
+		"sql_matches_last_apply":     hashOK && hash == sqlHash(sqlstr),
+		"created_server_version_num": version,
+	})
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestRecordApplied(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`server_version_num`, []string{"current_setting"}, []driver.Value{int64(160004)})
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	private := testPrivateState{}
+	var diags diag.Diagnostics
+	recordApplied(ctx, &diags, private, db, sqlSetLogin("app", true))
+	if diags.HasError() {
+		t.Fatalf("recordApplied() error = %v", diags)
+	}
+
+	if got := string(private[privateServerVersionKey]); got != "160004" {
+		t.Errorf("server_version_num = %s, want 160004", got)
+	}
+
+	// The version of the server at creation is kept by later applies
+	private[privateServerVersionKey] = []byte("150007")
+	recordApplied(ctx, &diags, private, db, sqlSetLogin("app", false))
+
+	var hash string
+	var version int
+	if ok, err := getPrivate(ctx, private, privateSQLHashKey, &hash); !ok || err != nil || hash != sqlHash(sqlSetLogin("app", false)) {
+		t.Errorf("sql_sha256 = %q, %v, want the hash of the last statement", hash, err)
+	}
+	if ok, err := getPrivate(ctx, private, privateServerVersionKey, &version); !ok || err != nil || version != 150007 {
+		t.Errorf("server_version_num = %d, %v, want 150007", version, err)
+	}
+}
+
+func TestGetPrivateMissing(t *testing.T) {
+	var version int
+	if ok, err := getPrivate(context.Background(), nil, privateServerVersionKey, &version); ok || err != nil {
+		t.Errorf("getPrivate() without private state = %t, %v, want false", ok, err)
+	}
+}
+
+func TestGetRestoreValue(t *testing.T) {
+	ctx := context.Background()
+	var diags diag.Diagnostics
+	private := testPrivateState{}
+	var limit int64
+	if getRestoreValue(ctx, &diags, private, &limit) {
+		t.Error("getRestoreValue() without a captured value = true, want false")
+	}
+
+	if !setPrivate(ctx, &diags, private, privateRestoreValueKey, int64(50)) {
+		t.Fatalf("setPrivate() error = %v", diags)
+	}
+	if !getRestoreValue(ctx, &diags, private, &limit) || limit != 50 {
+		t.Errorf("getRestoreValue() = %d, want 50", limit)
+	}
+
+	private[privateRestoreValueKey] = []byte(`"30s"`)
+	if getRestoreValue(ctx, &diags, private, &limit) {
+		t.Error("getRestoreValue() with an invalid value = true, want false")
+	}
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("getRestoreValue() diagnostics = %v, want a single warning", diags)
+	}
+}
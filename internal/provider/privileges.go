@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// connectingRolePrivileges describes what the role used by the provider is
+// allowed to do to a target role.
+type connectingRolePrivileges struct {
+	Name              string
+	Superuser         bool
+	CreateRole        bool
+	BypassRLS         bool
+	Replication       bool
+	CloudSQLSuperuser bool
+	AdminOnTarget     bool
+	TargetSuperuser   bool
+	ServerVersionNum  int
+}
+
+const sqlConnectingRolePrivileges = `SELECT
+	r.rolname,
+	r.rolsuper,
+	r.rolcreaterole,
+	r.rolbypassrls,
+	r.rolreplication,
+	CASE WHEN EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'cloudsqlsuperuser')
+		THEN pg_has_role(r.oid, 'cloudsqlsuperuser', 'MEMBER')
+		ELSE false
+	END,
+	pg_has_role(r.oid, t.oid, 'MEMBER WITH ADMIN OPTION'),
+	t.rolsuper,
+	current_setting('server_version_num')::int
+FROM pg_roles r, pg_roles t
+WHERE r.rolname = current_user AND t.rolname = $1;`
+
+// readConnectingRolePrivileges returns the privileges of the connecting role
+// over target, or sql.ErrNoRows if target does not exist.
+func readConnectingRolePrivileges(ctx context.Context, db *sql.DB, target string) (connectingRolePrivileges, error) {
+	var p connectingRolePrivileges
+	err := db.QueryRowContext(ctx, sqlConnectingRolePrivileges, target).Scan(
+		&p.Name,
+		&p.Superuser,
+		&p.CreateRole,
+		&p.BypassRLS,
+		&p.Replication,
+		&p.CloudSQLSuperuser,
+		&p.AdminOnTarget,
+		&p.TargetSuperuser,
+		&p.ServerVersionNum,
+	)
+	return p, err
+}
+
+// missingPrivilege explains which membership or attribute the connecting role
+// lacks to run ALTER ROLE on target, optionally changing attribute (e.g.
+// "BYPASSRLS"), or returns an empty string if it has enough privileges.
+func (p connectingRolePrivileges) missingPrivilege(target, attribute string) string {
+	if p.Superuser {
+		return ""
+	}
+	if p.TargetSuperuser {
+		return fmt.Sprintf("Role %s is a superuser and can only be altered by a superuser, but the connecting role %s is not a superuser.", target, p.Name)
+	}
+	if p.CloudSQLSuperuser {
+		// Cloud SQL grants cloudsqlsuperuser the privileges to manage
+		// non-superuser roles through its own extensions.
+		return ""
+	}
+	if !p.CreateRole {
+		return fmt.Sprintf("The connecting role %s needs to be a superuser, a member of cloudsqlsuperuser on Cloud SQL, or have the CREATEROLE attribute to alter role %s.", p.Name, target)
+	}
+
+	pg16 := p.ServerVersionNum >= 160000
+	if pg16 && !p.AdminOnTarget {
+		return fmt.Sprintf("Since PostgreSQL 16 the connecting role %s needs ADMIN OPTION on role %s, e.g.: GRANT %s TO %s WITH ADMIN OPTION;", p.Name, target, quoteIdentifier(target), quoteIdentifier(p.Name))
+	}
+
+	var hasAttribute bool
+	switch attribute {
+	case "BYPASSRLS":
+		hasAttribute = p.BypassRLS
+	case "REPLICATION":
+		hasAttribute = p.Replication
+	default:
+		return ""
+	}
+	if !pg16 {
+		return fmt.Sprintf("Before PostgreSQL 16 only superusers can change the %s attribute, but the connecting role %s is not a superuser.", attribute, p.Name)
+	}
+	if !hasAttribute {
+		return fmt.Sprintf("The connecting role %s needs the %s attribute itself to change it on role %s.", p.Name, attribute, target)
+	}
+	return ""
+}
+
+// checkPrivileges verifies that the connecting role can run ALTER ROLE on
+// role, optionally changing attribute, and adds an error to diags explaining
+// what is missing otherwise. It warns when role is the connecting role itself.
+func checkPrivileges(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, role, attribute string) bool {
+	privileges, err := readConnectingRolePrivileges(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		diags.AddError(
+			"Role not found",
+			fmt.Sprintf("Role %s does not exist.", role),
+		)
+		return false
+	}
+	if err != nil {
+		diags.AddError(
+			"Failed to query privileges",
+			fmt.Sprintf("Failed to query privileges of the connecting role over role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return false
+	}
+	if msg := privileges.missingPrivilege(role, attribute); msg != "" {
+		diags.AddError("Insufficient privileges", msg)
+		return false
+	}
+	if privileges.Name == role {
+		diags.AddWarning(
+			"Managing the connecting role",
+			fmt.Sprintf("Role %s is the role the provider connects as. Changes such as a connection limit of 0, disabling LOGIN or a very low statement_timeout can lock the provider out in the middle of the apply or in later runs. Manage it through a connection as another role instead.", role),
+		)
+	}
+	return true
+}
+
+// sqlParameterRequiresSuperuser reports whether $1 is a parameter only
+// superusers can set that the connecting role may not set, along with the
+// name of the connecting role. Before PostgreSQL 15 there is no way to grant
+// setting such parameters.
+const (
+	sqlParameterRequiresSuperuser = `
+SELECT s.context = 'superuser' AND NOT r.rolsuper, r.rolname
+FROM pg_settings s, pg_roles r
+WHERE lower(s.name) = lower($1) AND r.rolname = current_user;`
+
+	sqlParameterRequiresSuperuserPG15 = `
+SELECT s.context = 'superuser' AND NOT r.rolsuper AND NOT has_parameter_privilege($1, 'SET'), r.rolname
+FROM pg_settings s, pg_roles r
+WHERE lower(s.name) = lower($1) AND r.rolname = current_user;`
+)
+
+// checkParameterPrivilege verifies that the connecting role can set
+// parameter for other roles, and adds an error to diags on attribute
+// explaining what is missing otherwise. Parameters unknown to the server, e.g.
+// of extensions that are not loaded, are not checked.
+func checkParameterPrivilege(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, attribute, parameter string) bool {
+	var version int
+	if err := db.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int;").Scan(&version); err != nil {
+		diags.AddError(
+			"Failed to query server version",
+			"Failed to query server version: "+err.Error(),
+		)
+		return false
+	}
+	query := sqlParameterRequiresSuperuser
+	if version >= 150000 {
+		query = sqlParameterRequiresSuperuserPG15
+	}
+
+	var denied bool
+	var name string
+	err := db.QueryRowContext(ctx, query, parameter).Scan(&denied, &name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		diags.AddError(
+			"Failed to query privileges",
+			fmt.Sprintf("Failed to query privileges of the connecting role over parameter %s: %s", parameter, err),
+		)
+		return false
+	}
+	if !denied {
+		return true
+	}
+
+	msg := fmt.Sprintf("Parameter %s can only be set by superusers on this server, and the connecting role %s is not one.", parameter, name)
+	if version >= 150000 {
+		msg += fmt.Sprintf(" Connect as a superuser, or grant the privilege to set it, e.g.: GRANT SET ON PARAMETER %s TO %s;", parameter, quoteIdentifier(name))
+	} else {
+		msg += " Connect as a superuser to manage it."
+	}
+	diags.AddAttributeError(path.Root(attribute), "Insufficient privileges", msg)
+	return false
+}
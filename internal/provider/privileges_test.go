@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestMissingPrivilege(t *testing.T) {
+	tests := []struct {
+		name       string
+		privileges connectingRolePrivileges
+		attribute  string
+		want       string
+	}{
+		{
+			name:       "superuser",
+			privileges: connectingRolePrivileges{Name: "admin", Superuser: true, TargetSuperuser: true},
+			attribute:  "BYPASSRLS",
+		},
+		{
+			name:       "superuser target",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, TargetSuperuser: true},
+			want:       "can only be altered by a superuser",
+		},
+		{
+			name:       "cloudsqlsuperuser",
+			privileges: connectingRolePrivileges{Name: "postgres", CloudSQLSuperuser: true, ServerVersionNum: 160000},
+			attribute:  "REPLICATION",
+		},
+		{
+			name:       "no createrole",
+			privileges: connectingRolePrivileges{Name: "admin", ServerVersionNum: 150000},
+			want:       "CREATEROLE",
+		},
+		{
+			name:       "createrole before 16",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, ServerVersionNum: 150000},
+		},
+		{
+			name:       "bypassrls before 16",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, ServerVersionNum: 150000},
+			attribute:  "BYPASSRLS",
+			want:       "only superusers can change the BYPASSRLS attribute",
+		},
+		{
+			name:       "no admin option since 16",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, ServerVersionNum: 160002},
+			want:       `GRANT "app" TO "admin" WITH ADMIN OPTION;`,
+		},
+		{
+			name:       "replication without attribute since 16",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, AdminOnTarget: true, ServerVersionNum: 170000},
+			attribute:  "REPLICATION",
+			want:       "needs the REPLICATION attribute itself",
+		},
+		{
+			name:       "replication with attribute since 16",
+			privileges: connectingRolePrivileges{Name: "admin", CreateRole: true, Replication: true, AdminOnTarget: true, ServerVersionNum: 170000},
+			attribute:  "REPLICATION",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.privileges.missingPrivilege("app", tt.attribute)
+			if tt.want == "" && got != "" {
+				t.Errorf("missingPrivilege() = %q, want none", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("missingPrivilege() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckParameterPrivilege(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int64
+		rows    [][]driver.Value
+		want    string
+	}{
+		{
+			name:    "allowed",
+			version: 160000,
+			rows:    [][]driver.Value{{false, "track_admin"}},
+		},
+		{
+			name:    "unknown parameter",
+			version: 160000,
+		},
+		{
+			name:    "denied before 15",
+			version: 140000,
+			rows:    [][]driver.Value{{true, "track_admin"}},
+			want:    "Connect as a superuser to manage it.",
+		},
+		{
+			name:    "denied since 15",
+			version: 150000,
+			rows:    [][]driver.Value{{true, "track_admin"}},
+			want:    `GRANT SET ON PARAMETER track_io_timing TO "track_admin";`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := fakedb.New().
+				ExpectQuery(`server_version_num`, []string{"current_setting"}, []driver.Value{tt.version}).
+				ExpectQuery(`FROM pg_settings s, pg_roles r`, []string{"denied", "rolname"}, tt.rows...)
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			var diags diag.Diagnostics
+			got := checkParameterPrivilege(ctx, db, &diags, "track_io_timing", "track_io_timing")
+			if got != (tt.want == "") {
+				t.Fatalf("checkParameterPrivilege() = %t with %v", got, diags)
+			}
+			if tt.want != "" && !strings.Contains(diags[0].Detail(), tt.want) {
+				t.Errorf("checkParameterPrivilege() error = %q, want it to contain %q", diags[0].Detail(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPrivilegesConnectingRole(t *testing.T) {
+	columns := []string{"rolname", "rolsuper", "rolcreaterole", "rolbypassrls", "rolreplication", "cloudsqlsuperuser", "admin", "target_super", "server_version_num"}
+	for _, connecting := range []string{"app", "admin"} {
+		t.Run(connecting, func(t *testing.T) {
+			ctx := context.Background()
+			fake := fakedb.New().ExpectQuery(`FROM pg_roles r, pg_roles t`, columns,
+				[]driver.Value{connecting, true, false, false, false, false, true, false, int64(160000)},
+			)
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			var diags diag.Diagnostics
+			if !checkPrivileges(ctx, db, &diags, "app", "") {
+				t.Fatalf("checkPrivileges() = false with %v", diags)
+			}
+			if want := connecting == "app"; (diags.WarningsCount() == 1) != want {
+				t.Errorf("checkPrivileges() warnings = %v, want a warning: %t", diags, want)
+			}
+		})
+	}
+}
+
+func TestPrivilegesAdminOptionRequiredSincePG16(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccSkipBelowVersion(t, 16)()
+			testAccExecSQL(t, `CREATE ROLE "limited_admin" LOGIN CREATEROLE PASSWORD 'limited_admin';`)()
+		},
+		CheckDestroy: func(*terraform.State) error {
+			testAccExecSQL(t, `DROP ROLE "limited_admin";`)()
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfigAs(t, "limited_admin", "limited_admin") + `
+resource "pgrole_connection_limit" "test" {
+  role             = "example_user"
+  connection_limit = 5
+}
+`,
+				ExpectError: regexp.MustCompile(`needs ADMIN OPTION on role example_user`),
+			},
+		},
+	})
+}
+
+// testAccProviderConfigAs returns the provider configuration connecting to
+// the acceptance test database as another role.
+func testAccProviderConfigAs(t *testing.T, username, password string) string {
+	u, err := url.Parse(testDSN)
+	if err != nil {
+		t.Fatalf("Failed to parse DSN: %s", err)
+	}
+	return fmt.Sprintf(`
+provider "pgrole" {
+  host     = %q
+  port     = %s
+  database = %q
+  username = %q
+  password = %q
+  sslmode  = "disable"
+}
+`, u.Hostname(), u.Port(), strings.TrimPrefix(u.Path, "/"), username, password)
+}
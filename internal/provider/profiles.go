@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// profileModel describes a profile of the provider configuration: role
+// attributes and configuration parameters stamped together onto roles by
+// pgrole_role_template. Unset attributes are left alone.
+type profileModel struct {
+	ConnectionLimit types.Int64 `tfsdk:"connection_limit"`
+	Login           types.Bool  `tfsdk:"login"`
+	Replication     types.Bool  `tfsdk:"replication"`
+	BypassRLS       types.Bool  `tfsdk:"bypassrls"`
+	Settings        types.Map   `tfsdk:"settings"`
+}
+
+// providerProfilesAttribute returns the schema of the profiles provider
+// attribute.
+func providerProfilesAttribute() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		Description: "Named profiles of role attributes and configuration parameters, e.g. \"etl\", \"readonly\" or \"app\", applied to roles by pgrole_role_template. Defines golden role configurations once for many roles. Profiles are validated when the provider is configured: each must set at least one attribute or parameter.",
+		Optional:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"connection_limit": schema.Int64Attribute{
+					Description: "The CONNECTION LIMIT of the roles, -1 for no limit.",
+					Optional:    true,
+					Validators:  connectionLimitValidators(),
+				},
+				"login": schema.BoolAttribute{
+					Description: "Whether the roles can LOGIN.",
+					Optional:    true,
+				},
+				"replication": schema.BoolAttribute{
+					Description: "Whether the roles have the REPLICATION attribute.",
+					Optional:    true,
+				},
+				"bypassrls": schema.BoolAttribute{
+					Description: "Whether the roles have the BYPASSRLS attribute.",
+					Optional:    true,
+				},
+				"settings": schema.MapAttribute{
+					Description: "Map of configuration parameter name to value set on the roles, e.g. { statement_timeout = \"5min\" }. Names must be lowercase.",
+					ElementType: types.StringType,
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+// validate checks the profile configured at p, which must be fully known,
+// set something, and only valid values.
+func (m profileModel) validate(p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	values := map[string]attr.Value{
+		"connection_limit": m.ConnectionLimit,
+		"login":            m.Login,
+		"replication":      m.Replication,
+		"bypassrls":        m.BypassRLS,
+		"settings":         m.Settings,
+	}
+	set := 0
+	for name, value := range values {
+		if value.IsUnknown() {
+			diags.AddAttributeError(p.AtName(name), "unknown "+name, "unknown "+name)
+		}
+		if !value.IsNull() {
+			set++
+		}
+	}
+	for name, value := range m.Settings.Elements() {
+		if value.IsUnknown() {
+			diags.AddAttributeError(p.AtName("settings").AtMapKey(name), "unknown setting", fmt.Sprintf("unknown value of setting %s", name))
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if set == 0 {
+		diags.AddAttributeError(p, "Invalid profile", "The profile must set at least one of connection_limit, login, replication, bypassrls or settings.")
+	}
+	if v := m.ConnectionLimit.ValueInt64(); v < -1 || v > math.MaxInt32 {
+		diags.AddAttributeError(p.AtName("connection_limit"), "Invalid profile", fmt.Sprintf("connection_limit must be -1 for no limit or a positive number up to 2147483647, got %d.", v))
+	}
+	for _, name := range sortedKeys(m.settings()) {
+		if !parameterNameRe.MatchString(name) {
+			diags.AddAttributeError(p.AtName("settings").AtMapKey(name), "Invalid profile", fmt.Sprintf("%q is not a lowercase parameter name.", name))
+		}
+	}
+	return diags
+}
+
+// settings returns the configuration parameters of the profile, by name.
+func (m profileModel) settings() map[string]string {
+	settings := make(map[string]string, len(m.Settings.Elements()))
+	for name, value := range m.Settings.Elements() {
+		settings[name] = value.(types.String).ValueString()
+	}
+	return settings
+}
+
+// privilegedAttributes returns the role attributes set by the profile that
+// require the connecting role to have them itself, for checkPrivileges.
+func (m profileModel) privilegedAttributes() []string {
+	attributes := []string{""}
+	if !m.Replication.IsNull() {
+		attributes = append(attributes, "REPLICATION")
+	}
+	if !m.BypassRLS.IsNull() {
+		attributes = append(attributes, "BYPASSRLS")
+	}
+	return attributes
+}
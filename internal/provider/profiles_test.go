@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile profileModel
+		want    string
+	}{
+		{
+			name:    "attributes",
+			profile: profileModel{ConnectionLimit: types.Int64Value(-1), Login: types.BoolValue(true)},
+		},
+		{
+			name:    "settings",
+			profile: profileModel{Settings: testStringMap(map[string]string{"statement_timeout": "5min", "pgaudit.log": "ddl"})},
+		},
+		{
+			name:    "empty",
+			profile: profileModel{},
+			want:    "must set at least one of",
+		},
+		{
+			name:    "invalid connection limit",
+			profile: profileModel{ConnectionLimit: types.Int64Value(-2)},
+			want:    "connection_limit must be -1",
+		},
+		{
+			name:    "connection limit over int4",
+			profile: profileModel{ConnectionLimit: types.Int64Value(1 << 31)},
+			want:    "up to 2147483647",
+		},
+		{
+			name:    "invalid parameter name",
+			profile: profileModel{Settings: testStringMap(map[string]string{"Work_Mem": "64MB"})},
+			want:    "not a lowercase parameter name",
+		},
+		{
+			name:    "unknown attribute",
+			profile: profileModel{Login: types.BoolUnknown()},
+			want:    "unknown login",
+		},
+		{
+			name: "unknown setting",
+			profile: profileModel{Settings: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"work_mem": types.StringUnknown(),
+			})},
+			want: "unknown value of setting work_mem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.profile.validate(path.Root("profiles").AtMapKey("etl"))
+			if tt.want == "" {
+				if diags.HasError() {
+					t.Errorf("validate() error = %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || !strings.Contains(diags[0].Detail(), tt.want) {
+				t.Errorf("validate() = %v, want an error containing %q", diags, tt.want)
+			}
+		})
+	}
+}
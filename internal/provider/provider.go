@@ -2,20 +2,38 @@ package provider
 
 import (
 	"context"
-	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
-	_ provider.Provider              = &pgroleProvider{}
-	_ provider.ProviderWithFunctions = &pgroleProvider{}
+	_ provider.Provider                       = &pgroleProvider{}
+	_ provider.ProviderWithFunctions          = &pgroleProvider{}
+	_ provider.ProviderWithConfigValidators   = &pgroleProvider{}
+	_ provider.ProviderWithEphemeralResources = &pgroleProvider{}
+)
+
+var (
+	// cloudSQLAttributes are the provider attributes only used by Cloud SQL
+	// connections.
+	cloudSQLAttributes = []string{"project_id", "region", "instance", "cert_fetch_timeout"}
+
+	// standardAttributes are the provider attributes only used by standard
+	// PostgreSQL connections.
+	standardAttributes = []string{"host", "port", "password", "sslmode", "expected_server_name", "min_tls_version", "tls_cipher_suites", "iam_authentication", "password_command", "password_keyring"}
 )
 
 // pgroleProvider defines the provider implementation.
@@ -26,21 +44,104 @@ type pgroleProvider struct {
 	version string
 }
 
-// pgroleModel describes the provider data model.
-type pgroleModel struct {
+// connectionModel describes the connection settings of the provider, and of
+// each of its named connections.
+type connectionModel struct {
 	// Cloud SQL connection parameters
 	ProjectID                 types.String `tfsdk:"project_id"`
 	Region                    types.String `tfsdk:"region"`
 	Instance                  types.String `tfsdk:"instance"`
+	CertFetchTimeout          types.String `tfsdk:"cert_fetch_timeout"`
 	Database                  types.String `tfsdk:"database"`
 	Username                  types.String `tfsdk:"username"`
 	ImpersonateServiceAccount types.String `tfsdk:"impersonate_service_account"`
+	ImpersonationScopes       types.List   `tfsdk:"impersonation_scopes"`
+	QuotaProject              types.String `tfsdk:"quota_project"`
+	UniverseDomain            types.String `tfsdk:"universe_domain"`
+	SQLAdminEndpoint          types.String `tfsdk:"sqladmin_endpoint"`
+	IAMCredentialsEndpoint    types.String `tfsdk:"iam_credentials_endpoint"`
 
 	// Standard PostgreSQL connection parameters
-	Host     types.String `tfsdk:"host"`
-	Port     types.Int64  `tfsdk:"port"`
-	Password types.String `tfsdk:"password"`
-	SSLMode  types.String `tfsdk:"sslmode"`
+	Host               types.String `tfsdk:"host"`
+	Port               types.Int64  `tfsdk:"port"`
+	Password           types.String `tfsdk:"password"`
+	SSLMode            types.String `tfsdk:"sslmode"`
+	ExpectedServerName types.String `tfsdk:"expected_server_name"`
+	MinTLSVersion      types.String `tfsdk:"min_tls_version"`
+	TLSCipherSuites    types.List   `tfsdk:"tls_cipher_suites"`
+	IAMAuthentication  types.Bool   `tfsdk:"iam_authentication"`
+	PasswordCommand    types.List   `tfsdk:"password_command"`
+	PasswordKeyring    types.String `tfsdk:"password_keyring"`
+}
+
+// pgroleModel describes the provider data model.
+type pgroleModel struct {
+	connectionModel
+
+	Connections        map[string]connectionModel `tfsdk:"connections"`
+	Retry              *retryModel                `tfsdk:"retry"`
+	PasswordPolicy     *passwordPolicyModel       `tfsdk:"password_policy"`
+	VerifyWrites       types.Bool                 `tfsdk:"verify_writes"`
+	Profiles           map[string]profileModel    `tfsdk:"profiles"`
+	AllowSystemRoles   types.Bool                 `tfsdk:"allow_system_roles"`
+	AllowedParameters  []string                   `tfsdk:"allowed_parameters"`
+	DeniedParameters   []string                   `tfsdk:"denied_parameters"`
+	RoleNamePattern    types.String               `tfsdk:"role_name_pattern"`
+	Telemetry          *telemetryModel            `tfsdk:"telemetry"`
+	EventAudit         *eventAuditModel           `tfsdk:"event_audit"`
+	CompatibilityCheck *compatibilityCheckModel   `tfsdk:"compatibility_check"`
+	WaitForDatabase    types.String               `tfsdk:"wait_for_database"`
+}
+
+// providerData is passed by Configure to resources and data sources.
+type providerData struct {
+	db    DBGetter
+	retry retryPolicy
+	// connect returns the getter of connections to a database of the named
+	// connection, for resources overriding the provider connection or
+	// database. Empty names stand for the provider connection and for the
+	// database of the connection.
+	connect func(connection, database string) DBGetter
+	// connectAs is like connect, but connects as role, authenticated with
+	// password, for pgrole_password_auth_check.
+	connectAs func(connection, database, role, password string) DBGetter
+
+	// passwordRules are enforced on the passwords set by pgrole_password.
+	passwordRules passwordRules
+	// verifyWrites makes resources read back the role after each apply.
+	verifyWrites bool
+	// profiles are the profiles applied by pgrole_role_template, by name.
+	profiles map[string]profileModel
+	// allowSystemRoles lets resources manage reserved roles, see
+	// isSystemRole.
+	allowSystemRoles bool
+	// roleNamePattern matches the names of the roles resources may manage,
+	// if not nil.
+	roleNamePattern *regexp.Regexp
+	// parameterGuard constrains the configuration parameters resources may
+	// set on roles.
+	parameterGuard parameterGuard
+
+	// dsn is the connection string of the database, including the password
+	// of standard PostgreSQL connections.
+	dsn string
+	// impersonateServiceAccount is the service account impersonated for
+	// Cloud SQL connections, if any.
+	impersonateServiceAccount string
+	// impersonationScopes are the OAuth2 scopes of the tokens of the
+	// impersonated service account.
+	impersonationScopes []string
+	// gcp are the settings of the Google Cloud clients of the connection.
+	gcp gcpOptions
+}
+
+// dbFor returns the getter of connections to database on the named
+// connection, or to the provider connection and database if empty.
+func (d *providerData) dbFor(connection, database string) DBGetter {
+	if connection == "" && database == "" {
+		return d.db
+	}
+	return d.connect(connection, database)
 }
 
 func (p *pgroleProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -49,64 +150,223 @@ func (p *pgroleProvider) Metadata(ctx context.Context, req provider.MetadataRequ
 }
 
 func (p *pgroleProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes := connectionAttributes()
+	attributes["connections"] = schema.MapNestedAttribute{
+		Description: "Additional named connections, e.g. to the other instances of a small fleet, with the same settings as the provider block. Resources use one of them by setting their connection attribute to its name, and the connection of the provider block otherwise.",
+		Optional:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: connectionAttributes(),
+		},
+	}
+	attributes["retry"] = providerRetryAttribute()
+	attributes["password_policy"] = providerPasswordPolicyAttribute()
+	attributes["profiles"] = providerProfilesAttribute()
+	attributes["verify_writes"] = schema.BoolAttribute{
+		Description: "Whether resources read the role back from the catalog after each apply, and fail with a discrepancy report when the changes did not take effect, e.g. because a managed service silently ignored them. Defaults to false.",
+		Optional:    true,
+	}
+	attributes["telemetry"] = providerTelemetryAttribute()
+	attributes["event_audit"] = providerEventAuditAttribute()
+	attributes["compatibility_check"] = providerCompatibilityCheckAttribute()
+	attributes["wait_for_database"] = schema.StringAttribute{
+		Description: "How long to keep retrying to connect while the server does not accept connections, e.g. \"5m\" for Cloud SQL instances created in the same apply. Applies to every connection, including the named ones. Defaults to no retry.",
+		Optional:    true,
+		Validators:  []validator.String{durationValidator{}},
+	}
+	attributes["allow_system_roles"] = schema.BoolAttribute{
+		Description: "Whether resources may manage reserved roles: postgres, the administration roles of managed services (cloudsqladmin, rdsadmin, azure_pg_admin) and the predefined pg_* roles. Plans targeting them fail otherwise, since changing them can break the server or its managed service. Defaults to false.",
+		Optional:    true,
+	}
+	attributes["allowed_parameters"] = schema.ListAttribute{
+		Description: "The only configuration parameters resources may set on roles, as names or shell patterns, e.g. [\"statement_timeout\", \"work_mem\", \"citus.*\"], so that platform teams can delegate the provider to application teams. Names are matched case-insensitively, like PostgreSQL does. Applies to pgrole_config_map, to the resources of dedicated parameters, e.g. pgrole_statement_timeout or pgrole_audit, and to the settings of profiles. Plans setting other parameters fail. Defaults to every parameter.",
+		ElementType: types.StringType,
+		Optional:    true,
+		Validators: []validator.List{
+			listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+	}
+	attributes["denied_parameters"] = schema.ListAttribute{
+		Description: "Configuration parameters resources may not set on roles, as names or shell patterns, e.g. [\"session_replication_role\", \"pgaudit.*\"], even if matched by allowed_parameters. Applies to the same resources as allowed_parameters.",
+		ElementType: types.StringType,
+		Optional:    true,
+		Validators: []validator.List{
+			listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+		},
+	}
+	attributes["role_name_pattern"] = schema.StringAttribute{
+		Description: "Regular expression that the role of every resource must match as a whole, e.g. \"svc_[a-z0-9_]+\", so that platform teams can enforce naming conventions. Plans targeting other roles fail. Defaults to any role.",
+		Optional:    true,
+		Validators:  []validator.String{regexValidator{}},
+	}
 	resp.Schema = schema.Schema{
 		Description: "A provider for managing roles' attributes inside a PostgreSQL instance (Cloud SQL or standard).",
-		Attributes: map[string]schema.Attribute{
-			// Cloud SQL specific parameters
-			"project_id": schema.StringAttribute{
-				Description: "The Google Cloud project ID of the Cloud SQL instance. Required if using Cloud SQL.",
-				Optional:    true,
-			},
-			"region": schema.StringAttribute{
-				Description: "The region of the Cloud SQL instance. Required if using Cloud SQL.",
-				Optional:    true,
-			},
-			"instance": schema.StringAttribute{
-				Description: "The name of the Cloud SQL instance. Required if using Cloud SQL.",
-				Optional:    true,
-			},
+		Attributes:  attributes,
+	}
+}
+
+// connectionAttributes returns the schema of the connection settings of the
+// provider, also used by each of its named connections.
+func connectionAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		// Cloud SQL specific parameters
+		"project_id": schema.StringAttribute{
+			Description: "The Google Cloud project ID of the Cloud SQL instance. Required if using Cloud SQL.",
+			Optional:    true,
+		},
+		"region": schema.StringAttribute{
+			Description: "The region of the Cloud SQL instance. Required if using Cloud SQL.",
+			Optional:    true,
+		},
+		"instance": schema.StringAttribute{
+			Description: "The name of the Cloud SQL instance. Required if using Cloud SQL.",
+			Optional:    true,
+		},
+		"cert_fetch_timeout": schema.StringAttribute{
+			Description: "How long to wait for the Cloud SQL Admin API to issue the ephemeral certificate of each connection, e.g. \"1m\", retrying transient errors within it, if using Cloud SQL. A slow API then fails the connection with an error naming this attribute rather than stalling the apply. Defaults to 30s.",
+			Optional:    true,
+			Validators:  []validator.String{durationValidator{}},
+		},
 
-			// Common parameters
-			"database": schema.StringAttribute{
-				Description: "The name of the database to connect to. Defaults to postgres.",
-				Optional:    true,
+		// Common parameters
+		"database": schema.StringAttribute{
+			Description: "The name of the database to connect to. Defaults to postgres.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
-			"username": schema.StringAttribute{
-				Description: "Username for the server connection.",
-				Required:    true,
+		},
+		"username": schema.StringAttribute{
+			Description: "Username for the server connection.",
+			Required:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
-			"impersonate_service_account": schema.StringAttribute{
-				MarkdownDescription: `The service account to impersonate when connecting to the database.
+		},
+		"impersonate_service_account": schema.StringAttribute{
+			MarkdownDescription: `The service account to impersonate when connecting to the database. With standard PostgreSQL, e.g. through the Cloud SQL Auth Proxy, it requires iam_authentication and the IAM token is minted for the service account.
 
   When using this option, you must ensure:
 
     * The impersonated service account has sufficient permissions to connect to the database
     * The principal (that is impersonating the service account) has sufficient permissions to impersonate the service account`,
-				Optional: true,
+			Optional: true,
+		},
+		"impersonation_scopes": schema.ListAttribute{
+			Description: "The OAuth2 scopes of the tokens of the impersonated service account, e.g. only [\"https://www.googleapis.com/auth/sqlservice.login\"] with iam_authentication, which does not need the Cloud SQL Admin API. Defaults to the sqlservice.admin and sqlservice.login scopes.",
+			ElementType: types.StringType,
+			Optional:    true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("impersonate_service_account")),
+			},
+		},
+		"quota_project": schema.StringAttribute{
+			Description: "The Google Cloud project billed for the API calls of the provider, e.g. to the Cloud SQL Admin and IAM Credentials APIs, instead of the project of the credentials. Like the billing/quota_project property of gcloud.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
+		},
+		"universe_domain": schema.StringAttribute{
+			Description: "The domain of the Google Cloud universe of the instance, for Trusted Partner Cloud universes. Defaults to googleapis.com.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"sqladmin_endpoint": schema.StringAttribute{
+			Description: "The base URL of the Cloud SQL Admin API, used to fetch the certificates of Cloud SQL instances, e.g. \"https://sqladmin-myendpoint.p.googleapis.com/\" for a Private Service Connect endpoint in environments without internet access. Defaults to the endpoint of the universe domain. The Google Cloud clients go through the proxy set by the HTTPS_PROXY environment variable, if any.",
+			Optional:    true,
+			Validators:  endpointValidators(),
+		},
+		"iam_credentials_endpoint": schema.StringAttribute{
+			Description: "The base URL of the IAM Credentials API, used to mint the tokens of impersonate_service_account, e.g. \"https://iamcredentials-myendpoint.p.googleapis.com/\". Defaults to the endpoint of the universe domain.",
+			Optional:    true,
+			Validators:  endpointValidators(),
+		},
 
-			// Standard PostgreSQL parameters
-			"host": schema.StringAttribute{
-				Description: "The host of the PostgreSQL server. Required if using standard PostgreSQL.",
-				Optional:    true,
+		// Standard PostgreSQL parameters
+		"host": schema.StringAttribute{
+			Description: "The host of the PostgreSQL server, or a comma-separated list of hosts tried in order until one accepts the connection, e.g. \"db1.internal,db2.internal\", all on port. IPv6 addresses may be bracketed, e.g. \"[::1]\". Required if using standard PostgreSQL.",
+			Optional:    true,
+			Validators:  hostValidators(),
+		},
+		"port": schema.Int64Attribute{
+			Description: "The port of the PostgreSQL server. Default is 5432.",
+			Optional:    true,
+			Validators:  portValidators(),
+		},
+		"password": schema.StringAttribute{
+			Description: "Password for the server connection, if using standard PostgreSQL. Omit it for trust or peer authentication, or to read it from the password file, e.g. ~/.pgpass.",
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"sslmode": schema.StringAttribute{
+			Description: "SSL mode for the server connection. Default is 'disable'.",
+			Optional:    true,
+		},
+		"expected_server_name": schema.StringAttribute{
+			Description: "Name the certificate of the server is verified against with sslmode verify-full, and sent in the TLS SNI extension, instead of host, if using standard PostgreSQL, e.g. when connecting through a load balancer, a private endpoint or an SSH tunnel whose name differs from the certificate. Requires sslmode require, verify-ca or verify-full. As with lib/pq, the root certificates are read from PGSSLROOTCERT, or are those of the system, and the client certificate from PGSSLCERT and PGSSLKEY, or ~/.postgresql/postgresql.crt and postgresql.key.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
-			"port": schema.Int64Attribute{
-				Description: "The port of the PostgreSQL server. Default is 5432.",
-				Optional:    true,
+		},
+		"min_tls_version": schema.StringAttribute{
+			Description: "Minimum TLS version of the server connection, if using standard PostgreSQL: \"1.2\" or \"1.3\", e.g. to satisfy compliance requirements on self-managed clusters. Requires sslmode require, verify-ca or verify-full. Defaults to 1.2.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.OneOf("1.2", "1.3"),
 			},
-			"password": schema.StringAttribute{
-				Description: "Password for the server connection. Required if using standard PostgreSQL.",
-				Optional:    true,
-				Sensitive:   true,
+		},
+		"tls_cipher_suites": schema.ListAttribute{
+			Description: "Cipher suites offered for TLS 1.2 on the server connection, if using standard PostgreSQL, by their IANA names, e.g. [\"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384\"]. Only cipher suites without known security issues are supported. TLS 1.3 cipher suites are not configurable, so this conflicts with min_tls_version 1.3. Requires sslmode require, verify-ca or verify-full. Defaults to the cipher suites of the Go standard library.",
+			ElementType: types.StringType,
+			Optional:    true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.ValueStringsAre(stringvalidator.OneOf(cipherSuiteNames()...)),
 			},
-			"sslmode": schema.StringAttribute{
-				Description: "SSL mode for the server connection. Default is 'disable'.",
-				Optional:    true,
+		},
+		"iam_authentication": schema.BoolAttribute{
+			Description: "Whether to authenticate with a Cloud SQL IAM database authentication token as the password, if using standard PostgreSQL, e.g. through the Cloud SQL Auth Proxy or a private IP. The token is minted for impersonate_service_account if set, and for the application default credentials otherwise; username is the IAM database user. Conflicts with password. Defaults to false.",
+			Optional:    true,
+		},
+		"password_command": schema.ListAttribute{
+			Description: "Command run to obtain the password at each connection, if using standard PostgreSQL, as the program followed by its arguments, e.g. [\"aws\", \"rds\", \"generate-db-auth-token\", \"--hostname\", \"db.example.com\", \"--port\", \"5432\", \"--username\", \"terraform\"]. The password is its output, without the trailing newline. The command is not run through a shell. Conflicts with password and iam_authentication.",
+			ElementType: types.StringType,
+			Optional:    true,
+			Validators: []validator.List{
+				listvalidator.SizeAtLeast(1),
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+		},
+		"password_keyring": schema.StringAttribute{
+			Description: "Service name of the entry of the credential store of the OS holding the password, if using standard PostgreSQL, for the account of username, so that it is never written in variables files: the login keychain on macOS, the Secret Service on Linux (entries with service and username attributes, e.g. stored with secret-tool store --label=pgrole service <service> username <username>), or the Credential Manager on Windows (generic credentials named <service>:<username>). The password is read when the provider is configured. Conflicts with password, password_command and iam_authentication.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
 		},
 	}
 }
 
+// ConfigValidators ensures that exactly one connection mode is configured:
+// either Cloud SQL (project_id, region and instance) or standard PostgreSQL
+// (host), and that attributes of both modes are not mixed.
+func (p *pgroleProvider) ConfigValidators(ctx context.Context) []provider.ConfigValidator {
+	validators := []provider.ConfigValidator{
+		providervalidator.AtLeastOneOf(path.MatchRoot("project_id"), path.MatchRoot("host")),
+		providervalidator.RequiredTogether(path.MatchRoot("project_id"), path.MatchRoot("region"), path.MatchRoot("instance")),
+	}
+	for _, cloudSQLAttribute := range cloudSQLAttributes {
+		for _, standardAttribute := range standardAttributes {
+			validators = append(validators, providervalidator.Conflicting(path.MatchRoot(cloudSQLAttribute), path.MatchRoot(standardAttribute)))
+		}
+	}
+	return validators
+}
+
 func (p *pgroleProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	// Retrieve provider config from configuration
 	var config pgroleModel
@@ -138,6 +398,13 @@ func (p *pgroleProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			"unknown instance",
 		)
 	}
+	if config.CertFetchTimeout.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cert_fetch_timeout"),
+			"unknown cert_fetch_timeout",
+			"unknown cert_fetch_timeout",
+		)
+	}
 	if config.Database.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("database"),
@@ -159,6 +426,41 @@ func (p *pgroleProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			"unknown impersonate_service_account",
 		)
 	}
+	if config.ImpersonationScopes.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("impersonation_scopes"),
+			"unknown impersonation_scopes",
+			"unknown impersonation_scopes",
+		)
+	}
+	if config.QuotaProject.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("quota_project"),
+			"unknown quota_project",
+			"unknown quota_project",
+		)
+	}
+	if config.UniverseDomain.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("universe_domain"),
+			"unknown universe_domain",
+			"unknown universe_domain",
+		)
+	}
+	if config.SQLAdminEndpoint.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("sqladmin_endpoint"),
+			"unknown sqladmin_endpoint",
+			"unknown sqladmin_endpoint",
+		)
+	}
+	if config.IAMCredentialsEndpoint.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("iam_credentials_endpoint"),
+			"unknown iam_credentials_endpoint",
+			"unknown iam_credentials_endpoint",
+		)
+	}
 	if config.Host.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("host"),
@@ -187,112 +489,184 @@ func (p *pgroleProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			"unknown sslmode",
 		)
 	}
-	if resp.Diagnostics.HasError() {
-		return
+	if config.ExpectedServerName.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expected_server_name"),
+			"unknown expected_server_name",
+			"unknown expected_server_name",
+		)
 	}
-
-	// Extract values from configuration
-	projectID := ""
-	region := ""
-	instance := ""
-	database := "postgres"
-	username := ""
-	impersonateServiceAccount := ""
-	host := ""
-	port := int64(5432) // Default PostgreSQL port
-	password := ""
-	sslmode := "disable" // Default to disable SSL
-
-	if !config.ProjectID.IsNull() {
-		projectID = config.ProjectID.ValueString()
+	if config.MinTLSVersion.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("min_tls_version"),
+			"unknown min_tls_version",
+			"unknown min_tls_version",
+		)
 	}
-	if !config.Region.IsNull() {
-		region = config.Region.ValueString()
+	if config.TLSCipherSuites.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("tls_cipher_suites"),
+			"unknown tls_cipher_suites",
+			"unknown tls_cipher_suites",
+		)
 	}
-	if !config.Instance.IsNull() {
-		instance = config.Instance.ValueString()
+	if config.PasswordKeyring.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password_keyring"),
+			"unknown password_keyring",
+			"unknown password_keyring",
+		)
 	}
-	if !config.Database.IsNull() {
-		database = config.Database.ValueString()
+	if config.PasswordCommand.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password_command"),
+			"unknown password_command",
+			"unknown password_command",
+		)
 	}
-	if !config.Username.IsNull() {
-		username = config.Username.ValueString()
+	if config.IAMAuthentication.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("iam_authentication"),
+			"unknown iam_authentication",
+			"unknown iam_authentication",
+		)
 	}
-	if !config.ImpersonateServiceAccount.IsNull() {
-		impersonateServiceAccount = config.ImpersonateServiceAccount.ValueString()
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !config.Host.IsNull() {
-		host = config.Host.ValueString()
+
+	resp.Diagnostics.Append(validateAuthentication(path.Empty(), config.connectionModel)...)
+	resp.Diagnostics.Append(validateTLS(path.Empty(), config.connectionModel)...)
+	connections := make(map[string]connectionConfig, len(config.Connections))
+	for name, connection := range config.Connections {
+		p := path.Root("connections").AtMapKey(name)
+		diags := connection.validate(p)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			continue
+		}
+		c := connection.config()
+		if err := c.resolveKeyring(ctx); err != nil {
+			resp.Diagnostics.AddAttributeError(p.AtName("password_keyring"), "Failed to read password from keyring", err.Error())
+		}
+		connections[name] = c
 	}
-	if !config.Port.IsNull() {
-		port = config.Port.ValueInt64()
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !config.Password.IsNull() {
-		password = config.Password.ValueString()
+	defaultConnection := config.connectionModel.config()
+	if err := defaultConnection.resolveKeyring(ctx); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("password_keyring"), "Failed to read password from keyring", err.Error())
+		return
 	}
-	if !config.SSLMode.IsNull() {
-		sslmode = config.SSLMode.ValueString()
+
+	telemetry, diags := newTelemetry(ctx, config.Telemetry)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	var dbgetter F
+	var wait time.Duration
+	if !config.WaitForDatabase.IsNull() {
+		wait, _ = time.ParseDuration(config.WaitForDatabase.ValueString())
+	}
 
-	// Check if we should use standard PostgreSQL connection
-	if host != "" {
-		// Use standard PostgreSQL connection
-		url := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-			username, password, host, port, database, sslmode)
-		dbgetter = GetStandardPostgresGetter(url)
-	} else {
-		// Continue with Cloud SQL connection
-		if projectID == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("project_id"),
-				"missing project_id",
-				"project_id is required for Cloud SQL connection",
-			)
-		}
-		if region == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("region"),
-				"missing region",
-				"region is required for Cloud SQL connection",
-			)
+	// connect returns the getter of connections to database on the named
+	// connection
+	connect := func(connection, database string) DBGetter {
+		if connection == "" {
+			return telemetry.instrument(waitForDatabase(defaultConnection.connect(database), wait))
 		}
-		if instance == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("instance"),
-				"missing instance",
-				"instance is required for Cloud SQL connection",
-			)
+		c, ok := connections[connection]
+		if !ok {
+			return unknownConnection(connection)
 		}
-		if database == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("database"),
-				"missing database",
-				"database is required for Cloud SQL connection",
-			)
+		return telemetry.instrument(waitForDatabase(c.connect(database), wait))
+	}
+	connectAs := func(connection, database, role, password string) DBGetter {
+		c, ok := defaultConnection, true
+		if connection != "" {
+			c, ok = connections[connection]
 		}
-		if username == "" {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("username"),
-				"missing username",
-				"username is required for Cloud SQL connection",
-			)
+		if !ok {
+			return unknownConnection(connection)
 		}
+		return telemetry.instrument(waitForDatabase(c.as(role, password).connect(database), wait))
+	}
+
+	retry, err := defaultRetryPolicy.override(config.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	retry.telemetry = telemetry
+
+	audit, diags := newEventAudit(config.EventAudit)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	retry.audit = audit
+
+	parameterGuard, diags := newParameterGuard(config.AllowedParameters, config.DeniedParameters)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleNamePattern, err := compileRoleNamePattern(config.RoleNamePattern)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("role_name_pattern"),
+			"Invalid role_name_pattern",
+			err.Error(),
+		)
+		return
+	}
+
+	profiles := config.Profiles
+	if profiles == nil {
+		profiles = map[string]profileModel{}
+	}
+	for name, profile := range profiles {
+		p := path.Root("profiles").AtMapKey(name)
+		resp.Diagnostics.Append(profile.validate(p)...)
+		resp.Diagnostics.Append(parameterGuard.check(p.AtName("settings"), sortedKeys(profile.settings())...)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := &providerData{
+		db:               connect("", ""),
+		connect:          connect,
+		connectAs:        connectAs,
+		retry:            retry,
+		passwordRules:    config.PasswordPolicy.rules(),
+		verifyWrites:     config.VerifyWrites.ValueBool(),
+		profiles:         profiles,
+		allowSystemRoles: config.AllowSystemRoles.ValueBool(),
+		roleNamePattern:  roleNamePattern,
+		parameterGuard:   parameterGuard,
+
+		dsn:                       defaultConnection.dsn(""),
+		impersonateServiceAccount: defaultConnection.impersonateServiceAccount,
+		impersonationScopes:       defaultConnection.impersonationScopes,
+		gcp:                       defaultConnection.gcp,
+	}
+	if config.CompatibilityCheck != nil {
+		resp.Diagnostics.Append(checkCompatibility(ctx, data.db, config.CompatibilityCheck)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-
-		url := fmt.Sprintf("gcppostgres://%s@%s/%s/%s/%s", username, projectID, region, instance, database)
-		if impersonateServiceAccount != "" {
-			dbgetter = GetDatabaseGetterWithImpersonation(url, impersonateServiceAccount)
-		} else {
-			dbgetter = GetDatabaseGetter(url)
-		}
 	}
-
-	resp.DataSourceData = dbgetter
-	resp.ResourceData = dbgetter
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.EphemeralResourceData = data
 }
 
 func (p *pgroleProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -303,15 +677,64 @@ func (p *pgroleProvider) Resources(ctx context.Context) []func() resource.Resour
 		NewReplicationResource,
 		NewAuditResource,
 		NewSecurityLabelResource,
+		NewByteaOutputResource,
+		NewIntervalStyleResource,
+		NewExtraFloatDigitsResource,
+		NewClientEncodingResource,
+		NewLCSettingsResource,
+		NewCheckFunctionBodiesResource,
+		NewConstraintExclusionResource,
+		NewCollapseLimitsResource,
+		NewGEQOSettingsResource,
+		NewParallelQuerySettingsResource,
+		NewEffectiveIOConcurrencyResource,
+		NewStatementMemoryLimitsResource,
+		NewDefaultTransactionDeferrableResource,
+		NewTCPKeepalivesResource,
+		NewBackslashQuoteSafetyResource,
+		NewTrackSettingsResource,
+		NewPgStatStatementsSettingsResource,
+		NewCitusSettingsResource,
+		NewTimescaleDBSettingsResource,
+		NewConfigMapResource,
+		NewPasswordResource,
+		NewLoginResource,
+		NewPasswordExpiryNotifierResource,
+		NewRoleTemplateResource,
+		NewRoleAttributesEnforcerResource,
+	}
+}
+
+func (p *pgroleProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewCloudSQLIAMTokenEphemeralResource,
+		NewConnectionStringEphemeralResource,
+		NewPasswordEphemeralResource,
+		NewPasswordAuthCheckEphemeralResource,
 	}
 }
 
 func (p *pgroleProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewRoleSnapshotDataSource,
+		NewRolesDataSource,
+		NewInstanceInfoDataSource,
+		NewGrantRoleDataSource,
+		NewMembershipGraphDataSource,
+		NewAuditCoverageDataSource,
+		NewEffectiveSettingDataSource,
+		NewPingDataSource,
+	}
 }
 
 func (p *pgroleProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewToAlterStatementsFunction,
+		NewValidatePgIdentifierFunction,
+		NewIntervalToMsFunction,
+		NewMemoryToBytesFunction,
+		NewBytesToMemoryFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -1,23 +1,146 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/pgtest"
+)
+
+var (
+	// providerConfig connects to the acceptance test database started by
+	// TestMain.
+	providerConfig string
+
+	// testDSN is the connection string of the acceptance test database.
+	testDSN string
 )
 
-const (
-	providerConfig = `
+// TestMain starts a disposable PostgreSQL instance for acceptance tests. Set
+// PGROLE_TEST_DSN to use an existing database instead, e.g. the one started
+// by docker-compose.yml.
+func TestMain(m *testing.M) {
+	if os.Getenv("TF_ACC") == "" {
+		os.Exit(m.Run())
+	}
+
+	instance, err := pgtest.Start(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start test database: %s\n", err)
+		os.Exit(1)
+	}
+	providerConfig = instance.ProviderConfig()
+	testDSN = instance.DSN
+
+	code := m.Run()
+	if err := instance.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to stop test database: %s\n", err)
+	}
+	os.Exit(code)
+}
+
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"pgrole": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccExecSQL returns a function that runs sqlstr against the acceptance
+// test database, used to make out-of-band changes between test steps.
+func testAccExecSQL(t *testing.T, sqlstr string) func() {
+	return func() {
+		ctx := context.Background()
+		db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get database connection: %s", err)
+		}
+		defer db.Close()
+		if _, err := db.ExecContext(ctx, sqlstr); err != nil {
+			t.Fatalf("Failed to execute SQL: %s", err)
+		}
+	}
+}
+
+func TestProviderConfigValidators(t *testing.T) {
+	const res = `
+resource "pgrole_bypassrls" "test" {
+  role = "test"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Neither connection mode
+			{
+				Config: `
+provider "pgrole" {
+  username = "my-username"
+}
+` + res,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`At least one of these attributes must be configured`),
+			},
+			// Incomplete Cloud SQL connection
+			{
+				Config: `
+provider "pgrole" {
+  project_id = "my-project"
+  username   = "my-username"
+}
+` + res,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`These attributes must be configured together`),
+			},
+			// Mixed connection modes
+			{
+				Config: `
 provider "pgrole" {
   project_id = "my-project"
   region     = "my-region"
   instance   = "my-instance"
-  database   = "my-database"
+  host       = "localhost"
+  password   = "postgres"
+  username   = "my-username"
+}
+` + res,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`These attributes cannot be configured together`),
+			},
+		},
+	})
+}
 
-  username = "my-username"
+// testAccPreCheckExtension skips the test when the extension is not
+// available in the acceptance test database.
+func testAccPreCheckExtension(t *testing.T, extension string) func() {
+	return func() {
+		ctx := context.Background()
+		db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get database connection: %s", err)
+		}
+		defer db.Close()
+
+		var available bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = $1);", extension).Scan(&available)
+		if err != nil {
+			t.Fatalf("Failed to query extensions: %s", err)
+		}
+		if !available {
+			t.Skipf("Extension %s is not available", extension)
+		}
+	}
 }
-`
-)
 
-var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"pgrole": providerserver.NewProtocol6WithError(New("test")()),
+// testAccSkipBelowVersion returns a PreCheck skipping the test when the
+// acceptance test database runs a PostgreSQL major version below major.
+func testAccSkipBelowVersion(t *testing.T, major int) func() {
+	return func() {
+		pgtest.SkipBelowVersion(t, testDSN, major)
+	}
 }
@@ -2,11 +2,21 @@ package provider
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -14,15 +24,22 @@ var (
 	_ resource.Resource                = (*replicationResource)(nil)
 	_ resource.ResourceWithConfigure   = (*replicationResource)(nil)
 	_ resource.ResourceWithImportState = (*replicationResource)(nil)
+	_ resource.ResourceWithIdentity    = (*replicationResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*replicationResource)(nil)
+	_ resource.ResourceWithMoveState   = (*replicationResource)(nil)
 )
 
-// NewBypassRLSResource is a helper function to simplify the provider implementation.
+// NewReplicationResource is a helper function to simplify the provider implementation.
 func NewReplicationResource() resource.Resource {
 	return &replicationResource{}
 }
 
 type replicationResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
 }
 
 // Metadata returns the resource type name.
@@ -33,23 +50,55 @@ func (r *replicationResource) Metadata(_ context.Context, req resource.MetadataR
 // Schema defines the schema for the resource.
 func (r *replicationResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Manage REPLICATION status for an existing role. See PostgreSQL [ALTER ROLE](https://www.postgresql.org/docs/current/sql-alterrole.html).",
+		MarkdownDescription: "Manage REPLICATION status for an existing role. See PostgreSQL [ALTER ROLE](https://www.postgresql.org/docs/current/sql-alterrole.html).\n\nDisabling REPLICATION fails while the role has active replication connections or slots, whose reconnections would be refused, unless force_disable is set.",
 		Attributes: map[string]schema.Attribute{
 			"role": schema.StringAttribute{
 				Description: "Name of the role.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"enabled": schema.BoolAttribute{
-				Description: "Whether to enable REPLICATION for the role.",
+				Description: "Whether to enable REPLICATION for the role. Defaults to false.",
 				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
+			"force_disable": schema.BoolAttribute{
+				Description: "Whether to disable REPLICATION even though the role is streaming from the server, i.e. has active walsenders or replication slots. Their sessions are left alone but cannot reconnect, breaking replication. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"adopt":                 adoptAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type replicationModel struct {
-	Role    string `tfsdk:"role"`
-	Enabled bool   `tfsdk:"enabled"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	Enabled            bool         `tfsdk:"enabled"`
+	ForceDisable       bool         `tfsdk:"force_disable"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	Adopt              bool         `tfsdk:"adopt"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *replicationResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -60,15 +109,50 @@ func (r *replicationResource) Configure(_ context.Context, req resource.Configur
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *replicationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	r.getDB = client
+	var role types.String
+	var enabled types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("enabled"), &enabled)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || enabled.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetReplication(role.ValueString(), enabled.ValueBool())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -82,14 +166,10 @@ func (r *replicationResource) Create(ctx context.Context, req resource.CreateReq
 	}
 
 	// Create the resource
-	var sqlstr string
-	if plan.Enabled {
-		sqlstr = sqlEnableReplication(plan.Role)
-	} else {
-		sqlstr = sqlDisableReplication(plan.Role)
-	}
+	sqlstr := sqlSetReplication(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -98,15 +178,41 @@ func (r *replicationResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 	defer db.Close()
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "REPLICATION") {
+		return
+	}
+	if !plan.Enabled && !checkReplicationUse(ctx, db, &resp.Diagnostics, plan.Role, plan.ForceDisable) {
+		return
+	}
+	if plan.Adopt && !recordOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readReplication(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readReplication(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -117,7 +223,7 @@ func (r *replicationResource) Create(ctx context.Context, req resource.CreateReq
 // Read refreshes the Terraform state with the latest data.
 func (r *replicationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	// Get the current state
-	var state bypassrlsModel
+	var state replicationModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -125,7 +231,7 @@ func (r *replicationResource) Read(ctx context.Context, req resource.ReadRequest
 	}
 
 	// Get the actual state in postgres
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -135,19 +241,30 @@ func (r *replicationResource) Read(ctx context.Context, req resource.ReadRequest
 	}
 	defer db.Close()
 
-	var enabled bool
-	if err := db.QueryRowContext(ctx, "SELECT rolreplication FROM pg_roles WHERE rolname = $1;", state.Role).Scan(&enabled); err != nil {
-		resp.Diagnostics.AddError(
+	enabled, err := readReplication(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
 			"Failed to query REPLICATION status",
 			fmt.Sprintf("Failed to query REPLICATION status for role %s: %s", state.Role, err),
 		)
 		return
 	}
 
+	logApplied(ctx, req.Private, state.Role, state.SQL.ValueString())
+	addDriftWarning(&resp.Diagnostics, state.Role, "enabled", state.Enabled, enabled)
+
 	// Overwrite the state with the actual state
 	state.Enabled = enabled
 
 	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -166,14 +283,10 @@ func (r *replicationResource) Update(ctx context.Context, req resource.UpdateReq
 	}
 
 	// Update resource state with updated values
-	var sqlstr string
-	if plan.Enabled {
-		sqlstr = sqlEnableReplication(plan.Role)
-	} else {
-		sqlstr = sqlDisableReplication(plan.Role)
-	}
+	sqlstr := sqlSetReplication(plan.Role, plan.Enabled)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -182,14 +295,40 @@ func (r *replicationResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "REPLICATION") {
+		return
+	}
+	if !plan.Enabled && !checkReplicationUse(ctx, db, &resp.Diagnostics, plan.Role, plan.ForceDisable) {
+		return
+	}
+	if plan.Adopt && !recordMissingOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (bool, error) {
+		return readReplication(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readReplication(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("enabled", plan.Enabled, actual)) {
+			return
+		}
+	}
 
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -207,9 +346,23 @@ func (r *replicationResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 
-	// Delete the resource
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Delete the resource, or restore the original value
 	sqlstr := sqlDisableReplication(state.Role)
-	db, err := r.getDB(ctx)
+	var original bool
+	if state.Adopt && getOriginal(ctx, &resp.Diagnostics, req.Private, &original) {
+		sqlstr = sqlSetReplication(state.Role, original)
+	}
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -218,24 +371,167 @@ func (r *replicationResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "REPLICATION") {
+		return
+	}
+	if state.Enabled && !checkReplicationUse(ctx, db, &resp.Diagnostics, state.Role, state.ForceDisable) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 }
 
 func (r *replicationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.State.SetAttribute(ctx, path.Root("enabled"), false)
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enabled, err := readReplication(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query REPLICATION status",
+			fmt.Sprintf("Failed to query REPLICATION status for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enabled"), enabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("force_disable"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetReplication(role, enabled))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_role resource of the community
+// PostgreSQL provider into this resource.
+func (r *replicationResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlRoleState
+				if !readPostgresqlState(req, resp, "postgresql_role", &source) {
+					return
+				}
+
+				target := replicationModel{
+					Role:    source.Name,
+					Enabled: source.Replication,
+					SQL:     types.StringValue(sqlSetReplication(source.Name, source.Replication)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.Name)...)
+			},
+		},
+	}
+}
+
+// readReplication returns the REPLICATION status of the role, or
+// sql.ErrNoRows if the role does not exist.
+func readReplication(ctx context.Context, db *sql.DB, role string) (bool, error) {
+	var enabled bool
+	err := db.QueryRowContext(ctx, "SELECT rolreplication FROM pg_roles WHERE rolname = $1;", role).Scan(&enabled)
+	return enabled, err
+}
+
+// sqlReplicationUse returns the number of walsenders streaming as $1 and the
+// names of the replication slots they keep active.
+const sqlReplicationUse = `
+SELECT
+	(SELECT count(*) FROM pg_stat_replication WHERE usename = $1),
+	COALESCE((
+		SELECT string_agg(s.slot_name, ', ' ORDER BY s.slot_name)
+		FROM pg_replication_slots s JOIN pg_stat_activity a ON a.pid = s.active_pid
+		WHERE s.active AND a.usename = $1
+	), '');`
+
+// checkReplicationUse verifies that role can lose REPLICATION without
+// breaking replication, i.e. that it does not stream from the server. Active
+// use is an error, or a warning if force is set.
+func checkReplicationUse(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, role string, force bool) bool {
+	var walsenders int
+	var slots string
+	if err := db.QueryRowContext(ctx, sqlReplicationUse, role).Scan(&walsenders, &slots); err != nil {
+		diags.AddError(
+			"Failed to query replication",
+			fmt.Sprintf("Failed to query the replication connections of role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return false
+	}
+	if walsenders == 0 && slots == "" {
+		return true
+	}
+
+	use := fmt.Sprintf("%d active replication connection(s)", walsenders)
+	if slots != "" {
+		use += " and active replication slot(s) " + slots
+	}
+	if force {
+		diags.AddWarning(
+			"Replication in use",
+			fmt.Sprintf("REPLICATION is disabled for role %s although it has %s, as force_disable is set. They cannot reconnect anymore.", role, use),
+		)
+		return true
+	}
+	diags.AddAttributeError(
+		path.Root("enabled"),
+		"Replication in use",
+		fmt.Sprintf("Cannot disable REPLICATION for role %s: it has %s. Disabling REPLICATION does not end them, but they could not reconnect, silently breaking replication. Stop the replication first, or set force_disable = true to disable it anyway.", role, use),
+	)
+	return false
+}
+
+func sqlSetReplication(role string, enabled bool) string {
+	if enabled {
+		return sqlEnableReplication(role)
+	}
+	return sqlDisableReplication(role)
 }
 
 func sqlEnableReplication(role string) string {
-	return fmt.Sprintf("ALTER ROLE %q REPLICATION;", role)
+	return fmt.Sprintf("ALTER ROLE %s REPLICATION;", quoteIdentifier(role))
 }
 
 func sqlDisableReplication(role string) string {
-	return fmt.Sprintf("ALTER ROLE %q NOREPLICATION;", role)
+	return fmt.Sprintf("ALTER ROLE %s NOREPLICATION;", quoteIdentifier(role))
 }
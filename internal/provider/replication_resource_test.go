@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestCheckReplicationUse(t *testing.T) {
+	columns := []string{"walsenders", "slots"}
+	tests := []struct {
+		name    string
+		row     []driver.Value
+		force   bool
+		wantOK  bool
+		warning string
+		error   string
+	}{
+		{
+			name:   "unused",
+			row:    []driver.Value{int64(0), ""},
+			wantOK: true,
+		},
+		{
+			name:  "streaming",
+			row:   []driver.Value{int64(2), ""},
+			error: "it has 2 active replication connection(s)",
+		},
+		{
+			name:  "active slots",
+			row:   []driver.Value{int64(1), "standby_a, standby_b"},
+			error: "active replication slot(s) standby_a, standby_b",
+		},
+		{
+			name:    "forced",
+			row:     []driver.Value{int64(1), "standby_a"},
+			force:   true,
+			wantOK:  true,
+			warning: "as force_disable is set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := fakedb.New().ExpectQuery(`pg_stat_replication`, columns, tt.row).GetDB(ctx)
+			if err != nil {
+				t.Fatalf("GetDB() error = %v", err)
+			}
+			defer db.Close()
+
+			var diags diag.Diagnostics
+			if got := checkReplicationUse(ctx, db, &diags, "example_user", tt.force); got != tt.wantOK {
+				t.Fatalf("checkReplicationUse() = %v, want %v (%v)", got, tt.wantOK, diags)
+			}
+			if tt.error != "" && (diags.ErrorsCount() != 1 || !strings.Contains(diags.Errors()[0].Detail(), tt.error)) {
+				t.Errorf("checkReplicationUse() diagnostics = %v, want an error containing %q", diags, tt.error)
+			}
+			if tt.warning != "" && (diags.WarningsCount() != 1 || !strings.Contains(diags.Warnings()[0].Detail(), tt.warning)) {
+				t.Errorf("checkReplicationUse() diagnostics = %v, want a warning containing %q", diags, tt.warning)
+			}
+			if tt.error == "" && tt.warning == "" && len(diags) != 0 {
+				t.Errorf("checkReplicationUse() diagnostics = %v, want none", diags)
+			}
+		})
+	}
+}
+
+func TestReplicationResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_replication" "test" {
+  role    = "example_user"
+  enabled = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_replication.test", "role", "example_user"),
+					resource.TestCheckResourceAttr("pgrole_replication.test", "enabled", "true"),
+					testAccCheckReplication("example_user", true),
+				),
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" NOREPLICATION;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Drift is corrected on the next apply
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_replication.test", "enabled", "true"),
+					testAccCheckReplication("example_user", true),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckReplication verifies rolreplication of the role directly in
+// the database.
+func testAccCheckReplication(role string, want bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		ctx := context.Background()
+		db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		var got bool
+		if err := db.QueryRowContext(ctx, "SELECT rolreplication FROM pg_roles WHERE rolname = $1;", role).Scan(&got); err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("rolreplication for role %s: got %t, want %t", role, got, want)
+		}
+		return nil
+	}
+}
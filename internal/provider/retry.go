@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	retryDescription           = "Retry policy for SQL statements, e.g. when they contend with long-running migrations holding catalog locks."
+	retryAttemptsDescription   = "Maximum number of attempts per statement. Defaults to 1, i.e. no retry."
+	retryBackoffDescription    = "Delay before the first retry, doubled after each attempt, e.g. \"500ms\" or \"2s\". Defaults to 1s."
+	retryErrorRegexDescription = "Only errors whose text matches this regular expression are retried. Defaults to lock and deadlock errors."
+)
+
+// defaultRetryPolicy is used when neither the provider nor the resource
+// configures a retry policy.
+var defaultRetryPolicy = retryPolicy{
+	Attempts:   1,
+	Backoff:    time.Second,
+	ErrorRegex: regexp.MustCompile(`could not obtain lock|deadlock detected|lock timeout`),
+}
+
+// retryModel describes the retry attribute of the provider and resources.
+type retryModel struct {
+	Attempts   types.Int64  `tfsdk:"attempts"`
+	Backoff    types.String `tfsdk:"backoff"`
+	ErrorRegex types.String `tfsdk:"error_regex"`
+}
+
+// retryPolicy controls how SQL statements are retried.
+type retryPolicy struct {
+	Attempts   int64
+	Backoff    time.Duration
+	ErrorRegex *regexp.Regexp
+
+	// telemetry records the statements and their retries, if not nil.
+	telemetry *telemetry
+	// audit records the statements into the event_audit table, if not nil.
+	audit *eventAudit
+}
+
+// override returns a copy of the policy with the values set in m.
+func (p retryPolicy) override(m *retryModel) (retryPolicy, error) {
+	if m == nil {
+		return p, nil
+	}
+	if !m.Attempts.IsNull() {
+		p.Attempts = m.Attempts.ValueInt64()
+	}
+	if !m.Backoff.IsNull() {
+		backoff, err := time.ParseDuration(m.Backoff.ValueString())
+		if err != nil {
+			return p, fmt.Errorf("invalid backoff: %w", err)
+		}
+		p.Backoff = backoff
+	}
+	if !m.ErrorRegex.IsNull() {
+		re, err := regexp.Compile(m.ErrorRegex.ValueString())
+		if err != nil {
+			return p, fmt.Errorf("invalid error_regex: %w", err)
+		}
+		p.ErrorRegex = re
+	}
+	return p, nil
+}
+
+// execWithRetry runs sqlstr in a transaction, retrying it according to
+// policy. When sqlstr holds several statements, e.g. for a resource changing
+// several settings of a role, either all of them or none are applied, so that
+// roles never end up half-configured.
+//
+// Canceling ctx, e.g. when Terraform is interrupted, cancels the statement
+// running on the server, through the driver, and stops retrying.
+func execWithRetry(ctx context.Context, db *sql.DB, policy retryPolicy, sqlstr string) error {
+	return execMaskedWithRetry(ctx, db, policy, sqlstr, sqlstr)
+}
+
+// execMaskedWithRetry is like execWithRetry, but records masked in the
+// event_audit table instead of sqlstr, for the statements holding secrets,
+// e.g. password verifiers.
+func execMaskedWithRetry(ctx context.Context, db *sql.DB, policy retryPolicy, sqlstr, masked string) error {
+	backoff := policy.Backoff
+	for attempt := int64(1); ; attempt++ {
+		start := time.Now()
+		err := execInTransaction(ctx, db, policy.audit, sqlstr, masked)
+		policy.telemetry.record(ctx, telemetryQuery, time.Since(start), err)
+		if err == nil || ctx.Err() != nil || attempt >= policy.Attempts || !policy.ErrorRegex.MatchString(err.Error()) {
+			return err
+		}
+
+		tflog.Warn(ctx, "Retrying SQL statement", map[string]any{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		policy.telemetry.retry(ctx)
+	}
+}
+
+// execInTransaction runs sqlstr in a transaction, rolled back on failure,
+// recording masked with audit. Errors caused by the cancellation of ctx wrap
+// its error.
+func execInTransaction(ctx context.Context, db *sql.DB, audit *eventAudit, sqlstr, masked string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return canceled(ctx, err)
+	}
+	_, err = tx.ExecContext(ctx, sqlstr)
+	if err == nil {
+		err = audit.record(ctx, tx, masked)
+	}
+	if err != nil {
+		// database/sql already rolls back the transactions of canceled
+		// contexts.
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			tflog.Warn(ctx, "Failed to roll back transaction", map[string]any{
+				"error": rollbackErr.Error(),
+			})
+		}
+		return canceled(ctx, err)
+	}
+	return canceled(ctx, tx.Commit())
+}
+
+// canceled wraps err, unless nil, with the error of ctx once it is canceled,
+// so that the statements interrupted by the driver are reported as such
+// rather than as server errors.
+func canceled(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil || errors.Is(err, ctx.Err()) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ctx.Err(), err)
+}
+
+// providerRetryAttribute returns the schema of the provider retry attribute.
+func providerRetryAttribute() providerschema.SingleNestedAttribute {
+	return providerschema.SingleNestedAttribute{
+		Description: retryDescription,
+		Optional:    true,
+		Attributes: map[string]providerschema.Attribute{
+			"attempts": providerschema.Int64Attribute{
+				Description: retryAttemptsDescription,
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"backoff": providerschema.StringAttribute{
+				Description: retryBackoffDescription,
+				Optional:    true,
+				Validators:  []validator.String{durationValidator{}},
+			},
+			"error_regex": providerschema.StringAttribute{
+				Description: retryErrorRegexDescription,
+				Optional:    true,
+				Validators:  []validator.String{regexValidator{}},
+			},
+		},
+	}
+}
+
+// retryAttribute returns the schema of the resource retry attribute, which
+// overrides the provider retry policy.
+func retryAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: retryDescription + " Overrides the provider retry policy.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"attempts": schema.Int64Attribute{
+				Description: retryAttemptsDescription,
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"backoff": schema.StringAttribute{
+				Description: retryBackoffDescription,
+				Optional:    true,
+				Validators:  []validator.String{durationValidator{}},
+			},
+			"error_regex": schema.StringAttribute{
+				Description: retryErrorRegexDescription,
+				Optional:    true,
+				Validators:  []validator.String{regexValidator{}},
+			},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestRetryPolicyOverride(t *testing.T) {
+	provider, err := defaultRetryPolicy.override(&retryModel{
+		Attempts:   types.Int64Value(3),
+		Backoff:    types.StringNull(),
+		ErrorRegex: types.StringNull(),
+	})
+	if err != nil {
+		t.Fatalf("override() error = %v", err)
+	}
+	if provider.Attempts != 3 || provider.Backoff != time.Second || provider.ErrorRegex != defaultRetryPolicy.ErrorRegex {
+		t.Errorf("override() = %+v, want 3 attempts with the default backoff and regex", provider)
+	}
+
+	resource, err := provider.override(&retryModel{
+		Attempts:   types.Int64Null(),
+		Backoff:    types.StringValue("250ms"),
+		ErrorRegex: types.StringValue("canceling statement"),
+	})
+	if err != nil {
+		t.Fatalf("override() error = %v", err)
+	}
+	if resource.Attempts != 3 || resource.Backoff != 250*time.Millisecond || !resource.ErrorRegex.MatchString("ERROR: canceling statement due to lock timeout") {
+		t.Errorf("override() = %+v, want the provider attempts with the resource backoff and regex", resource)
+	}
+
+	if got, _ := provider.override(nil); got.Attempts != provider.Attempts {
+		t.Errorf("override(nil) = %+v, want %+v", got, provider)
+	}
+
+	if _, err := provider.override(&retryModel{Backoff: types.StringValue("soon")}); err == nil {
+		t.Error("override() with an invalid backoff succeeded, want error")
+	}
+	if _, err := provider.override(&retryModel{ErrorRegex: types.StringValue("(")}); err == nil {
+		t.Error("override() with an invalid error_regex succeeded, want error")
+	}
+}
+
+func TestExecWithRetryTransaction(t *testing.T) {
+	ctx := context.Background()
+	sqlstr := `ALTER ROLE "app" SET work_mem = '64MB';
+ALTER ROLE "app" SET statement_timeout = '30s';`
+
+	fake := fakedb.New()
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+	if err := execWithRetry(ctx, db, defaultRetryPolicy, sqlstr); err != nil {
+		t.Fatalf("execWithRetry() error = %v", err)
+	}
+	var got []string
+	for _, s := range fake.Execs() {
+		got = append(got, s.SQL)
+	}
+	if want := []string{"BEGIN", sqlstr, "COMMIT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("execWithRetry() ran %q, want %q", got, want)
+	}
+
+	fake = fakedb.New().ExpectError(`statement_timeout`, errors.New("canceling statement due to lock timeout"))
+	db, _ = fake.GetDB(ctx)
+	defer db.Close()
+	policy := defaultRetryPolicy
+	policy.Attempts = 2
+	policy.Backoff = time.Millisecond
+	if err := execWithRetry(ctx, db, policy, sqlstr); err == nil {
+		t.Fatal("execWithRetry() succeeded, want error")
+	}
+	got = nil
+	for _, s := range fake.Execs() {
+		got = append(got, s.SQL)
+	}
+	if want := []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("execWithRetry() ran %q, want %q", got, want)
+	}
+}
+
+func TestExecWithRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fake := fakedb.New()
+	db, _ := fake.GetDB(context.Background())
+	defer db.Close()
+	policy := defaultRetryPolicy
+	policy.Attempts = 3
+	policy.Backoff = time.Hour
+	policy.ErrorRegex = regexp.MustCompile(".*")
+	err := execWithRetry(ctx, db, policy, `ALTER ROLE "app" SET work_mem = '64MB';`)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("execWithRetry() error = %v, want %v", err, context.Canceled)
+	}
+	if execs := fake.Execs(); len(execs) != 0 {
+		t.Errorf("execWithRetry() ran %v, want nothing", execs)
+	}
+}
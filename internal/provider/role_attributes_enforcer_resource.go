@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = (*roleAttributesEnforcerResource)(nil)
+	_ resource.ResourceWithConfigure  = (*roleAttributesEnforcerResource)(nil)
+	_ resource.ResourceWithModifyPlan = (*roleAttributesEnforcerResource)(nil)
+)
+
+// NewRoleAttributesEnforcerResource is a helper function to simplify the provider implementation.
+func NewRoleAttributesEnforcerResource() resource.Resource {
+	return &roleAttributesEnforcerResource{}
+}
+
+type roleAttributesEnforcerResource struct {
+	connect func(connection, database string) DBGetter
+}
+
+// Metadata returns the resource type name.
+func (r *roleAttributesEnforcerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_attributes_enforcer"
+}
+
+// Schema defines the schema for the resource.
+func (r *roleAttributesEnforcerResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Asserts which roles may have the privileged attributes SUPERUSER, BYPASSRLS, REPLICATION and CREATEROLE, without managing any role. Every plan and apply fails with a report of the violations, e.g. a role granted SUPERUSER by hand, turning the provider into a guardrail.
+
+Reserved roles, such as postgres and the predefined pg_* roles, are never violations. Destroying the resource only stops the checks.`,
+		Attributes: map[string]schema.Attribute{
+			"superuser_roles": schema.SetAttribute{
+				Description: "Roles allowed to have the SUPERUSER attribute. Any other role having it is a violation. Not checked if null.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"bypassrls_roles": schema.SetAttribute{
+				Description: "Roles allowed to have the BYPASSRLS attribute. Any other role having it is a violation. Not checked if null.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"replication_roles": schema.SetAttribute{
+				Description: "Roles allowed to have the REPLICATION attribute. Any other role having it is a violation. Not checked if null.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"createrole_roles": schema.SetAttribute{
+				Description: "Roles allowed to have the CREATEROLE attribute. Any other role having it is a violation. Not checked if null.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"violations": schema.ListAttribute{
+				Description: "The violations found by the last refresh, empty once the roles comply.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"connection": connectionAttribute(),
+		},
+	}
+}
+
+type roleAttributesEnforcerModel struct {
+	Connection       types.String `tfsdk:"connection"`
+	SuperuserRoles   types.Set    `tfsdk:"superuser_roles"`
+	BypassRLSRoles   types.Set    `tfsdk:"bypassrls_roles"`
+	ReplicationRoles types.Set    `tfsdk:"replication_roles"`
+	CreateRoleRoles  types.Set    `tfsdk:"createrole_roles"`
+	Violations       types.List   `tfsdk:"violations"`
+}
+
+// policy returns the allowed roles by attribute, e.g. "SUPERUSER", for the
+// checked attributes, and whether the policy is known.
+func (m roleAttributesEnforcerModel) policy(ctx context.Context) (map[string][]string, bool) {
+	sets := map[string]types.Set{
+		"SUPERUSER":   m.SuperuserRoles,
+		"BYPASSRLS":   m.BypassRLSRoles,
+		"REPLICATION": m.ReplicationRoles,
+		"CREATEROLE":  m.CreateRoleRoles,
+	}
+	policy := map[string][]string{}
+	for attribute, set := range sets {
+		if set.IsNull() {
+			continue
+		}
+		if set.IsUnknown() {
+			return nil, false
+		}
+		var roles []string
+		if set.ElementsAs(ctx, &roles, false).HasError() {
+			return nil, false
+		}
+		policy[attribute] = roles
+	}
+	return policy, true
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *roleAttributesEnforcerResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+}
+
+// ModifyPlan fails the plan when the roles violate the policy.
+func (r *roleAttributesEnforcerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check when the resource is being destroyed
+	if req.Plan.Raw.IsNull() || r.connect == nil {
+		return
+	}
+
+	var plan roleAttributesEnforcerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Connection.IsUnknown() {
+		return
+	}
+	if !r.enforce(ctx, &resp.Diagnostics, plan) {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("violations"), []string{})...)
+}
+
+// Create checks the roles and sets the initial Terraform state.
+func (r *roleAttributesEnforcerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan roleAttributesEnforcerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.enforce(ctx, &resp.Diagnostics, plan) {
+		return
+	}
+	plan.Violations = types.ListValueMust(types.StringType, nil)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Read refreshes the violations of the policy.
+func (r *roleAttributesEnforcerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state roleAttributesEnforcerModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	policy, _ := state.policy(ctx)
+	violations, err := readRoleAttributeViolations(ctx, db, policy)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role attributes",
+			"Failed to query the attributes of the roles: "+err.Error(),
+		)
+		return
+	}
+	tflog.Info(ctx, "Read role attribute policy violations", map[string]any{
+		"violations": len(violations),
+	})
+
+	state.Violations, _ = types.ListValueFrom(ctx, types.StringType, violations)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// Update checks the roles against the updated policy.
+func (r *roleAttributesEnforcerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan roleAttributesEnforcerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.enforce(ctx, &resp.Diagnostics, plan) {
+		return
+	}
+	plan.Violations = types.ListValueMust(types.StringType, nil)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete stops the checks, leaving the roles alone.
+func (r *roleAttributesEnforcerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "Removing role attribute policy, roles are left unchanged")
+}
+
+// enforce adds an error to diags reporting the violations of the policy of m,
+// and returns false if there are any.
+func (r *roleAttributesEnforcerResource) enforce(ctx context.Context, diags *diag.Diagnostics, m roleAttributesEnforcerModel) bool {
+	policy, ok := m.policy(ctx)
+	if !ok {
+		// Checked once the allowed roles are known
+		return true
+	}
+
+	db, err := r.connect(m.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	violations, err := readRoleAttributeViolations(ctx, db, policy)
+	if err != nil {
+		diags.AddError(
+			"Failed to query role attributes",
+			"Failed to query the attributes of the roles: "+err.Error(),
+		)
+		return false
+	}
+	if len(violations) > 0 {
+		diags.AddError(
+			"Role attribute policy violated",
+			"The roles do not comply with the policy:\n\n  - "+strings.Join(violations, "\n  - ")+"\n\nRevoke the attributes, or allow the roles in the policy.",
+		)
+		return false
+	}
+	return true
+}
+
+// roleAttributesEnforcerAttributes are the checked role attributes, in the
+// order of the columns of pg_roles read, with the attribute of the policy
+// allowing them.
+var roleAttributesEnforcerAttributes = []struct {
+	Name, Attribute string
+}{
+	{"SUPERUSER", "superuser_roles"},
+	{"BYPASSRLS", "bypassrls_roles"},
+	{"REPLICATION", "replication_roles"},
+	{"CREATEROLE", "createrole_roles"},
+}
+
+// readRoleAttributeViolations returns the violations of policy, the allowed
+// roles by attribute, sorted by role. Reserved roles are never violations.
+func readRoleAttributeViolations(ctx context.Context, db *sql.DB, policy map[string][]string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT rolname, rolsuper, rolbypassrls, rolreplication, rolcreaterole FROM pg_roles ORDER BY rolname;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	violations := []string{}
+	for rows.Next() {
+		var role string
+		has := make([]bool, len(roleAttributesEnforcerAttributes))
+		if err := rows.Scan(&role, &has[0], &has[1], &has[2], &has[3]); err != nil {
+			return nil, err
+		}
+		if isSystemRole(role) {
+			continue
+		}
+		for i, a := range roleAttributesEnforcerAttributes {
+			allowed, checked := policy[a.Name]
+			if !has[i] || !checked || slices.Contains(allowed, role) {
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("Role %s has %s but is not in %s, e.g. revoke it with: ALTER ROLE %s NO%s;", role, a.Name, a.Attribute, quoteIdentifier(role), a.Name))
+		}
+	}
+	return violations, rows.Err()
+}
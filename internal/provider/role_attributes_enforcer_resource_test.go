@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadRoleAttributeViolations(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`FROM pg_roles`,
+		[]string{"rolname", "rolsuper", "rolbypassrls", "rolreplication", "rolcreaterole"},
+		[]driver.Value{"admin", true, true, false, true},
+		[]driver.Value{"app", false, true, false, false},
+		[]driver.Value{"etl", true, false, true, false},
+		[]driver.Value{"postgres", true, true, true, true},
+		[]driver.Value{"pg_monitor", false, false, false, false},
+	)
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	m := roleAttributesEnforcerModel{
+		SuperuserRoles:   types.SetValueMust(types.StringType, []attr.Value{types.StringValue("admin")}),
+		BypassRLSRoles:   types.SetValueMust(types.StringType, []attr.Value{}),
+		ReplicationRoles: types.SetNull(types.StringType),
+		CreateRoleRoles:  types.SetValueMust(types.StringType, []attr.Value{types.StringValue("admin")}),
+	}
+	policy, ok := m.policy(ctx)
+	if !ok {
+		t.Fatal("policy() is unknown")
+	}
+	got, err := readRoleAttributeViolations(ctx, db, policy)
+	if err != nil {
+		t.Fatalf("readRoleAttributeViolations() error = %v", err)
+	}
+	want := []string{
+		`Role admin has BYPASSRLS but is not in bypassrls_roles, e.g. revoke it with: ALTER ROLE "admin" NOBYPASSRLS;`,
+		`Role app has BYPASSRLS but is not in bypassrls_roles, e.g. revoke it with: ALTER ROLE "app" NOBYPASSRLS;`,
+		`Role etl has SUPERUSER but is not in superuser_roles, e.g. revoke it with: ALTER ROLE "etl" NOSUPERUSER;`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readRoleAttributeViolations() = %q, want %q", got, want)
+	}
+}
+
+func TestRoleAttributesEnforcerPolicyUnknown(t *testing.T) {
+	m := roleAttributesEnforcerModel{
+		SuperuserRoles:   types.SetUnknown(types.StringType),
+		BypassRLSRoles:   types.SetNull(types.StringType),
+		ReplicationRoles: types.SetNull(types.StringType),
+		CreateRoleRoles:  types.SetNull(types.StringType),
+	}
+	if _, ok := m.policy(context.Background()); ok {
+		t.Error("policy() with unknown superuser_roles is known, want unknown")
+	}
+}
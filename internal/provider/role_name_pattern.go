@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// compileRoleNamePattern returns the regular expression of the
+// role_name_pattern of the provider, matching whole role names, or nil if
+// not set. The pattern is checked by regexValidator.
+func compileRoleNamePattern(pattern types.String) (*regexp.Regexp, error) {
+	if pattern.IsNull() {
+		return nil, nil
+	}
+	return regexp.Compile(`^(?:` + pattern.ValueString() + `)$`)
+}
+
+// checkRoleName returns an error on the role attribute of plan when it does
+// not match pattern, for resources to refuse at plan time the roles breaking
+// the naming conventions set by the provider. A nil pattern allows any role.
+func checkRoleName(ctx context.Context, plan tfsdk.Plan, pattern *regexp.Regexp) diag.Diagnostics {
+	if pattern == nil {
+		return nil
+	}
+	var role types.String
+	diags := plan.GetAttribute(ctx, path.Root("role"), &role)
+	if diags.HasError() || role.IsUnknown() || pattern.MatchString(role.ValueString()) {
+		return diags
+	}
+	diags.AddAttributeError(
+		path.Root("role"),
+		"Invalid role name",
+		fmt.Sprintf("Role %s does not match the role_name_pattern of the provider, %s, which enforces the naming conventions of the roles it manages.", role.ValueString(), pattern),
+	)
+	return diags
+}
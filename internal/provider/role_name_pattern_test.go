@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCheckRoleName(t *testing.T) {
+	ctx := context.Background()
+	pattern, err := compileRoleNamePattern(types.StringValue("svc_[a-z0-9_]+|app"))
+	if err != nil {
+		t.Fatalf("compileRoleNamePattern() error = %v", err)
+	}
+	if none, err := compileRoleNamePattern(types.StringNull()); none != nil || err != nil {
+		t.Errorf("compileRoleNamePattern(null) = %v, %v, want nil", none, err)
+	}
+
+	var schemaResp resource.SchemaResponse
+	NewLoginResource().Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	plan := func(role tftypes.Value) tfsdk.Plan {
+		values := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+		for name, attrType := range typ.AttributeTypes {
+			values[name] = tftypes.NewValue(attrType, nil)
+		}
+		values["role"] = role
+		return tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(typ, values)}
+	}
+
+	tests := map[string]bool{
+		"svc_billing":     true,
+		"app":             true,
+		"svc_":            false,
+		"legacy_svc_jobs": false,
+		"app_admin":       false,
+	}
+	for role, want := range tests {
+		diags := checkRoleName(ctx, plan(tftypes.NewValue(tftypes.String, role)), pattern)
+		if got := !diags.HasError(); got != want {
+			t.Errorf("checkRoleName(%q) = %v, want allowed %t", role, diags, want)
+		}
+	}
+	if diags := checkRoleName(ctx, plan(tftypes.NewValue(tftypes.String, tftypes.UnknownValue)), pattern); diags.HasError() {
+		t.Errorf("checkRoleName(unknown) error = %v", diags)
+	}
+	if diags := checkRoleName(ctx, plan(tftypes.NewValue(tftypes.String, "legacy")), nil); diags.HasError() {
+		t.Errorf("checkRoleName() without pattern error = %v", diags)
+	}
+}
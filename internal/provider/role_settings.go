@@ -0,0 +1,928 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = (*roleSettingsResource)(nil)
+	_ resource.ResourceWithConfigure      = (*roleSettingsResource)(nil)
+	_ resource.ResourceWithImportState    = (*roleSettingsResource)(nil)
+	_ resource.ResourceWithIdentity       = (*roleSettingsResource)(nil)
+	_ resource.ResourceWithModifyPlan     = (*roleSettingsResource)(nil)
+	_ resource.ResourceWithValidateConfig = (*roleSettingsResource)(nil)
+)
+
+// settingType is the Terraform type of a role setting attribute.
+type settingType int
+
+const (
+	settingString settingType = iota
+	settingInt64
+	settingFloat64
+	settingBool
+)
+
+// roleSetting describes a configuration parameter set on a role with
+// ALTER ROLE ... SET, and the resource attribute managing it.
+type roleSetting struct {
+	// Attribute is the name of the resource attribute.
+	Attribute string
+	// Parameter is the name of the configuration parameter.
+	Parameter   string
+	Description string
+	Type        settingType
+	// Unit is the default unit of parameters accepting values with units,
+	// e.g. "kB" for work_mem or "s" for tcp_keepalives_idle, so that values
+	// set in another unit, e.g. "1min", are recognized.
+	Unit string
+
+	StringValidators  []validator.String
+	Int64Validators   []validator.Int64
+	Float64Validators []validator.Float64
+}
+
+// roleSettingsResource manages one or more configuration parameters of a
+// role. Resources managing a single parameter require it; resources bundling
+// several parameters make them optional and RESET the ones left unset.
+//
+// Each resource is declared in its own file, e.g. bytea_output_resource.go.
+type roleSettingsResource struct {
+	// typeName is the resource type name without the provider prefix,
+	// e.g. "bytea_output".
+	typeName    string
+	description string
+	settings    []roleSetting
+
+	// check, if set, validates the values against the database before they
+	// are applied, adding errors to diags.
+	check func(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, values map[string]attr.Value) bool
+
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+	parameterGuard   parameterGuard
+}
+
+// roleSettingsState is the state of a roleSettingsResource, read attribute by
+// attribute since the setting attributes differ between resources.
+type roleSettingsState struct {
+	Role               string
+	Connection         types.String
+	Database           string
+	DeletionProtection bool
+	SkipResetOnDestroy bool
+	Retry              *retryModel
+	// FromCurrent are the attributes of the settings set FROM CURRENT.
+	FromCurrent []string
+	// Values are the setting values by attribute name, null when unset and
+	// unknown when set FROM CURRENT but not applied yet.
+	Values map[string]attr.Value
+}
+
+// attributeGetter is implemented by tfsdk.Plan, tfsdk.State and tfsdk.Config.
+type attributeGetter interface {
+	GetAttribute(ctx context.Context, path path.Path, target interface{}) diag.Diagnostics
+}
+
+// Metadata returns the resource type name.
+func (r *roleSettingsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.typeName
+}
+
+// Schema defines the schema for the resource.
+func (r *roleSettingsResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"role": schema.StringAttribute{
+			Description: "Name of the role.",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"connection":            connectionAttribute(),
+		"database":              databaseAttribute(),
+		"deletion_protection":   deletionProtectionAttribute(),
+		"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+		"sql":                   sqlAttribute(),
+		"retry":                 retryAttribute(),
+	}
+	names := make([]string, 0, len(r.settings))
+	required := len(r.settings) == 1
+	for _, setting := range r.settings {
+		attributes[setting.Attribute] = setting.schema(required)
+		names = append(names, setting.Attribute)
+	}
+	attributes["from_current"] = schema.SetAttribute{
+		Description: fmt.Sprintf("Setting attributes whose value is captured from the current value of the provider session with ALTER ROLE ... SET ... FROM CURRENT instead of configured, e.g. to snapshot tuned session parameters into role defaults. They are captured when first applied, or again if reset outside of Terraform, and their value is then read from the role. Elements must be among: %s.", strings.Join(names, ", ")),
+		ElementType: types.StringType,
+		Optional:    true,
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.OneOf(names...)),
+		},
+	}
+	resp.Schema = schema.Schema{
+		Description: r.description,
+		Attributes:  attributes,
+	}
+}
+
+func (s roleSetting) schema(required bool) schema.Attribute {
+	// Settings are computed when set FROM CURRENT, so a single setting is
+	// required by ValidateConfig instead
+	description := s.Description
+	if required {
+		description += " Required unless listed in from_current."
+	}
+	switch s.Type {
+	case settingInt64:
+		return schema.Int64Attribute{
+			Description: description,
+			Optional:    true,
+			Computed:    true,
+			Validators:  s.Int64Validators,
+		}
+	case settingFloat64:
+		return schema.Float64Attribute{
+			Description: description,
+			Optional:    true,
+			Computed:    true,
+			Validators:  s.Float64Validators,
+		}
+	case settingBool:
+		return schema.BoolAttribute{
+			Description: description,
+			Optional:    true,
+			Computed:    true,
+		}
+	default:
+		return schema.StringAttribute{
+			Description: description,
+			Optional:    true,
+			Computed:    true,
+			Validators:  s.StringValidators,
+		}
+	}
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *roleSettingsResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *roleSettingsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+	r.parameterGuard = data.parameterGuard
+}
+
+// ValidateConfig checks that settings set FROM CURRENT are not configured, and
+// that a single setting is either configured or set FROM CURRENT.
+func (r *roleSettingsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var fromCurrent types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("from_current"), &fromCurrent)...)
+	values, diags := r.getValues(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || fromCurrent.IsUnknown() {
+		return
+	}
+	var names []string
+	resp.Diagnostics.Append(fromCurrent.ElementsAs(ctx, &names, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, setting := range r.settings {
+		value := values[setting.Attribute]
+		switch {
+		case slices.Contains(names, setting.Attribute) && !value.IsNull():
+			resp.Diagnostics.AddAttributeError(
+				path.Root(setting.Attribute),
+				"Conflicting setting value",
+				fmt.Sprintf("%s is listed in from_current, so its value is captured from the provider session and cannot be configured.", setting.Attribute),
+			)
+		case len(r.settings) == 1 && !slices.Contains(names, setting.Attribute) && value.IsNull():
+			resp.Diagnostics.AddAttributeError(
+				path.Root(setting.Attribute),
+				"Missing setting value",
+				fmt.Sprintf("%s must be configured, or listed in from_current to capture it from the provider session.", setting.Attribute),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans the setting values and previews the SQL statements that
+// the apply will run.
+func (r *roleSettingsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.checkParameters(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role, database types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("database"), &database)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || database.IsUnknown() {
+		return
+	}
+	fromCurrent, ok := r.planValues(ctx, req, resp, role.ValueString(), database.ValueString())
+	if !ok {
+		return
+	}
+	values, diags := r.getValues(ctx, resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for name, value := range values {
+		if value.IsUnknown() && !slices.Contains(fromCurrent, name) {
+			return
+		}
+	}
+
+	sqlstr := r.sqlApply(role.ValueString(), database.ValueString(), values, fromCurrent)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+}
+
+// checkParameters returns an error on the attributes of the settings
+// configured or set FROM CURRENT in config whose parameter the provider does
+// not allow.
+func (r *roleSettingsResource) checkParameters(ctx context.Context, config attributeGetter) diag.Diagnostics {
+	var fromCurrent types.Set
+	diags := config.GetAttribute(ctx, path.Root("from_current"), &fromCurrent)
+	values, d := r.getValues(ctx, config)
+	diags.Append(d...)
+	var names []string
+	if !diags.HasError() && !fromCurrent.IsUnknown() {
+		diags.Append(fromCurrent.ElementsAs(ctx, &names, true)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+	for _, setting := range r.settings {
+		if !values[setting.Attribute].IsNull() || slices.Contains(names, setting.Attribute) {
+			diags.Append(r.parameterGuard.check(path.Root(setting.Attribute), setting.Parameter)...)
+		}
+	}
+	return diags
+}
+
+// planValues plans the setting values, which are computed for the settings
+// set FROM CURRENT: they keep their prior value, unless they are captured by
+// this apply. The other ones are the configured values, so that unset ones
+// are reset. It returns the attributes set FROM CURRENT, and false if the plan
+// is not known enough to do so.
+func (r *roleSettingsResource) planValues(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, role, database string) ([]string, bool) {
+	var connection types.String
+	var fromCurrent types.Set
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("connection"), &connection)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("from_current"), &fromCurrent)...)
+	if resp.Diagnostics.HasError() || fromCurrent.IsUnknown() {
+		return nil, false
+	}
+	var names []string
+	resp.Diagnostics.Append(fromCurrent.ElementsAs(ctx, &names, true)...)
+	config, diags := r.getValues(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return nil, false
+	}
+
+	var prior roleSettingsState
+	if !req.State.Raw.IsNull() {
+		prior, diags = r.get(ctx, req.State)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return nil, false
+		}
+	}
+
+	for _, setting := range r.settings {
+		value := config[setting.Attribute]
+		if slices.Contains(names, setting.Attribute) {
+			value = prior.Values[setting.Attribute]
+			captured := value != nil && !value.IsNull() && slices.Contains(prior.FromCurrent, setting.Attribute) &&
+				prior.Role == role && prior.Database == database && prior.Connection.Equal(connection)
+			if !captured {
+				value = setting.unknown()
+			}
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(setting.Attribute), value)...)
+	}
+	return names, !resp.Diagnostics.HasError()
+}
+
+// unknown returns the unknown value of the attribute type.
+func (s roleSetting) unknown() attr.Value {
+	switch s.Type {
+	case settingInt64:
+		return types.Int64Unknown()
+	case settingFloat64:
+		return types.Float64Unknown()
+	case settingBool:
+		return types.BoolUnknown()
+	default:
+		return types.StringUnknown()
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *roleSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan
+	plan, diags := r.get(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := r.sqlApply(plan.Role, plan.Database, plan.Values, plan.FromCurrent)
+	if !r.exec(ctx, &resp.Diagnostics, "create", plan, plan.Values, sqlstr) {
+		return
+	}
+
+	tflog.Info(ctx, "Applied role settings", map[string]any{
+		"role":     plan.Role,
+		"resource": r.typeName,
+	})
+
+	// Set state to fully populated data
+	resp.State.Raw = req.Plan.Raw
+	for _, name := range plan.FromCurrent {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), plan.Values[name])...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, plan.Connection, plan.Role, plan.Database)...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *roleSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	state, diags := r.get(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read the current values from the database
+	db, err := r.connect(state.Connection.ValueString(), state.Database).GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	values, err := r.read(ctx, db, &resp.Diagnostics, state.Role, state.Database)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query role settings",
+			fmt.Sprintf("Failed to query role settings for role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	// Overwrite the state with the actual values, keeping the configured form
+	// of equivalent ones, e.g. "1GB" for "1024MB"
+	for _, setting := range r.settings {
+		if setting.equivalent(state.Values[setting.Attribute], values[setting.Attribute]) {
+			values[setting.Attribute] = state.Values[setting.Attribute]
+		}
+		addDriftWarning(&resp.Diagnostics, state.Role, setting.Attribute, state.Values[setting.Attribute].String(), values[setting.Attribute].String())
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(setting.Attribute), values[setting.Attribute])...)
+	}
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, state.Connection, state.Role, state.Database)...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *roleSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve value from plan
+	plan, diags := r.get(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := r.sqlApply(plan.Role, plan.Database, plan.Values, plan.FromCurrent)
+	if !r.exec(ctx, &resp.Diagnostics, "update", plan, plan.Values, sqlstr) {
+		return
+	}
+
+	// Set state to updated value
+	resp.State.Raw = req.Plan.Raw
+	for _, name := range plan.FromCurrent {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), plan.Values[name])...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, plan.Connection, plan.Role, plan.Database)...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *roleSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	state, diags := r.get(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	r.exec(ctx, &resp.Diagnostics, "delete", state, nil, r.sqlReset(state.Role, state.Database))
+}
+
+func (r *roleSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, database, diags := importRoleDatabase(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	values, err := r.read(ctx, db, &resp.Diagnostics, role, database)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role settings",
+			fmt.Sprintf("Failed to query role settings for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	// A single setting is required, so that a role without it would be
+	// imported, and have its configuration generated, with a null value
+	if len(r.settings) == 1 && values[r.settings[0].Attribute].IsNull() {
+		scope := ""
+		if database != "" {
+			scope = " in database " + database
+		}
+		resp.Diagnostics.AddError(
+			"Setting not found",
+			fmt.Sprintf("Cannot import role %s: %s is not set for the role%s, create the resource instead", role, r.settings[0].Parameter, scope),
+		)
+		return
+	}
+
+	for _, setting := range r.settings {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(setting.Attribute), values[setting.Attribute])...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	if database != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database"), database)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), r.sqlApply(role, database, values, nil))...)
+	resp.Diagnostics.Append(setRoleDatabaseIdentity(ctx, resp.Identity, connection, role, database)...)
+}
+
+// get reads the common attributes and the setting values from a plan or
+// state.
+func (r *roleSettingsResource) get(ctx context.Context, src attributeGetter) (roleSettingsState, diag.Diagnostics) {
+	var s roleSettingsState
+	var diags diag.Diagnostics
+	var database types.String
+	diags.Append(src.GetAttribute(ctx, path.Root("role"), &s.Role)...)
+	diags.Append(src.GetAttribute(ctx, path.Root("connection"), &s.Connection)...)
+	diags.Append(src.GetAttribute(ctx, path.Root("database"), &database)...)
+	s.Database = database.ValueString()
+	diags.Append(src.GetAttribute(ctx, path.Root("deletion_protection"), &s.DeletionProtection)...)
+	diags.Append(src.GetAttribute(ctx, path.Root("skip_reset_on_destroy"), &s.SkipResetOnDestroy)...)
+	diags.Append(src.GetAttribute(ctx, path.Root("retry"), &s.Retry)...)
+	diags.Append(src.GetAttribute(ctx, path.Root("from_current"), &s.FromCurrent)...)
+	values, d := r.getValues(ctx, src)
+	diags.Append(d...)
+	s.Values = values
+	return s, diags
+}
+
+// getValues reads the setting values from a plan or state.
+func (r *roleSettingsResource) getValues(ctx context.Context, src attributeGetter) (map[string]attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	values := make(map[string]attr.Value, len(r.settings))
+	for _, setting := range r.settings {
+		var value attr.Value
+		switch setting.Type {
+		case settingInt64:
+			var v types.Int64
+			diags.Append(src.GetAttribute(ctx, path.Root(setting.Attribute), &v)...)
+			value = v
+		case settingFloat64:
+			var v types.Float64
+			diags.Append(src.GetAttribute(ctx, path.Root(setting.Attribute), &v)...)
+			value = v
+		case settingBool:
+			var v types.Bool
+			diags.Append(src.GetAttribute(ctx, path.Root(setting.Attribute), &v)...)
+			value = v
+		default:
+			var v types.String
+			diags.Append(src.GetAttribute(ctx, path.Root(setting.Attribute), &v)...)
+			value = v
+		}
+		values[setting.Attribute] = value
+	}
+	return values, diags
+}
+
+// exec runs sqlstr for the role of s during operation, e.g. "create", adding
+// any error to diags. Unless nil, values are checked before: the connecting
+// role must be allowed to set them, and they must pass the check of the
+// resource. The values set FROM CURRENT are read back into values once applied.
+func (r *roleSettingsResource) exec(ctx context.Context, diags *diag.Diagnostics, operation string, s roleSettingsState, values map[string]attr.Value, sqlstr string) bool {
+	db, err := r.connect(s.Connection.ValueString(), s.Database).GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(s.Retry)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return false
+	}
+	if !checkPrivileges(ctx, db, diags, s.Role, "") {
+		return false
+	}
+	if values != nil {
+		for _, setting := range r.settings {
+			if value := values[setting.Attribute]; value.IsNull() {
+				continue
+			}
+			if !checkParameterPrivilege(ctx, db, diags, setting.Attribute, setting.Parameter) {
+				return false
+			}
+		}
+		// Values captured from the session are unknown, but valid
+		if r.check != nil && len(s.FromCurrent) == 0 && !r.check(ctx, db, diags, values) {
+			return false
+		}
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(diags, operation, s.Role, err)
+		return false
+	}
+	if values != nil && len(s.FromCurrent) > 0 {
+		actual, err := r.read(ctx, db, diags, s.Role, s.Database)
+		if err != nil {
+			diags.AddError(
+				"Failed to query role settings",
+				fmt.Sprintf("Failed to query role settings for role %s: %s", s.Role, err)+sqlErrorDetails(s.Role, err),
+			)
+			return false
+		}
+		for _, name := range s.FromCurrent {
+			values[name] = actual[name]
+		}
+	}
+	if values != nil && r.verifyWrites {
+		actual, err := r.read(ctx, db, diags, s.Role, s.Database)
+		var discrepancies []string
+		for _, setting := range r.settings {
+			if applied := values[setting.Attribute]; err == nil && !setting.equivalent(applied, actual[setting.Attribute]) {
+				discrepancies = append(discrepancies, fmt.Sprintf("%s: applied %s, found %s", setting.Attribute, applied, actual[setting.Attribute]))
+			}
+		}
+		return verifyWrite(diags, s.Role, err, discrepancies...)
+	}
+	return true
+}
+
+// read returns the setting values of the role in database, or in all
+// databases if empty, null for unset ones, or sql.ErrNoRows if the role does
+// not exist.
+//
+// Values that cannot be represented by their attribute, e.g. set outside of
+// Terraform in an unexpected form, are read as null with a warning added to
+// diags, so that they show as drift instead of failing the refresh.
+func (r *roleSettingsResource) read(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, role, database string) (map[string]attr.Value, error) {
+	set, err := readRoleDatabaseConfig(ctx, db, role, database)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]attr.Value, len(r.settings))
+	for _, setting := range r.settings {
+		raw, ok := set[strings.ToLower(setting.Parameter)]
+		value, err := setting.parse(raw, ok)
+		if err != nil {
+			diags.AddAttributeWarning(
+				path.Root(setting.Attribute),
+				"Unrecognized setting value",
+				fmt.Sprintf("The %s of role %s is set to a value the provider does not recognize, it is overwritten by the next apply: %s.", setting.Attribute, role, err),
+			)
+			value, _ = setting.parse("", false)
+		}
+		values[setting.Attribute] = value
+	}
+	return values, nil
+}
+
+// parse converts a value found in rolconfig to the attribute type.
+func (s roleSetting) parse(raw string, ok bool) (attr.Value, error) {
+	switch s.Type {
+	case settingInt64:
+		if !ok {
+			return types.Int64Null(), nil
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil && s.Unit != "" {
+			// Values set in another unit, e.g. "1min" for 60 seconds
+			if f, ok := convertUnit(raw, s.Unit); ok && f == math.Trunc(f) {
+				return types.Int64Value(int64(f)), nil
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", s.Parameter, raw, err)
+		}
+		return types.Int64Value(v), nil
+	case settingFloat64:
+		if !ok {
+			return types.Float64Null(), nil
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", s.Parameter, raw, err)
+		}
+		return types.Float64Value(v), nil
+	case settingBool:
+		if !ok {
+			return types.BoolNull(), nil
+		}
+		v, err := parseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", s.Parameter, raw, err)
+		}
+		return types.BoolValue(v), nil
+	default:
+		if !ok {
+			return types.StringNull(), nil
+		}
+		return types.StringValue(raw), nil
+	}
+}
+
+// equivalent reports whether two values of the setting are equal, or are the
+// same quantity in different units, e.g. "1GB" and "1024MB".
+func (s roleSetting) equivalent(a, b attr.Value) bool {
+	if a.Equal(b) {
+		return true
+	}
+	x, okX := a.(types.String)
+	y, okY := b.(types.String)
+	if s.Unit == "" || !okX || !okY || x.IsNull() || y.IsNull() || x.IsUnknown() || y.IsUnknown() {
+		return false
+	}
+	return sameQuantity(x.ValueString(), y.ValueString(), s.Unit)
+}
+
+// sameQuantity reports whether a and b, values of a parameter whose default
+// unit is unit, are the same quantity, e.g. "1min" and "60s".
+func sameQuantity(a, b, unit string) bool {
+	x, okX := convertUnit(a, unit)
+	y, okY := convertUnit(b, unit)
+	return okX && okY && x == y
+}
+
+// unitFactors are the units of PostgreSQL parameters, as multiples of the
+// smallest unit of their kind: bytes for memory and microseconds for time.
+var unitFactors = []map[string]float64{
+	{"B": 1, "kB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30, "TB": 1 << 40},
+	{"us": 1, "ms": 1e3, "s": 1e6, "min": 60e6, "h": 3600e6, "d": 86400e6},
+}
+
+// unitValueRe matches a parameter value with an optional unit, e.g. "64MB".
+var unitValueRe = regexp.MustCompile(`^\s*(-?[0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)\s*$`)
+
+// convertUnit converts raw, a value in unit if it has none, to a number of
+// unit, e.g. "1min" to 60 for "s". ok is false unless raw is a number with an
+// optional unit of the same kind as unit.
+func convertUnit(raw, unit string) (float64, bool) {
+	m := unitValueRe.FindStringSubmatch(raw)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if m[2] == "" {
+		return v, true
+	}
+	for _, factors := range unitFactors {
+		from, okFrom := factors[m[2]]
+		to, okTo := factors[unit]
+		if okFrom && okTo {
+			return v * from / to, true
+		}
+	}
+	return 0, false
+}
+
+// parseBool parses a PostgreSQL boolean parameter value: on, off, 1, 0 or
+// a prefix of true, false, yes or no.
+func parseBool(s string) (bool, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "on", s == "1":
+		return true, nil
+	case s == "off", s == "0":
+		return false, nil
+	case s == "":
+	case strings.HasPrefix("true", s), strings.HasPrefix("yes", s):
+		return true, nil
+	case strings.HasPrefix("false", s), strings.HasPrefix("no", s):
+		return false, nil
+	}
+	return false, errors.New("not a boolean")
+}
+
+// literal returns the SQL literal of a known, non-null setting value.
+func (s roleSetting) literal(value attr.Value) string {
+	switch v := value.(type) {
+	case types.Int64:
+		return strconv.FormatInt(v.ValueInt64(), 10)
+	case types.Float64:
+		return strconv.FormatFloat(v.ValueFloat64(), 'g', -1, 64)
+	case types.Bool:
+		if v.ValueBool() {
+			return "on"
+		}
+		return "off"
+	case types.String:
+		return pq.QuoteLiteral(v.ValueString())
+	}
+	panic(fmt.Sprintf("unexpected value type %T", value))
+}
+
+// sqlApply returns the statements setting the values of the role in
+// database, or in all databases if empty, setting the attributes in
+// fromCurrent FROM CURRENT and resetting the unset ones. They run as a single
+// implicit transaction.
+func (r *roleSettingsResource) sqlApply(role, database string, values map[string]attr.Value, fromCurrent []string) string {
+	statements := make([]string, 0, len(r.settings))
+	for _, setting := range r.settings {
+		value := values[setting.Attribute]
+		if slices.Contains(fromCurrent, setting.Attribute) {
+			statements = append(statements, sqlSetRoleSettingFromCurrent(role, database, setting.Parameter))
+			continue
+		}
+		if value == nil || value.IsNull() {
+			statements = append(statements, sqlResetRoleSetting(role, database, setting.Parameter))
+			continue
+		}
+		statements = append(statements, sqlSetRoleSetting(role, database, setting.Parameter, setting.literal(value)))
+	}
+	return strings.Join(statements, "\n")
+}
+
+// sqlReset returns the statements resetting all settings of the role in
+// database, or in all databases if empty.
+func (r *roleSettingsResource) sqlReset(role, database string) string {
+	statements := make([]string, 0, len(r.settings))
+	for _, setting := range r.settings {
+		statements = append(statements, sqlResetRoleSetting(role, database, setting.Parameter))
+	}
+	return strings.Join(statements, "\n")
+}
+
+func sqlSetRoleSetting(role, database, parameter, literal string) string {
+	return fmt.Sprintf("%s SET %s = %s;", sqlAlterRole(role, database), parameter, literal)
+}
+
+func sqlSetRoleSettingFromCurrent(role, database, parameter string) string {
+	return fmt.Sprintf("%s SET %s FROM CURRENT;", sqlAlterRole(role, database), parameter)
+}
+
+func sqlResetRoleSetting(role, database, parameter string) string {
+	return fmt.Sprintf("%s RESET %s;", sqlAlterRole(role, database), parameter)
+}
+
+// requireExtension returns a check failing unless extension is installed in
+// the database, for settings that have no effect without it.
+func requireExtension(extension string) func(context.Context, *sql.DB, *diag.Diagnostics, map[string]attr.Value) bool {
+	return func(ctx context.Context, db *sql.DB, diags *diag.Diagnostics, _ map[string]attr.Value) bool {
+		var installed bool
+		if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = $1);", extension).Scan(&installed); err != nil {
+			diags.AddError(
+				"Failed to query extensions",
+				"Failed to query extensions: "+err.Error(),
+			)
+			return false
+		}
+		if !installed {
+			diags.AddError(
+				"Extension not installed",
+				fmt.Sprintf("The %s extension is not installed in the database, so these settings would have no effect. Install it first, e.g.: CREATE EXTENSION %s;", extension, extension),
+			)
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,485 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    bool
+		wantErr bool
+	}{
+		{"on", true, false},
+		{"off", false, false},
+		{"true", true, false},
+		{"t", true, false},
+		{"YES", true, false},
+		{"f", false, false},
+		{"no", false, false},
+		{"1", true, false},
+		{"0", false, false},
+		{"o", false, true},
+		{"", false, true},
+		{"maybe", false, true},
+	}
+	for _, tt := range tests {
+		got, err := parseBool(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseBool(%q) = %t, %v, want %t, error %t", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRoleSettingsSQL(t *testing.T) {
+	r := &roleSettingsResource{
+		settings: []roleSetting{
+			{Attribute: "text", Parameter: "application_name", Type: settingString},
+			{Attribute: "number", Parameter: "extra_float_digits", Type: settingInt64},
+			{Attribute: "ratio", Parameter: "hash_mem_multiplier", Type: settingFloat64},
+			{Attribute: "flag", Parameter: "check_function_bodies", Type: settingBool},
+		},
+	}
+	values := map[string]attr.Value{
+		"text":   types.StringValue("it's"),
+		"number": types.Int64Value(-2),
+		"ratio":  types.Float64Value(1.5),
+		"flag":   types.BoolNull(),
+	}
+
+	want := `ALTER ROLE "app" SET application_name = 'it''s';
+ALTER ROLE "app" SET extra_float_digits = -2;
+ALTER ROLE "app" SET hash_mem_multiplier = 1.5;
+ALTER ROLE "app" RESET check_function_bodies;`
+	if got := r.sqlApply("app", "", values, nil); got != want {
+		t.Errorf("sqlApply() = %q, want %q", got, want)
+	}
+
+	want = `ALTER ROLE "app" RESET application_name;
+ALTER ROLE "app" RESET extra_float_digits;
+ALTER ROLE "app" RESET hash_mem_multiplier;
+ALTER ROLE "app" RESET check_function_bodies;`
+	if got := r.sqlReset("app", ""); got != want {
+		t.Errorf("sqlReset() = %q, want %q", got, want)
+	}
+
+	want = `ALTER ROLE "app" IN DATABASE "my db" RESET application_name;
+ALTER ROLE "app" IN DATABASE "my db" RESET extra_float_digits;
+ALTER ROLE "app" IN DATABASE "my db" RESET hash_mem_multiplier;
+ALTER ROLE "app" IN DATABASE "my db" RESET check_function_bodies;`
+	if got := r.sqlReset("app", "my db"); got != want {
+		t.Errorf("sqlReset() = %q, want %q", got, want)
+	}
+}
+
+func TestRoleSettingsFromCurrent(t *testing.T) {
+	ctx := context.Background()
+	r := NewByteaOutputResource().(*roleSettingsResource)
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	raw := func(values map[string]tftypes.Value) tftypes.Value {
+		all := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+		for name, attrType := range typ.AttributeTypes {
+			all[name] = tftypes.NewValue(attrType, nil)
+		}
+		for name, value := range values {
+			all[name] = value
+		}
+		return tftypes.NewValue(typ, all)
+	}
+	fromCurrent := tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "format")})
+
+	validate := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   string
+	}{
+		{
+			name:   "configured",
+			config: map[string]tftypes.Value{"format": tftypes.NewValue(tftypes.String, "escape")},
+		},
+		{
+			name:   "from current",
+			config: map[string]tftypes.Value{"from_current": fromCurrent},
+		},
+		{
+			name:   "both",
+			config: map[string]tftypes.Value{"format": tftypes.NewValue(tftypes.String, "escape"), "from_current": fromCurrent},
+			want:   "Conflicting setting value",
+		},
+		{
+			name: "neither",
+			want: "Missing setting value",
+		},
+	}
+	for _, tt := range validate {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp resource.ValidateConfigResponse
+			r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw(tt.config)}}, &resp)
+			if tt.want == "" {
+				if resp.Diagnostics.HasError() {
+					t.Errorf("ValidateConfig() error = %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != tt.want {
+				t.Errorf("ValidateConfig() = %v, want %q", resp.Diagnostics, tt.want)
+			}
+		})
+	}
+
+	// The value is captured on create, and kept afterwards
+	config := raw(map[string]tftypes.Value{"role": tftypes.NewValue(tftypes.String, "app"), "from_current": fromCurrent})
+	plan := func(state tftypes.Value) *resource.ModifyPlanResponse {
+		resp := &resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: config}}
+		r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+			Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: config},
+			State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("ModifyPlan() error = %v", resp.Diagnostics)
+		}
+		return resp
+	}
+	resp := plan(tftypes.NewValue(typ, nil))
+	var format, sqlstr types.String
+	resp.Plan.GetAttribute(ctx, path.Root("format"), &format)
+	resp.Plan.GetAttribute(ctx, path.Root("sql"), &sqlstr)
+	if !format.IsUnknown() {
+		t.Errorf("ModifyPlan() on create format = %s, want unknown", format)
+	}
+	if want := `ALTER ROLE "app" SET bytea_output FROM CURRENT;`; sqlstr.ValueString() != want {
+		t.Errorf("ModifyPlan() sql = %q, want %q", sqlstr.ValueString(), want)
+	}
+
+	resp = plan(raw(map[string]tftypes.Value{
+		"role":                  tftypes.NewValue(tftypes.String, "app"),
+		"format":                tftypes.NewValue(tftypes.String, "hex"),
+		"from_current":          fromCurrent,
+		"deletion_protection":   tftypes.NewValue(tftypes.Bool, false),
+		"skip_reset_on_destroy": tftypes.NewValue(tftypes.Bool, false),
+	}))
+	resp.Plan.GetAttribute(ctx, path.Root("format"), &format)
+	if format.ValueString() != "hex" {
+		t.Errorf("ModifyPlan() on update format = %s, want the captured \"hex\"", format)
+	}
+}
+
+func TestConvertUnit(t *testing.T) {
+	tests := []struct {
+		raw    string
+		unit   string
+		want   float64
+		wantOK bool
+	}{
+		{"30", "s", 30, true},
+		{"1min", "s", 60, true},
+		{"1500ms", "s", 1.5, true},
+		{"64MB", "kB", 65536, true},
+		{"65536kB", "kB", 65536, true},
+		{"1GB", "kB", 1 << 20, true},
+		{"-1", "kB", -1, true},
+		{"1min", "kB", 0, false},
+		{"64XB", "kB", 0, false},
+		{"fast", "s", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := convertUnit(tt.raw, tt.unit)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("convertUnit(%q, %q) = %v, %t, want %v, %t", tt.raw, tt.unit, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRoleSettingParseUnits(t *testing.T) {
+	seconds := roleSetting{Attribute: "idle", Parameter: "tcp_keepalives_idle", Type: settingInt64, Unit: "s"}
+	if got, err := seconds.parse("2min", true); err != nil || !got.Equal(types.Int64Value(120)) {
+		t.Errorf("parse(2min) = %v, %v, want 120", got, err)
+	}
+	if _, err := seconds.parse("1500ms", true); err == nil {
+		t.Error("parse(1500ms) succeeded, want error for a fractional number of seconds")
+	}
+
+	plain := roleSetting{Attribute: "geqo_threshold", Parameter: "geqo_threshold", Type: settingInt64}
+	if _, err := plain.parse("12abc", true); err == nil {
+		t.Error("parse(12abc) succeeded without unit, want error")
+	}
+}
+
+func TestRoleSettingEquivalent(t *testing.T) {
+	memory := roleSetting{Attribute: "work_mem", Parameter: "work_mem", Unit: "kB"}
+	tests := []struct {
+		a, b attr.Value
+		want bool
+	}{
+		{types.StringValue("64MB"), types.StringValue("64MB"), true},
+		{types.StringValue("64MB"), types.StringValue("65536kB"), true},
+		{types.StringValue("64MB"), types.StringValue("65536"), true},
+		{types.StringValue("64MB"), types.StringValue("32MB"), false},
+		{types.StringValue("64MB"), types.StringNull(), false},
+		{types.StringNull(), types.StringNull(), true},
+	}
+	for _, tt := range tests {
+		if got := memory.equivalent(tt.a, tt.b); got != tt.want {
+			t.Errorf("equivalent(%s, %s) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	// Without unit, values are compared as is
+	plain := roleSetting{Attribute: "bytea_output", Parameter: "bytea_output"}
+	if plain.equivalent(types.StringValue("1MB"), types.StringValue("1024kB")) {
+		t.Error("equivalent() without unit = true, want false")
+	}
+}
+
+func TestRoleSettingsReadUnrecognized(t *testing.T) {
+	ctx := context.Background()
+	r := NewTCPKeepalivesResource().(*roleSettingsResource)
+	fake := fakedb.New().ExpectQuery(`SELECT rolconfig FROM pg_roles`, []string{"rolconfig"},
+		[]driver.Value{[]byte(`{tcp_keepalives_idle=1min,tcp_keepalives_count=lots}`)},
+	)
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	var diags diag.Diagnostics
+	values, err := r.read(ctx, db, &diags, "app", "")
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if !values["idle"].Equal(types.Int64Value(60)) {
+		t.Errorf("read() idle = %s, want 60", values["idle"])
+	}
+	if !values["count"].IsNull() || diags.WarningsCount() != 1 {
+		t.Errorf("read() count = %s with %d warnings, want null with a warning", values["count"], diags.WarningsCount())
+	}
+}
+
+func TestSameQuantity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1min", "60s", true},
+		{"60000", "1min", true},
+		{"0s", "0", true},
+		{"30s", "1min", false},
+		{"30s", "soon", false},
+	}
+	for _, tt := range tests {
+		if got := sameQuantity(tt.a, tt.b, "ms"); got != tt.want {
+			t.Errorf("sameQuantity(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRoleSettingsResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resource  func() resource.Resource
+		rolconfig map[string]string
+		want      string
+	}{
+		{
+			name:      "backslash_quote_safety",
+			resource:  NewBackslashQuoteSafetyResource,
+			rolconfig: map[string]string{"backslash_quote": "safe_encoding", "escape_string_warning": "off"},
+			want: `ALTER ROLE "app" RESET standard_conforming_strings;
+ALTER ROLE "app" SET backslash_quote = 'safe_encoding';
+ALTER ROLE "app" SET escape_string_warning = off;`,
+		},
+		{
+			name:      "bytea_output",
+			resource:  NewByteaOutputResource,
+			rolconfig: map[string]string{"bytea_output": "escape"},
+			want:      `ALTER ROLE "app" SET bytea_output = 'escape';`,
+		},
+		{
+			name:      "check_function_bodies",
+			resource:  NewCheckFunctionBodiesResource,
+			rolconfig: map[string]string{"check_function_bodies": "false"},
+			want:      `ALTER ROLE "app" SET check_function_bodies = off;`,
+		},
+		{
+			name:      "client_encoding",
+			resource:  NewClientEncodingResource,
+			rolconfig: map[string]string{"client_encoding": "LATIN1"},
+			want:      `ALTER ROLE "app" SET client_encoding = 'LATIN1';`,
+		},
+		{
+			name:      "collapse_limits",
+			resource:  NewCollapseLimitsResource,
+			rolconfig: map[string]string{"from_collapse_limit": "16", "join_collapse_limit": "12"},
+			want: `ALTER ROLE "app" SET from_collapse_limit = 16;
+ALTER ROLE "app" SET join_collapse_limit = 12;`,
+		},
+		{
+			name:      "constraint_exclusion",
+			resource:  NewConstraintExclusionResource,
+			rolconfig: map[string]string{"constraint_exclusion": "on"},
+			want:      `ALTER ROLE "app" SET constraint_exclusion = 'on';`,
+		},
+		{
+			name:      "default_transaction_deferrable",
+			resource:  NewDefaultTransactionDeferrableResource,
+			rolconfig: map[string]string{"default_transaction_deferrable": "on"},
+			want:      `ALTER ROLE "app" SET default_transaction_deferrable = on;`,
+		},
+		{
+			name:      "effective_io_concurrency",
+			resource:  NewEffectiveIOConcurrencyResource,
+			rolconfig: map[string]string{"effective_io_concurrency": "200"},
+			want: `ALTER ROLE "app" SET effective_io_concurrency = 200;
+ALTER ROLE "app" RESET maintenance_io_concurrency;`,
+		},
+		{
+			name:      "extra_float_digits",
+			resource:  NewExtraFloatDigitsResource,
+			rolconfig: map[string]string{"extra_float_digits": "-2"},
+			want:      `ALTER ROLE "app" SET extra_float_digits = -2;`,
+		},
+		{
+			name:      "geqo_settings",
+			resource:  NewGEQOSettingsResource,
+			rolconfig: map[string]string{"geqo_threshold": "14", "geqo_selection_bias": "1.8"},
+			want: `ALTER ROLE "app" RESET geqo;
+ALTER ROLE "app" SET geqo_threshold = 14;
+ALTER ROLE "app" RESET geqo_effort;
+ALTER ROLE "app" RESET geqo_pool_size;
+ALTER ROLE "app" RESET geqo_generations;
+ALTER ROLE "app" SET geqo_selection_bias = 1.8;
+ALTER ROLE "app" RESET geqo_seed;`,
+		},
+		{
+			name:      "intervalstyle",
+			resource:  NewIntervalStyleResource,
+			rolconfig: map[string]string{"IntervalStyle": "iso_8601"},
+			want:      `ALTER ROLE "app" SET IntervalStyle = 'iso_8601';`,
+		},
+		{
+			name:      "lc_settings",
+			resource:  NewLCSettingsResource,
+			rolconfig: map[string]string{"lc_monetary": "C", "lc_numeric": "C"},
+			want: `ALTER ROLE "app" RESET lc_messages;
+ALTER ROLE "app" SET lc_monetary = 'C';
+ALTER ROLE "app" SET lc_numeric = 'C';
+ALTER ROLE "app" RESET lc_time;`,
+		},
+		{
+			name:      "parallel_query_settings",
+			resource:  NewParallelQuerySettingsResource,
+			rolconfig: map[string]string{"max_parallel_workers_per_gather": "4", "min_parallel_table_scan_size": "64MB"},
+			want: `ALTER ROLE "app" SET max_parallel_workers_per_gather = 4;
+ALTER ROLE "app" RESET parallel_setup_cost;
+ALTER ROLE "app" RESET parallel_tuple_cost;
+ALTER ROLE "app" SET min_parallel_table_scan_size = '64MB';`,
+		},
+		{
+			name:      "pg_stat_statements_settings",
+			resource:  NewPgStatStatementsSettingsResource,
+			rolconfig: map[string]string{"pg_stat_statements.track": "all"},
+			want:      `ALTER ROLE "app" SET pg_stat_statements.track = 'all';`,
+		},
+		{
+			name:      "statement_memory_limits",
+			resource:  NewStatementMemoryLimitsResource,
+			rolconfig: map[string]string{"work_mem": "64MB", "hash_mem_multiplier": "2.5", "temp_file_limit": "-1"},
+			want: `ALTER ROLE "app" SET work_mem = '64MB';
+ALTER ROLE "app" SET hash_mem_multiplier = 2.5;
+ALTER ROLE "app" SET temp_file_limit = '-1';`,
+		},
+		{
+			name:      "tcp_keepalives",
+			resource:  NewTCPKeepalivesResource,
+			rolconfig: map[string]string{"tcp_keepalives_idle": "1min", "tcp_keepalives_interval": "10", "tcp_keepalives_count": "6"},
+			want: `ALTER ROLE "app" SET tcp_keepalives_idle = 60;
+ALTER ROLE "app" SET tcp_keepalives_interval = 10;
+ALTER ROLE "app" SET tcp_keepalives_count = 6;`,
+		},
+		{
+			name:      "track_settings",
+			resource:  NewTrackSettingsResource,
+			rolconfig: map[string]string{"track_functions": "pl", "track_io_timing": "on"},
+			want: `ALTER ROLE "app" RESET track_activities;
+ALTER ROLE "app" SET track_functions = 'pl';
+ALTER ROLE "app" SET track_io_timing = on;
+ALTER ROLE "app" RESET track_wal_io_timing;`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.resource().(*roleSettingsResource)
+			values := make(map[string]attr.Value, len(r.settings))
+			for _, setting := range r.settings {
+				raw, ok := tt.rolconfig[setting.Parameter]
+				value, err := setting.parse(raw, ok)
+				if err != nil {
+					t.Fatalf("parse(%q) error = %v", raw, err)
+				}
+				values[setting.Attribute] = value
+			}
+			if got := r.sqlApply("app", "", values, nil); got != tt.want {
+				t.Errorf("sqlApply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckClientEncoding(t *testing.T) {
+	ctx := context.Background()
+	for _, supported := range []bool{true, false} {
+		fake := fakedb.New().ExpectQuery(`pg_char_to_encoding`, []string{"supported"}, []driver.Value{supported})
+		db, _ := fake.GetDB(ctx)
+
+		var diags diag.Diagnostics
+		got := checkClientEncoding(ctx, db, &diags, map[string]attr.Value{"encoding": types.StringValue("KLINGON")})
+		db.Close()
+		if got != supported || diags.HasError() == supported {
+			t.Errorf("checkClientEncoding() = %t with %v, want %t", got, diags, supported)
+		}
+		if !supported && diags[0].Summary() != "Unsupported client encoding" {
+			t.Errorf("checkClientEncoding() error = %q, want Unsupported client encoding", diags[0].Summary())
+		}
+	}
+}
+
+func TestRequireExtension(t *testing.T) {
+	ctx := context.Background()
+	for _, installed := range []bool{true, false} {
+		fake := fakedb.New().ExpectQuery(`FROM pg_extension`, []string{"exists"}, []driver.Value{installed})
+		db, _ := fake.GetDB(ctx)
+
+		var diags diag.Diagnostics
+		got := requireExtension("pg_stat_statements")(ctx, db, &diags, nil)
+		db.Close()
+		if got != installed || diags.HasError() == installed {
+			t.Errorf("requireExtension() = %t with %v, want %t", got, diags, installed)
+		}
+		if !installed && !strings.Contains(diags[0].Detail(), "CREATE EXTENSION pg_stat_statements;") {
+			t.Errorf("requireExtension() error = %q, want the statement installing the extension", diags[0].Detail())
+		}
+	}
+
+	fake := fakedb.New().ExpectError(`FROM pg_extension`, errors.New("connection reset"))
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+	var diags diag.Diagnostics
+	if requireExtension("timescaledb")(ctx, db, &diags, nil) || !diags.HasError() {
+		t.Errorf("requireExtension() on a failed query = true with %v, want an error", diags)
+	}
+}
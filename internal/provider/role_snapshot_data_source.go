@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*roleSnapshotDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*roleSnapshotDataSource)(nil)
+)
+
+// NewRoleSnapshotDataSource is a helper function to simplify the provider implementation.
+func NewRoleSnapshotDataSource() datasource.DataSource {
+	return &roleSnapshotDataSource{}
+}
+
+type roleSnapshotDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type roleSnapshotDataSourceModel struct {
+	Role       types.String `tfsdk:"role"`
+	Connection types.String `tfsdk:"connection"`
+	JSON       types.String `tfsdk:"json"`
+}
+
+// roleSnapshot is the normalized definition of a role. It leaves out OIDs
+// and passwords, so that snapshots of the same role in different
+// environments are equal.
+type roleSnapshot struct {
+	Name           string                       `json:"name"`
+	Attributes     roleSnapshotAttributes       `json:"attributes"`
+	Config         map[string]string            `json:"config"`
+	DatabaseConfig map[string]map[string]string `json:"database_config"`
+	MemberOf       []roleSnapshotMembership     `json:"member_of"`
+}
+
+type roleSnapshotAttributes struct {
+	Superuser       bool    `json:"superuser"`
+	Inherit         bool    `json:"inherit"`
+	CreateRole      bool    `json:"create_role"`
+	CreateDatabase  bool    `json:"create_database"`
+	Login           bool    `json:"login"`
+	Replication     bool    `json:"replication"`
+	BypassRLS       bool    `json:"bypass_rls"`
+	ConnectionLimit int64   `json:"connection_limit"`
+	ValidUntil      *string `json:"valid_until"`
+}
+
+type roleSnapshotMembership struct {
+	Role        string `json:"role"`
+	AdminOption bool   `json:"admin_option"`
+}
+
+// Metadata returns the data source type name.
+func (d *roleSnapshotDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_snapshot"
+}
+
+// Schema defines the schema for the data source.
+func (d *roleSnapshotDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Renders a normalized JSON snapshot of the full definition of a role: its attributes, its configuration parameters, including database-specific ones, and the roles it is a member of.
+
+The snapshot leaves out OIDs and passwords, and sorts keys and memberships, so that it can be diffed between environments or exported to compliance tooling.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the role through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+			},
+			"json": schema.StringAttribute{
+				Description: "The snapshot of the role, as JSON.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *roleSnapshotDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *roleSnapshotDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data roleSnapshotDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role := data.Role.ValueString()
+	snapshot, err := readRoleSnapshot(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Role %s does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role",
+			fmt.Sprintf("Failed to query role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to encode snapshot",
+			fmt.Sprintf("Failed to encode snapshot of role %s: %s", role, err),
+		)
+		return
+	}
+	data.JSON = types.StringValue(string(b))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readRoleSnapshot returns the snapshot of role, or sql.ErrNoRows if it does
+// not exist.
+func readRoleSnapshot(ctx context.Context, db *sql.DB, role string) (roleSnapshot, error) {
+	s := roleSnapshot{
+		Name:           role,
+		DatabaseConfig: map[string]map[string]string{},
+		MemberOf:       []roleSnapshotMembership{},
+	}
+
+	var validUntil sql.NullString
+	err := db.QueryRowContext(ctx, `SELECT rolsuper, rolinherit, rolcreaterole, rolcreatedb, rolcanlogin, rolreplication, rolbypassrls, rolconnlimit, to_char(rolvaliduntil AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+FROM pg_roles WHERE rolname = $1;`, role).Scan(
+		&s.Attributes.Superuser,
+		&s.Attributes.Inherit,
+		&s.Attributes.CreateRole,
+		&s.Attributes.CreateDatabase,
+		&s.Attributes.Login,
+		&s.Attributes.Replication,
+		&s.Attributes.BypassRLS,
+		&s.Attributes.ConnectionLimit,
+		&validUntil,
+	)
+	if err != nil {
+		return s, err
+	}
+	if validUntil.Valid {
+		s.Attributes.ValidUntil = &validUntil.String
+	}
+
+	if s.Config, err = readRoleConfig(ctx, db, role); err != nil {
+		return s, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT d.datname, s.setconfig
+FROM pg_db_role_setting s
+JOIN pg_roles r ON r.oid = s.setrole
+JOIN pg_database d ON d.oid = s.setdatabase
+WHERE r.rolname = $1;`, role)
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var database string
+		var config pq.StringArray
+		if err := rows.Scan(&database, &config); err != nil {
+			return s, err
+		}
+		s.DatabaseConfig[database] = parseRoleConfig(config)
+	}
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	// Since PostgreSQL 16, a membership can be granted by several grantors
+	rows, err = db.QueryContext(ctx, `SELECT g.rolname, bool_or(m.admin_option)
+FROM pg_auth_members m
+JOIN pg_roles r ON r.oid = m.member
+JOIN pg_roles g ON g.oid = m.roleid
+WHERE r.rolname = $1
+GROUP BY g.rolname
+ORDER BY g.rolname;`, role)
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m roleSnapshotMembership
+		if err := rows.Scan(&m.Role, &m.AdminOption); err != nil {
+			return s, err
+		}
+		s.MemberOf = append(s.MemberOf, m)
+	}
+	return s, rows.Err()
+}
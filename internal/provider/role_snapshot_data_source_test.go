@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestReadRoleSnapshot(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().
+		ExpectQuery(`SELECT rolsuper`, []string{"rolsuper", "rolinherit", "rolcreaterole", "rolcreatedb", "rolcanlogin", "rolreplication", "rolbypassrls", "rolconnlimit", "to_char"},
+			[]driver.Value{false, true, false, false, true, false, true, int64(10), nil},
+		).
+		ExpectQuery(`SELECT rolconfig`, []string{"rolconfig"},
+			[]driver.Value{[]byte(`{statement_timeout=5s}`)},
+		).
+		ExpectQuery(`FROM pg_db_role_setting`, []string{"datname", "setconfig"},
+			[]driver.Value{"app", []byte(`{work_mem=64MB}`)},
+		).
+		ExpectQuery(`FROM pg_auth_members`, []string{"rolname", "bool_or"},
+			[]driver.Value{"readers", false},
+			[]driver.Value{"writers", true},
+		)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	snapshot, err := readRoleSnapshot(ctx, db, "app")
+	if err != nil {
+		t.Fatalf("readRoleSnapshot() error = %v", err)
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"name":"app","attributes":{"superuser":false,"inherit":true,"create_role":false,"create_database":false,"login":true,"replication":false,"bypass_rls":true,"connection_limit":10,"valid_until":null},"config":{"statement_timeout":"5s"},"database_config":{"app":{"work_mem":"64MB"}},"member_of":[{"role":"readers","admin_option":false},{"role":"writers","admin_option":true}]}`
+	if string(b) != want {
+		t.Errorf("snapshot = %s, want %s", b, want)
+	}
+}
+
+func TestRoleSnapshotDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: testAccExecSQL(t, `ALTER ROLE "example_user" SET statement_timeout = '5s';`),
+		CheckDestroy: func(*terraform.State) error {
+			testAccExecSQL(t, `ALTER ROLE "example_user" RESET statement_timeout;`)()
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "pgrole_role_snapshot" "test" {
+  role = "example_user"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pgrole_role_snapshot.test", "role", "example_user"),
+					resource.TestCheckResourceAttrWith("data.pgrole_role_snapshot.test", "json", func(value string) error {
+						var snapshot roleSnapshot
+						if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+							return err
+						}
+						if !snapshot.Attributes.Login || snapshot.Config["statement_timeout"] != "5s" {
+							return fmt.Errorf("unexpected snapshot %s", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,510 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = (*roleTemplateResource)(nil)
+	_ resource.ResourceWithConfigure   = (*roleTemplateResource)(nil)
+	_ resource.ResourceWithImportState = (*roleTemplateResource)(nil)
+	_ resource.ResourceWithIdentity    = (*roleTemplateResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*roleTemplateResource)(nil)
+)
+
+// NewRoleTemplateResource is a helper function to simplify the provider implementation.
+func NewRoleTemplateResource() resource.Resource {
+	return &roleTemplateResource{}
+}
+
+type roleTemplateResource struct {
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+	// profiles are nil until the provider is configured.
+	profiles map[string]profileModel
+}
+
+// Metadata returns the resource type name.
+func (r *roleTemplateResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_template"
+}
+
+// Schema defines the schema for the resource.
+func (r *roleTemplateResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `Apply a profile of the provider configuration to an existing role, i.e. its role attributes and configuration parameters, so that golden profiles like "etl", "readonly" or "app" are defined once and stamped onto many roles.
+
+Changes to the profile are applied to every role using it. Role attributes and parameters removed from the profile, or by switching profiles, are reset to their default. Do not combine with other resources managing the same attributes or parameters of the role.`,
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				Description: "Name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the profile to apply, one of the keys of the profiles provider attribute.",
+				Required:    true,
+			},
+			"connection_limit": schema.Int64Attribute{
+				Description: "The CONNECTION LIMIT applied by the profile, null if the profile does not set it.",
+				Computed:    true,
+			},
+			"login": schema.BoolAttribute{
+				Description: "The LOGIN status applied by the profile, null if the profile does not set it.",
+				Computed:    true,
+			},
+			"replication": schema.BoolAttribute{
+				Description: "The REPLICATION status applied by the profile, null if the profile does not set it.",
+				Computed:    true,
+			},
+			"bypassrls": schema.BoolAttribute{
+				Description: "The BYPASSRLS status applied by the profile, null if the profile does not set it.",
+				Computed:    true,
+			},
+			"settings": schema.MapAttribute{
+				Description: "The configuration parameters applied by the profile.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
+		},
+	}
+}
+
+type roleTemplateModel struct {
+	Role       string       `tfsdk:"role"`
+	Profile    types.String `tfsdk:"profile"`
+	Connection types.String `tfsdk:"connection"`
+	profileModel
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *roleTemplateResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *roleTemplateResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+	r.profiles = data.profiles
+}
+
+// ModifyPlan plans the values of the profile, so that changes to the profile
+// show on every role using it, and previews the SQL statements that the apply
+// will run.
+func (r *roleTemplateResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed, or before the
+	// provider is configured
+	if req.Plan.Raw.IsNull() || r.profiles == nil {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role, name types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("profile"), &name)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || name.IsUnknown() {
+		return
+	}
+	profile, ok := r.profiles[name.ValueString()]
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown profile",
+			fmt.Sprintf("Profile %q is not defined in the profiles provider attribute.", name.ValueString()),
+		)
+		return
+	}
+
+	var prior roleTemplateModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("connection_limit"), profile.ConnectionLimit)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("login"), profile.Login)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("replication"), profile.Replication)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("bypassrls"), profile.BypassRLS)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("settings"), profileSettings(profile))...)
+
+	sqlstr := sqlApplyRoleTemplate(role.ValueString(), profile, prior.profileModel)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
+}
+
+// profileSettings returns the settings of the profile, never null so that
+// roles get an empty map rather than a null one.
+func profileSettings(p profileModel) types.Map {
+	if p.Settings.IsNull() {
+		return types.MapValueMust(types.StringType, map[string]attr.Value{})
+	}
+	return p.Settings
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *roleTemplateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve value from plan
+	var plan roleTemplateModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlApplyRoleTemplate(plan.Role, plan.profileModel, profileModel{})
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "create", plan, true, sqlstr) {
+		return
+	}
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *roleTemplateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state roleTemplateModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read the current values from the database
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	actual, err := readRoleTemplate(ctx, db, state.Role, state.profileModel)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query role",
+			fmt.Sprintf("Failed to query role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	addDriftWarning(&resp.Diagnostics, state.Role, "connection_limit", state.ConnectionLimit.String(), actual.ConnectionLimit.String())
+	addDriftWarning(&resp.Diagnostics, state.Role, "login", state.Login.String(), actual.Login.String())
+	addDriftWarning(&resp.Diagnostics, state.Role, "replication", state.Replication.String(), actual.Replication.String())
+	addDriftWarning(&resp.Diagnostics, state.Role, "bypassrls", state.BypassRLS.String(), actual.BypassRLS.String())
+	expected, found := state.settings(), actual.settings()
+	for _, name := range sortedKeys(expected) {
+		addDriftWarning(&resp.Diagnostics, state.Role, "settings", name+"="+expected[name], name+"="+found[name])
+	}
+
+	// Overwrite the state with the actual values
+	state.profileModel = actual
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *roleTemplateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan and prior state
+	var plan, state roleTemplateModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sqlstr := sqlApplyRoleTemplate(plan.Role, plan.profileModel, state.profileModel)
+	plan.SQL = types.StringValue(sqlstr)
+	if !r.exec(ctx, &resp.Diagnostics, "update", plan, true, sqlstr) {
+		return
+	}
+
+	// Set state to updated value
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *roleTemplateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve value from state
+	var state roleTemplateModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Reset everything the profile applied
+	r.exec(ctx, &resp.Diagnostics, "delete", state, false, sqlApplyRoleTemplate(state.Role, profileModel{}, state.profileModel))
+}
+
+// ImportState imports the role without a profile: the next apply applies the
+// configured one.
+func (r *roleTemplateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	exists, err := roleExists(ctx, db, role)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query role",
+			fmt.Sprintf("Failed to query role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("settings"), map[string]string{})...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), "")...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// exec runs sqlstr for the role of m during operation, e.g. "create", adding
+// any error to diags. When check is true, the connecting role must be allowed
+// to apply the profile of m, and with verify_writes it is read back afterwards.
+func (r *roleTemplateResource) exec(ctx context.Context, diags *diag.Diagnostics, operation string, m roleTemplateModel, check bool, sqlstr string) bool {
+	db, err := r.connect(m.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		diags.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return false
+	}
+	defer db.Close()
+
+	policy, err := r.retry.override(m.Retry)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return false
+	}
+	for _, attribute := range m.privilegedAttributes() {
+		if !checkPrivileges(ctx, db, diags, m.Role, attribute) {
+			return false
+		}
+	}
+	if check {
+		for _, name := range sortedKeys(m.settings()) {
+			if !checkParameterPrivilege(ctx, db, diags, "settings", name) {
+				return false
+			}
+		}
+	}
+	if sqlstr == "" {
+		return true
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(diags, operation, m.Role, err)
+		return false
+	}
+	if check && r.verifyWrites {
+		actual, err := readRoleTemplate(ctx, db, m.Role, m.profileModel)
+		discrepancies := []string{
+			discrepancy("connection_limit", m.ConnectionLimit.String(), actual.ConnectionLimit.String()),
+			discrepancy("login", m.Login.String(), actual.Login.String()),
+			discrepancy("replication", m.Replication.String(), actual.Replication.String()),
+			discrepancy("bypassrls", m.BypassRLS.String(), actual.BypassRLS.String()),
+		}
+		applied, found := m.settings(), actual.settings()
+		for _, name := range sortedKeys(applied) {
+			discrepancies = append(discrepancies, discrepancy(name, applied[name], found[name]))
+		}
+		return verifyWrite(diags, m.Role, err, discrepancies...)
+	}
+	return true
+}
+
+// readRoleTemplate returns the values of the role for the attributes and
+// parameters set by profile, or sql.ErrNoRows if the role does not exist.
+func readRoleTemplate(ctx context.Context, db *sql.DB, role string, profile profileModel) (profileModel, error) {
+	var connLimit int64
+	var login, replication, bypassRLS bool
+	var rolconfig pq.StringArray
+	err := db.QueryRowContext(ctx, "SELECT rolconnlimit, rolcanlogin, rolreplication, rolbypassrls, rolconfig FROM pg_roles WHERE rolname = $1;", role).
+		Scan(&connLimit, &login, &replication, &bypassRLS, &rolconfig)
+	if err != nil {
+		return profileModel{}, err
+	}
+
+	actual := profileModel{
+		ConnectionLimit: types.Int64Null(),
+		Login:           types.BoolNull(),
+		Replication:     types.BoolNull(),
+		BypassRLS:       types.BoolNull(),
+	}
+	if !profile.ConnectionLimit.IsNull() {
+		actual.ConnectionLimit = types.Int64Value(connLimit)
+	}
+	if !profile.Login.IsNull() {
+		actual.Login = types.BoolValue(login)
+	}
+	if !profile.Replication.IsNull() {
+		actual.Replication = types.BoolValue(replication)
+	}
+	if !profile.BypassRLS.IsNull() {
+		actual.BypassRLS = types.BoolValue(bypassRLS)
+	}
+	config := parseRoleConfig(rolconfig)
+	settings := map[string]attr.Value{}
+	for name := range profile.settings() {
+		if value, ok := config[name]; ok {
+			settings[name] = types.StringValue(value)
+		}
+	}
+	actual.Settings = types.MapValueMust(types.StringType, settings)
+	return actual, nil
+}
+
+// sqlApplyRoleTemplate returns the statements applying profile to the role,
+// and resetting to their default the attributes and parameters set by prior
+// but not by profile.
+func sqlApplyRoleTemplate(role string, profile, prior profileModel) string {
+	var statements []string
+	switch {
+	case !profile.ConnectionLimit.IsNull():
+		statements = append(statements, sqlSetConnectionLimit(role, profile.ConnectionLimit.ValueInt64()))
+	case !prior.ConnectionLimit.IsNull():
+		statements = append(statements, sqlSetConnectionLimit(role, -1))
+	}
+	switch {
+	case !profile.Login.IsNull():
+		statements = append(statements, sqlSetLogin(role, profile.Login.ValueBool()))
+	case !prior.Login.IsNull():
+		statements = append(statements, sqlDisableLogin(role))
+	}
+	switch {
+	case !profile.Replication.IsNull():
+		statements = append(statements, sqlSetReplication(role, profile.Replication.ValueBool()))
+	case !prior.Replication.IsNull():
+		statements = append(statements, sqlDisableReplication(role))
+	}
+	switch {
+	case !profile.BypassRLS.IsNull():
+		statements = append(statements, sqlSetBypassRLS(role, profile.BypassRLS.ValueBool()))
+	case !prior.BypassRLS.IsNull():
+		statements = append(statements, sqlDisableBypassRLS(role))
+	}
+	if settings := sqlApplyNamespaceSettings(role, "", profile.settings(), prior.settings()); settings != "" {
+		statements = append(statements, settings)
+	}
+	return strings.Join(statements, "\n")
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestSqlApplyRoleTemplate(t *testing.T) {
+	etl := profileModel{
+		ConnectionLimit: types.Int64Value(5),
+		Login:           types.BoolValue(true),
+		Replication:     types.BoolNull(),
+		BypassRLS:       types.BoolValue(true),
+		Settings:        testStringMap(map[string]string{"work_mem": "256MB", "statement_timeout": "0"}),
+	}
+	readonly := profileModel{
+		ConnectionLimit: types.Int64Null(),
+		Login:           types.BoolValue(true),
+		Replication:     types.BoolNull(),
+		BypassRLS:       types.BoolNull(),
+		Settings:        testStringMap(map[string]string{"default_transaction_read_only": "on"}),
+	}
+	tests := []struct {
+		name    string
+		profile profileModel
+		prior   profileModel
+		want    string
+	}{
+		{
+			name:    "create",
+			profile: etl,
+			want: `ALTER ROLE "app" CONNECTION LIMIT 5;
+ALTER ROLE "app" LOGIN;
+ALTER ROLE "app" BYPASSRLS;
+ALTER ROLE "app" SET statement_timeout = '0';
+ALTER ROLE "app" SET work_mem = '256MB';`,
+		},
+		{
+			name:    "switch profile",
+			profile: readonly,
+			prior:   etl,
+			want: `ALTER ROLE "app" CONNECTION LIMIT -1;
+ALTER ROLE "app" LOGIN;
+ALTER ROLE "app" NOBYPASSRLS;
+ALTER ROLE "app" RESET statement_timeout;
+ALTER ROLE "app" RESET work_mem;
+ALTER ROLE "app" SET default_transaction_read_only = 'on';`,
+		},
+		{
+			name:  "delete",
+			prior: readonly,
+			want: `ALTER ROLE "app" NOLOGIN;
+ALTER ROLE "app" RESET default_transaction_read_only;`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqlApplyRoleTemplate("app", tt.profile, tt.prior); got != tt.want {
+				t.Errorf("sqlApplyRoleTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRoleTemplate(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectQuery(`FROM pg_roles`, []string{"rolconnlimit", "rolcanlogin", "rolreplication", "rolbypassrls", "rolconfig"},
+		[]driver.Value{int64(10), true, false, true, "{work_mem=64MB,search_path=app}"},
+	)
+	db, err := fake.GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	profile := profileModel{
+		ConnectionLimit: types.Int64Value(5),
+		Login:           types.BoolValue(true),
+		Replication:     types.BoolNull(),
+		BypassRLS:       types.BoolNull(),
+		Settings:        testStringMap(map[string]string{"work_mem": "256MB", "statement_timeout": "0"}),
+	}
+	got, err := readRoleTemplate(ctx, db, "app", profile)
+	if err != nil {
+		t.Fatalf("readRoleTemplate() error = %v", err)
+	}
+
+	// Only the attributes and parameters of the profile are read
+	want := profileModel{
+		ConnectionLimit: types.Int64Value(10),
+		Login:           types.BoolValue(true),
+		Replication:     types.BoolNull(),
+		BypassRLS:       types.BoolNull(),
+		Settings:        testStringMap(map[string]string{"work_mem": "64MB"}),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readRoleTemplate() = %+v, want %+v", got, want)
+	}
+}
+
+func testStringMap(m map[string]string) types.Map {
+	elements := make(map[string]attr.Value, len(m))
+	for k, v := range m {
+		elements[k] = types.StringValue(v)
+	}
+	return types.MapValueMust(types.StringType, elements)
+}
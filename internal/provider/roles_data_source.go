@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = (*rolesDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*rolesDataSource)(nil)
+)
+
+// NewRolesDataSource is a helper function to simplify the provider implementation.
+func NewRolesDataSource() datasource.DataSource {
+	return &rolesDataSource{}
+}
+
+type rolesDataSource struct {
+	connect func(connection, database string) DBGetter
+}
+
+type rolesDataSourceModel struct {
+	NameRegex  types.String `tfsdk:"name_regex"`
+	Connection types.String `tfsdk:"connection"`
+	Names      []string     `tfsdk:"names"`
+}
+
+// Metadata returns the data source type name.
+func (d *rolesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_roles"
+}
+
+// Schema defines the schema for the data source.
+func (d *rolesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Lists the names of the non-system roles of the database, i.e. leaving out the bootstrap superuser and the predefined ` + "`pg_*`" + ` roles.
+
+Combined with ` + "`for_each`" + ` in ` + "`import`" + ` blocks (Terraform >= 1.7), this imports the settings of every role in one pass, e.g. to adopt an existing database.`,
+		Attributes: map[string]schema.Attribute{
+			"connection": schema.StringAttribute{
+				Description: "Name of the provider connection to read the roles through, one of the keys of the connections provider attribute. Defaults to the connection of the provider block.",
+				Optional:    true,
+			},
+			"name_regex": schema.StringAttribute{
+				Description: "Only roles whose name matches this regular expression are listed.",
+				Optional:    true,
+				Validators:  []validator.String{regexValidator{}},
+			},
+			"names": schema.ListAttribute{
+				Description: "The names of the roles, sorted.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *rolesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.connect = data.dbFor
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *rolesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data rolesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := d.connect(data.Connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	names, err := readRoleNames(ctx, db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query roles",
+			"Failed to query roles: "+err.Error(),
+		)
+		return
+	}
+
+	data.Names = []string{}
+	re := regexp.MustCompile(data.NameRegex.ValueString())
+	for _, name := range names {
+		if re.MatchString(name) {
+			data.Names = append(data.Names, name)
+		}
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readRoleNames returns the sorted names of the non-system roles. Roles
+// created by users have an OID of at least 16384, FirstNormalObjectId.
+func readRoleNames(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT rolname FROM pg_roles WHERE oid >= 16384 AND rolname !~ '^pg_' ORDER BY rolname;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
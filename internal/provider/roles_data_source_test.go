@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestRolesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "pgrole_roles" "test" {
+  name_regex = "^(test_role|example_user|pg_monitor|postgres)$"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pgrole_roles.test", "names.#", "2"),
+					resource.TestCheckResourceAttr("data.pgrole_roles.test", "names.0", "example_user"),
+					resource.TestCheckResourceAttr("data.pgrole_roles.test", "names.1", "test_role"),
+				),
+			},
+		},
+	})
+}
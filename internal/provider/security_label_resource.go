@@ -5,12 +5,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
+	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -18,6 +22,9 @@ var (
 	_ resource.Resource                = (*securityLabelResource)(nil)
 	_ resource.ResourceWithConfigure   = (*securityLabelResource)(nil)
 	_ resource.ResourceWithImportState = (*securityLabelResource)(nil)
+	_ resource.ResourceWithIdentity    = (*securityLabelResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*securityLabelResource)(nil)
+	_ resource.ResourceWithMoveState   = (*securityLabelResource)(nil)
 )
 
 // NewSecurityLabelResource is a helper function to simplify the provider implementation.
@@ -26,7 +33,11 @@ func NewSecurityLabelResource() resource.Resource {
 }
 
 type securityLabelResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
 }
 
 // Metadata returns the resource type name.
@@ -69,18 +80,36 @@ This resource performs the following SQL operations:
 			"role": schema.StringAttribute{
 				Description: "Name of the role to apply the security label to.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"label": schema.StringAttribute{
 				Description: "Security label value. Use 'MASKED' to enable dynamic masking for the role, or NULL to remove the label.",
 				Required:    true,
 			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type securityLabelModel struct {
-	Role  string `tfsdk:"role"`
-	Label string `tfsdk:"label"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	Label              string       `tfsdk:"label"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *securityLabelResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -91,15 +120,50 @@ func (r *securityLabelResource) Configure(_ context.Context, req resource.Config
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
 	}
 
-	r.getDB = client
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *securityLabelResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role types.String
+	var label types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("label"), &label)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || label.IsUnknown() {
+		return
+	}
+
+	sqlstr := sqlSetSecurityLabel(role.ValueString(), label.ValueString())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -114,8 +178,9 @@ func (r *securityLabelResource) Create(ctx context.Context, req resource.CreateR
 
 	// Create the resource
 	sqlstr := sqlSetSecurityLabel(plan.Role, plan.Label)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -125,13 +190,28 @@ func (r *securityLabelResource) Create(ctx context.Context, req resource.CreateR
 	}
 	defer db.Close()
 
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readSecurityLabel(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("label", plan.Label, actual)) {
+			return
+		}
+	}
 
 	tflog.Info(ctx, "Created security label for role", map[string]any{
 		"role":  plan.Role,
@@ -139,6 +219,7 @@ func (r *securityLabelResource) Create(ctx context.Context, req resource.CreateR
 	})
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -157,7 +238,7 @@ func (r *securityLabelResource) Read(ctx context.Context, req resource.ReadReque
 	}
 
 	// Get the actual value in postgres
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -167,38 +248,33 @@ func (r *securityLabelResource) Read(ctx context.Context, req resource.ReadReque
 	}
 	defer db.Close()
 
-	var label sql.NullString
-	sqlstr := `SELECT label 
-FROM pg_seclabels 
-WHERE objtype = 'role' 
-AND provider = 'anon' 
-AND objname = $1`
-
-	err = db.QueryRowContext(ctx, sqlstr, state.Role).Scan(&label)
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		// No security label found, set to empty
-		state.Label = ""
-	case err == nil:
-		if label.Valid {
-			state.Label = label.String
-		} else {
-			state.Label = ""
-		}
-	default:
-		resp.Diagnostics.AddError(
+	label, err := readSecurityLabel(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
 			"Failed to query security label",
 			fmt.Sprintf("Failed to query security label for role %s: %s", state.Role, err),
 		)
 		return
 	}
 
+	addDriftWarning(&resp.Diagnostics, state.Role, "label", state.Label, label)
+
+	state.Label = label
+
 	tflog.Info(ctx, "Read security label for role", map[string]any{
 		"role":  state.Role,
 		"label": state.Label,
 	})
 
 	// Set refreshed state
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -218,8 +294,9 @@ func (r *securityLabelResource) Update(ctx context.Context, req resource.UpdateR
 
 	// Update resource state with updated values
 	sqlstr := sqlSetSecurityLabel(plan.Role, plan.Label)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -229,19 +306,35 @@ func (r *securityLabelResource) Update(ctx context.Context, req resource.UpdateR
 	}
 	defer db.Close()
 
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readSecurityLabel(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("label", plan.Label, actual)) {
+			return
+		}
+	}
 
 	tflog.Info(ctx, "Updated security label for role", map[string]any{
 		"role":  plan.Role,
 		"label": plan.Label,
 	})
 
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -259,9 +352,19 @@ func (r *securityLabelResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
 	// Delete the resource by removing the security label
 	sqlstr := sqlRemoveSecurityLabel(state.Role)
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -271,13 +374,22 @@ func (r *securityLabelResource) Delete(ctx context.Context, req resource.DeleteR
 	}
 	defer db.Close()
 
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 
 	tflog.Info(ctx, "Deleted security label for role", map[string]any{
 		"role": state.Role,
@@ -285,16 +397,115 @@ func (r *securityLabelResource) Delete(ctx context.Context, req resource.DeleteR
 }
 
 func (r *securityLabelResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	label, err := readSecurityLabel(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query security label",
+			fmt.Sprintf("Failed to query security label for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("label"), label)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetSecurityLabel(role, label))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_security_label resource of the community
+// PostgreSQL provider into this resource.
+func (r *securityLabelResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlSecurityLabelState
+				if !readPostgresqlState(req, resp, "postgresql_security_label", &source) {
+					return
+				}
+
+				if source.ObjectType != "role" || source.LabelProvider != "anon" {
+					resp.Diagnostics.AddError(
+						"Unable to move resource state",
+						fmt.Sprintf("Only anon security labels on roles can be moved, got a %s label on %s %s.", source.LabelProvider, source.ObjectType, source.ObjectName),
+					)
+					return
+				}
+
+				target := securityLabelModel{
+					Role:  source.ObjectName,
+					Label: source.Label,
+					SQL:   types.StringValue(sqlSetSecurityLabel(source.ObjectName, source.Label)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.ObjectName)...)
+			},
+		},
+	}
+}
+
+// readSecurityLabel returns the anon security label of the role, an empty
+// string if none is set, or sql.ErrNoRows if the role does not exist.
+func readSecurityLabel(ctx context.Context, db *sql.DB, role string) (string, error) {
+	exists, err := roleExists(ctx, db, role)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", sql.ErrNoRows
+	}
+
+	var label sql.NullString
+	sqlstr := `SELECT label 
+FROM pg_seclabels 
+WHERE objtype = 'role' 
+AND provider = 'anon' 
+AND objname = $1`
+	err = db.QueryRowContext(ctx, sqlstr, role).Scan(&label)
+	if errors.Is(err, sql.ErrNoRows) {
+		// No security label found
+		return "", nil
+	}
+	return label.String, err
 }
 
 // sqlSetSecurityLabel generates SQL to set a security label for a role
 func sqlSetSecurityLabel(role string, label string) string {
-	escapedLabel := strings.ReplaceAll(label, "'", "''")
-	return fmt.Sprintf("SECURITY LABEL FOR anon ON ROLE %q IS '%s';", role, escapedLabel)
+	return fmt.Sprintf("SECURITY LABEL FOR anon ON ROLE %s IS %s;", quoteIdentifier(role), pq.QuoteLiteral(label))
 }
 
 // sqlRemoveSecurityLabel generates SQL to remove a security label for a role
 func sqlRemoveSecurityLabel(role string) string {
-	return fmt.Sprintf("SECURITY LABEL FOR anon ON ROLE %q IS NULL;", role)
+	return fmt.Sprintf("SECURITY LABEL FOR anon ON ROLE %s IS NULL;", quoteIdentifier(role))
 }
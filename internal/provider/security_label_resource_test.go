@@ -8,6 +8,7 @@ import (
 
 func TestSecurityLabelResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
+		PreCheck:                 testAccPreCheckExtension(t, "anon"),
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			// Create and Read testing
@@ -46,3 +47,19 @@ resource "pgrole_security_label" "test" {
 		},
 	})
 }
+
+func TestSqlSetSecurityLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"MASKED", `SECURITY LABEL FOR anon ON ROLE "test" IS 'MASKED';`},
+		{"O'Brien", `SECURITY LABEL FOR anon ON ROLE "test" IS 'O''Brien';`},
+		{"x'; DROP ROLE test; --", `SECURITY LABEL FOR anon ON ROLE "test" IS 'x''; DROP ROLE test; --';`},
+	}
+	for _, tt := range tests {
+		if got := sqlSetSecurityLabel("test", tt.label); got != tt.want {
+			t.Errorf("sqlSetSecurityLabel(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
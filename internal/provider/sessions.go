@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
+)
+
+// drainPollInterval is the delay between two counts of the sessions of a role
+// while waiting for them to drain.
+var drainPollInterval = time.Second
+
+// drainModel describes the drain attribute of pgrole_connection_limit.
+type drainModel struct {
+	Timeout       types.String `tfsdk:"timeout"`
+	TerminateIdle types.Bool   `tfsdk:"terminate_idle"`
+}
+
+// drainAttribute returns the schema of the drain attribute.
+func drainAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "What to do when the role has more sessions than its new connection limit. Without it, existing sessions are left alone and the role stays over its limit until they end.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				Description: "How long the apply waits for the sessions to drain, e.g. \"30s\" or \"5m\". The apply succeeds with a warning if the role is still over its limit afterwards.",
+				Required:    true,
+				Validators:  []validator.String{durationValidator{}},
+			},
+			"terminate_idle": schema.BoolAttribute{
+				Description: "Whether to terminate the oldest idle sessions of the role, instead of only waiting for them to end. Requires the pg_signal_backend role. Defaults to false.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// drainSessions waits up to timeout for role to have at most limit sessions,
+// terminating its oldest idle sessions if terminateIdle is set, recorded with
+// audit. It returns the number of sessions still over the limit.
+func drainSessions(ctx context.Context, db *sql.DB, audit *eventAudit, role string, limit int64, timeout time.Duration, terminateIdle bool) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		sessions, err := countSessions(ctx, db, role)
+		if err != nil {
+			return 0, err
+		}
+		over := sessions - int(limit)
+		if over <= 0 {
+			return 0, nil
+		}
+
+		if terminateIdle {
+			terminated, err := terminateIdleSessions(ctx, db, audit, role, over)
+			if err != nil {
+				return 0, err
+			}
+			tflog.Info(ctx, "Terminated idle sessions over the connection limit", map[string]any{
+				"role":       role,
+				"terminated": terminated,
+			})
+		}
+
+		if !time.Now().Add(drainPollInterval).Before(deadline) {
+			return over, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// countSessions returns the number of sessions of role, across all databases.
+func countSessions(ctx context.Context, db *sql.DB, role string) (int, error) {
+	var sessions int
+	err := db.QueryRowContext(ctx, "SELECT count(*) FROM pg_stat_activity WHERE usename = $1;", role).Scan(&sessions)
+	return sessions, err
+}
+
+// terminateIdleSessions terminates at most n idle sessions of role, oldest
+// first, recorded with audit, and returns the number of sessions terminated.
+func terminateIdleSessions(ctx context.Context, db *sql.DB, audit *eventAudit, role string, n int) (int, error) {
+	var terminated int
+	err := queryRowAudited(ctx, db, audit, fmt.Sprintf(`SELECT count(*) FILTER (WHERE terminated) FROM (
+  SELECT pg_terminate_backend(pid) AS terminated
+  FROM pg_stat_activity
+  WHERE usename = %s AND state = 'idle' AND pid <> pg_backend_pid()
+  ORDER BY backend_start
+  LIMIT %d
+) s;`, pq.QuoteLiteral(role), n), &terminated)
+	return terminated, err
+}
+
+// terminateSessions terminates all sessions of role, except the current one,
+// recorded with audit, and returns the number of sessions terminated.
+func terminateSessions(ctx context.Context, db *sql.DB, audit *eventAudit, role string) (int, error) {
+	var terminated int
+	err := queryRowAudited(ctx, db, audit, fmt.Sprintf(`SELECT count(*) FILTER (WHERE pg_terminate_backend(pid))
+FROM pg_stat_activity
+WHERE usename = %s AND pid <> pg_backend_pid();`, pq.QuoteLiteral(role)), &terminated)
+	return terminated, err
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestDrainSessions(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		sessions      int64
+		terminateIdle bool
+		want          int
+	}{
+		{"within limit", 2, false, 0},
+		{"over limit", 5, false, 2},
+		{"over limit with termination", 5, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake database keeps reporting the same number of sessions,
+			// as if they were busy
+			fake := fakedb.New().
+				ExpectQuery(`FROM pg_stat_activity WHERE usename`, []string{"count"}, []driver.Value{tt.sessions}).
+				ExpectQuery(`pg_terminate_backend`, []string{"count"}, []driver.Value{int64(0)})
+			if !tt.terminateIdle {
+				fake.ExpectError(`pg_terminate_backend`, errors.New("sessions must not be terminated"))
+			}
+			db, _ := fake.GetDB(ctx)
+			defer db.Close()
+
+			got, err := drainSessions(ctx, db, nil, "app", 3, 0, tt.terminateIdle)
+			if err != nil {
+				t.Fatalf("drainSessions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("drainSessions() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminateSessionsEventAudit(t *testing.T) {
+	ctx := context.Background()
+	audit, _ := newEventAudit(&eventAuditModel{
+		Table:    types.StringValue("audit.pgrole_events"),
+		RunID:    types.StringValue("run-1"),
+		Operator: types.StringValue("ci@example.com"),
+	})
+	fake := fakedb.New().ExpectQuery(`pg_terminate_backend`, []string{"count"}, []driver.Value{int64(2)})
+	db, _ := fake.GetDB(ctx)
+	defer db.Close()
+
+	terminated, err := terminateSessions(ctx, db, audit, "app")
+	if err != nil {
+		t.Fatalf("terminateSessions() error = %v", err)
+	}
+	if terminated != 2 {
+		t.Errorf("terminateSessions() = %d, want 2", terminated)
+	}
+	execs := fake.Execs()
+	if len(execs) != 3 || execs[0].SQL != "BEGIN" || !strings.HasPrefix(execs[1].SQL, `INSERT INTO "audit"."pgrole_events"`) || execs[2].SQL != "COMMIT" {
+		t.Fatalf("terminateSessions() ran %v, want the statement recorded in a transaction", execs)
+	}
+	if statement, _ := execs[1].Args[2].(string); !strings.Contains(statement, "pg_terminate_backend") || !strings.Contains(statement, "usename = 'app'") {
+		t.Errorf("terminateSessions() recorded %q, want the statement terminating the sessions of app", statement)
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/lib/pq"
+)
+
+// addSQLError adds an error to diags for err, returned by the SQL statements
+// run on role during operation, e.g. "create", along with its SQLSTATE and
+// a hint to fix it.
+func addSQLError(diags *diag.Diagnostics, operation, role string, err error) {
+	diags.AddError(
+		"Failed to execute SQL",
+		fmt.Sprintf("Failed to execute SQL on role %s during %s: %s", role, operation, err)+sqlErrorDetails(role, err),
+	)
+}
+
+// sqlErrorDetails returns the SQLSTATE of err, if it was returned by the
+// server, and a remediation hint for errors caused by the setup rather than
+// the configuration, to append to the detail of diagnostics about role.
+// Errors without a known hint keep the hint of the server, if any, and
+// interruptions explain that nothing was changed.
+func sqlErrorDetails(role string, err error) string {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("\n\nHint: The operation was interrupted, e.g. with Ctrl-C, and its statements were canceled on the server and rolled back: role %s is unchanged.", role)
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return ""
+	}
+	details := fmt.Sprintf("\n\nSQLSTATE: %s (%s)", pqErr.Code, pqErr.Code.Name())
+	hint := pqErr.Hint
+	switch pqErr.Code {
+	case "42501": // insufficient_privilege
+		hint = fmt.Sprintf("Grant ADMIN OPTION on role %s to the connecting role, e.g. as a superuser: GRANT %s TO <provider user> WITH ADMIN OPTION; Changing the REPLICATION or BYPASSRLS attributes, or superuser-only parameters, also requires the connecting role to have them, or to be a superuser.", role, quoteIdentifier(role))
+	case "42704": // undefined_object
+		hint = fmt.Sprintf("Check that role %s and the configuration parameters exist. Parameters of extensions are only known once the extension is loaded, e.g. through shared_preload_libraries.", role)
+	case "22023": // invalid_parameter_value
+		hint = "Check the values against the documentation of the configuration parameters, including their units."
+	case "55P03", "40P01": // lock_not_available, deadlock_detected
+		hint = "Another session holds a conflicting lock, e.g. a long-running migration. Configure the retry attribute to retry the statements."
+	case "25006": // read_only_sql_transaction
+		hint = "The server is read-only, e.g. a standby. Connect to the primary."
+	case "57014": // query_canceled
+		hint = "The statements were canceled, e.g. by the statement_timeout or lock_timeout of the connecting role. Raise them, or configure the retry attribute."
+	}
+	if hint != "" {
+		details += "\n\nHint: " + hint
+	}
+	return details
+}
+
+// addReadError adds a diagnostic to diags for err, returned by the refresh of
+// role: an error, or a warning when the connecting role may not read the
+// catalog, e.g. because a managed service hides some of its columns, so that
+// the refresh keeps the prior state instead of failing.
+func addReadError(diags *diag.Diagnostics, role string, err error, summary, detail string) {
+	if isInsufficientPrivilege(err) {
+		diags.AddWarning(
+			"Insufficient privileges to refresh",
+			detail+fmt.Sprintf("\n\nThe prior state is kept, so drift on role %s goes undetected until the connecting role may read it: grant it SELECT on the catalog named in the error, or membership in a predefined role such as pg_read_all_settings.", role),
+		)
+		return
+	}
+	diags.AddError(summary, detail+sqlErrorDetails(role, err))
+}
+
+// isInsufficientPrivilege reports whether err is a permission error of the
+// server.
+func isInsufficientPrivilege(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42501"
+}
+
+// isAuthenticationFailure reports whether err is the refusal of the server to
+// authenticate a role, e.g. because of a wrong password, as opposed to a
+// failure to reach the server.
+func isAuthenticationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == "28P01" || pqErr.Code == "28000") // invalid_password, invalid_authorization_specification
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestSqlErrorDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "insufficient privilege",
+			err:  fmt.Errorf("wrapped: %w", &pq.Error{Code: "42501", Message: "permission denied to alter role"}),
+			want: []string{"SQLSTATE: 42501 (insufficient_privilege)", `GRANT "app" TO <provider user> WITH ADMIN OPTION;`},
+		},
+		{
+			name: "lock",
+			err:  &pq.Error{Code: "55P03", Message: "could not obtain lock"},
+			want: []string{"SQLSTATE: 55P03 (lock_not_available)", "Configure the retry attribute"},
+		},
+		{
+			name: "server hint",
+			err:  &pq.Error{Code: "22P02", Message: "invalid input syntax", Hint: "Use a number."},
+			want: []string{"SQLSTATE: 22P02 (invalid_text_representation)", "Hint: Use a number."},
+		},
+		{
+			name: "interrupted",
+			err:  fmt.Errorf("%w: %w", context.Canceled, &pq.Error{Code: "57014", Message: "canceling statement due to user request"}),
+			want: []string{"statements were canceled on the server and rolled back: role app is unchanged"},
+		},
+		{
+			name: "not from the server",
+			err:  errors.New("driver: bad connection"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sqlErrorDetails("app", tt.err)
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("sqlErrorDetails() = %q, want none", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("sqlErrorDetails() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadInsufficientPrivilege(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedb.New().ExpectError(`rolbypassrls`, &pq.Error{Code: "42501", Message: "permission denied for view pg_roles"})
+	r := NewBypassRLSResource()
+	testConfigure(t, r, fake)
+
+	prior := bypassrlsModel{
+		Role:    "app",
+		Enabled: true,
+		SQL:     types.StringValue(sqlSetBypassRLS("app", true)),
+	}
+	state := testResourceState(t, r, prior)
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() error = %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || !strings.Contains(resp.Diagnostics[0].Detail(), "The prior state is kept") {
+		t.Errorf("Read() = %v, want a warning keeping the prior state", resp.Diagnostics)
+	}
+
+	var refreshed bypassrlsModel
+	resp.State.Get(ctx, &refreshed)
+	if !reflect.DeepEqual(refreshed, prior) {
+		t.Errorf("Read() state = %+v, want the prior state %+v", refreshed, prior)
+	}
+}
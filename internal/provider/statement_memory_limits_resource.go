@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewStatementMemoryLimitsResource is a helper function to simplify the provider implementation.
+func NewStatementMemoryLimitsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "statement_memory_limits",
+		description: `Manage the memory limits of the statements of an existing role (work_mem, hash_mem_multiplier and temp_file_limit) together. Settings left unset are reset to the database default.
+
+All settings are applied atomically: the ALTER ROLE statements run as a single batch, which either applies every change or none.
+
+Setting temp_file_limit requires the connecting role to be a superuser.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-resource.html#RUNTIME-CONFIG-RESOURCE-MEMORY) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "work_mem",
+				Parameter:   "work_mem",
+				Description: "The memory used by a query operation before writing to temporary files, e.g. \"64MB\".",
+				Unit:        "kB",
+				StringValidators: []validator.String{
+					memoryValidator{},
+				},
+			},
+			{
+				Attribute:   "hash_mem_multiplier",
+				Parameter:   "hash_mem_multiplier",
+				Description: "The multiple of work_mem that hash-based operations can use, between 1 and 1000.",
+				Type:        settingFloat64,
+				Float64Validators: []validator.Float64{
+					float64validator.Between(1, 1000),
+				},
+			},
+			{
+				Attribute:   "temp_file_limit",
+				Parameter:   "temp_file_limit",
+				Description: "The disk space a process can use for temporary files, e.g. \"10GB\", or \"-1\" for no limit.",
+				Unit:        "kB",
+				StringValidators: []validator.String{
+					stringvalidator.Any(memoryValidator{}, stringvalidator.OneOf("-1")),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestStatementMemoryLimitsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_statement_memory_limits" "test" {
+  role                = "example_user"
+  work_mem            = "64MB"
+  hash_mem_multiplier = 2.5
+  temp_file_limit     = "1GB"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_statement_memory_limits.test", "work_mem", "64MB"),
+					resource.TestCheckResourceAttr("pgrole_statement_memory_limits.test", "hash_mem_multiplier", "2.5"),
+					resource.TestCheckResourceAttr("pgrole_statement_memory_limits.test", "temp_file_limit", "1GB"),
+					resource.TestCheckResourceAttr("pgrole_statement_memory_limits.test", "sql", `ALTER ROLE "example_user" SET work_mem = '64MB';
+ALTER ROLE "example_user" SET hash_mem_multiplier = 2.5;
+ALTER ROLE "example_user" SET temp_file_limit = '1GB';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_statement_memory_limits.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET work_mem = '4MB';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
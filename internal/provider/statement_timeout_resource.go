@@ -6,13 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lib/pq"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -20,6 +27,9 @@ var (
 	_ resource.Resource                = (*statementTimeoutResource)(nil)
 	_ resource.ResourceWithConfigure   = (*statementTimeoutResource)(nil)
 	_ resource.ResourceWithImportState = (*statementTimeoutResource)(nil)
+	_ resource.ResourceWithIdentity    = (*statementTimeoutResource)(nil)
+	_ resource.ResourceWithModifyPlan  = (*statementTimeoutResource)(nil)
+	_ resource.ResourceWithMoveState   = (*statementTimeoutResource)(nil)
 )
 
 // NewStatementTimeoutResource is a helper function to simplify the provider implementation.
@@ -28,7 +38,12 @@ func NewStatementTimeoutResource() resource.Resource {
 }
 
 type statementTimeoutResource struct {
-	getDB F
+	connect          func(connection, database string) DBGetter
+	retry            retryPolicy
+	verifyWrites     bool
+	allowSystemRoles bool
+	roleNamePattern  *regexp.Regexp
+	parameterGuard   parameterGuard
 }
 
 // Metadata returns the resource type name.
@@ -43,11 +58,16 @@ func (r *statementTimeoutResource) Schema(_ context.Context, req resource.Schema
 	resp.Schema = schema.Schema{
 		Description: `Manage statement_timeout for an existing role.
 
+Values set on the role in specific databases with ALTER ROLE ... IN DATABASE take precedence in those databases and are not managed by this resource; a warning lists them when the resource is read or imported.
+
 See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-STATEMENT-TIMEOUT) for more details.`,
 		Attributes: map[string]schema.Attribute{
 			"role": schema.StringAttribute{
 				Description: "Name of the role.",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"timeout": schema.StringAttribute{
 				Description: "The timeout value, must be an integer follow by character \"s\", .e.g: 100s.",
@@ -56,13 +76,43 @@ See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-con
 					stringvalidator.RegexMatches(timeoutAttributeRe, "Timeout must be in the format of <number>s, for example: 100s, 300s."),
 				},
 			},
+			"restore_value": schema.StringAttribute{
+				Description: "The timeout set on the role when this resource is destroyed, e.g. the organization default \"30s\", in the same format as timeout. Defaults to resetting statement_timeout, so that the role inherits it from the database or the server. Recorded when this resource is created: later changes to it only take effect if it was not set then. Takes precedence over adopt, and is ignored if skip_reset_on_destroy is set.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(timeoutAttributeRe, "Timeout must be in the format of <number>s, for example: 100s, 300s."),
+				},
+			},
+			"effective_timeout": schema.StringAttribute{
+				Description: "The statement_timeout actually in effect for new sessions of the role in the database of the connection: the value set on the role in that database, else the one set on the role, else the one set on the database, else the server default. Explains why statements are cancelled at a different timeout than the managed one.",
+				Computed:    true,
+			},
+			"connection":            connectionAttribute(),
+			"deletion_protection":   deletionProtectionAttribute(),
+			"skip_reset_on_destroy": skipResetOnDestroyAttribute(),
+			"adopt":                 adoptAttribute(),
+			"sql":                   sqlAttribute(),
+			"retry":                 retryAttribute(),
 		},
 	}
 }
 
 type statementTimeoutModel struct {
-	Role    string `tfsdk:"role"`
-	Timeout string `tfsdk:"timeout"`
+	Role               string       `tfsdk:"role"`
+	Connection         types.String `tfsdk:"connection"`
+	Timeout            string       `tfsdk:"timeout"`
+	RestoreValue       types.String `tfsdk:"restore_value"`
+	EffectiveTimeout   types.String `tfsdk:"effective_timeout"`
+	DeletionProtection bool         `tfsdk:"deletion_protection"`
+	SkipResetOnDestroy bool         `tfsdk:"skip_reset_on_destroy"`
+	Adopt              bool         `tfsdk:"adopt"`
+	SQL                types.String `tfsdk:"sql"`
+	Retry              *retryModel  `tfsdk:"retry"`
+}
+
+// IdentitySchema defines the identity schema for the resource.
+func (r *statementTimeoutResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = roleIdentitySchema()
 }
 
 // Configure adds the provider configured client to the resource.
@@ -73,15 +123,55 @@ func (r *statementTimeoutResource) Configure(_ context.Context, req resource.Con
 		return
 	}
 
-	client, ok := req.ProviderData.(F)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Source Configure Type",
-			fmt.Sprintf("Expected provider.F, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *provider.providerData, got %T", req.ProviderData),
 		)
+		return
+	}
+
+	r.connect = data.dbFor
+	r.retry = data.retry
+	r.verifyWrites = data.verifyWrites
+	r.allowSystemRoles = data.allowSystemRoles
+	r.roleNamePattern = data.roleNamePattern
+	r.parameterGuard = data.parameterGuard
+}
+
+// ModifyPlan previews the SQL statement that the apply will run.
+func (r *statementTimeoutResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to preview when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	if r.connect != nil && !r.allowSystemRoles {
+		resp.Diagnostics.Append(checkSystemRole(ctx, req.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(checkRoleName(ctx, req.Plan, r.roleNamePattern)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.parameterGuard.check(path.Root("timeout"), "statement_timeout")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var role types.String
+	var timeout types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("role"), &role)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("timeout"), &timeout)...)
+	if resp.Diagnostics.HasError() || role.IsUnknown() || timeout.IsUnknown() {
+		return
 	}
 
-	r.getDB = client
+	sqlstr := sqlSetStatementTimeout(role.ValueString(), timeout.ValueString())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sql"), sqlstr)...)
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -96,8 +186,9 @@ func (r *statementTimeoutResource) Create(ctx context.Context, req resource.Crea
 
 	// Create the resource
 	sqlstr := sqlSetStatementTimeout(plan.Role, plan.Timeout)
+	plan.SQL = types.StringValue(sqlstr)
 
-	db, err := r.getDB(ctx)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -106,15 +197,51 @@ func (r *statementTimeoutResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 	defer db.Close()
-	if _, err = db.ExecContext(ctx, sqlstr); err != nil {
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (*string, error) {
+		return readOriginalStatementTimeout(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "create", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if !plan.RestoreValue.IsNull() && !setPrivate(ctx, &resp.Diagnostics, resp.Private, privateRestoreValueKey, plan.RestoreValue.ValueString()) {
+		return
+	}
+	if r.verifyWrites {
+		actual, err := readStatementTimeout(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("timeout", plan.Timeout, actual)) {
+			return
+		}
+	}
+
+	effective, err := readEffectiveStatementTimeout(ctx, db, plan.Role)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query effective statement_timeout value for role %s: %s", plan.Role, err)+sqlErrorDetails(plan.Role, err),
 		)
 		return
 	}
+	plan.EffectiveTimeout = types.StringValue(effective)
 
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -133,7 +260,7 @@ func (r *statementTimeoutResource) Read(ctx context.Context, req resource.ReadRe
 	}
 
 	// Read the current value from the database
-	db, err := r.getDB(ctx)
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -143,29 +270,54 @@ func (r *statementTimeoutResource) Read(ctx context.Context, req resource.ReadRe
 	}
 	defer db.Close()
 
-	var timeoutSetting string
-	sqlstr := `SELECT setting
-FROM (
-	SELECT UNNEST(rolconfig) AS setting
-	FROM pg_roles
-	WHERE rolname = $1
-) t
-WHERE setting LIKE 'statement_timeout=%' LIMIT 1;`
-	err = db.QueryRowContext(ctx, sqlstr, state.Role).Scan(&timeoutSetting)
-	switch { // Overwrite the state with the actual value
-	case errors.Is(err, sql.ErrNoRows):
-		state.Timeout = "0s"
-	case err == nil:
-		state.Timeout = strings.TrimPrefix(timeoutSetting, "statement_timeout=")
-	default:
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+	timeout, err := readStatementTimeout(ctx, db, state.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Role not found, removing resource from state", map[string]any{
+			"role": state.Role,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query statement_timeout value for role %s: %s", state.Role, err),
+		)
+		return
+	}
+
+	// Keep the configured form of equivalent values, e.g. "1min" for "60s"
+	if sameQuantity(state.Timeout, timeout, "ms") {
+		timeout = state.Timeout
+	}
+	logApplied(ctx, req.Private, state.Role, state.SQL.ValueString())
+	addDriftWarning(&resp.Diagnostics, state.Role, "timeout", state.Timeout, timeout)
+
+	effective, err := readEffectiveStatementTimeout(ctx, db, state.Role)
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query effective statement_timeout value for role %s: %s", state.Role, err),
 		)
 		return
 	}
 
+	overrides, err := readDatabaseStatementTimeouts(ctx, db, state.Role)
+	if err != nil {
+		addReadError(&resp.Diagnostics, state.Role, err,
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query database statement_timeout values for role %s: %s", state.Role, err),
+		)
+		return
+	}
+	addDatabaseOverridesWarning(&resp.Diagnostics, state.Role, overrides)
+
+	// Overwrite the state with the actual values
+	state.Timeout = timeout
+	state.EffectiveTimeout = types.StringValue(effective)
+
 	// Set state to fully populated data
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, state.Connection, state.Role)...)
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -185,7 +337,8 @@ func (r *statementTimeoutResource) Update(ctx context.Context, req resource.Upda
 
 	// Update statement_timeout in database
 	sqlstr := sqlSetStatementTimeout(plan.Role, plan.Timeout)
-	db, err := r.getDB(ctx)
+	plan.SQL = types.StringValue(sqlstr)
+	db, err := r.connect(plan.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -194,15 +347,48 @@ func (r *statementTimeoutResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
+
+	policy, err := r.retry.override(plan.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
+		)
+		return
+	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, plan.Role, "") {
+		return
+	}
+	if plan.Adopt && !recordMissingOriginal(ctx, &resp.Diagnostics, resp.Private, plan.Role, func() (*string, error) {
+		return readOriginalStatementTimeout(ctx, db, plan.Role)
+	}) {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "update", plan.Role, err)
+		return
+	}
+	recordApplied(ctx, &resp.Diagnostics, resp.Private, db, sqlstr)
+	if r.verifyWrites {
+		actual, err := readStatementTimeout(ctx, db, plan.Role)
+		if !verifyWrite(&resp.Diagnostics, plan.Role, err, discrepancy("timeout", plan.Timeout, actual)) {
+			return
+		}
+	}
+
+	effective, err := readEffectiveStatementTimeout(ctx, db, plan.Role)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query effective statement_timeout value for role %s: %s", plan.Role, err)+sqlErrorDetails(plan.Role, err),
 		)
 		return
 	}
+	plan.EffectiveTimeout = types.StringValue(effective)
 
 	// Set state to updated value
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, plan.Connection, plan.Role)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -220,9 +406,32 @@ func (r *statementTimeoutResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 
-	// Reset statement_timeout in database
+	if !checkDeletionProtection(&resp.Diagnostics, state.Role, state.DeletionProtection) {
+		return
+	}
+	if state.SkipResetOnDestroy {
+		tflog.Info(ctx, "Skipping reset on destroy for role", map[string]any{
+			"role": state.Role,
+		})
+		return
+	}
+
+	// Reset statement_timeout in database, or restore the configured or
+	// original value
 	sqlstr := sqlResetStatementTimeout(state.Role)
-	db, err := r.getDB(ctx)
+	restoreValue := state.RestoreValue
+	var captured string
+	if getRestoreValue(ctx, &resp.Diagnostics, req.Private, &captured) {
+		restoreValue = types.StringValue(captured)
+	}
+	var original *string
+	switch {
+	case !restoreValue.IsNull():
+		sqlstr = sqlSetStatementTimeout(state.Role, restoreValue.ValueString())
+	case state.Adopt && getOriginal(ctx, &resp.Diagnostics, req.Private, &original) && original != nil:
+		sqlstr = sqlSetStatementTimeout(state.Role, *original)
+	}
+	db, err := r.connect(state.Connection.ValueString(), "").GetDB(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get database connection",
@@ -231,24 +440,250 @@ func (r *statementTimeoutResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 	defer db.Close()
-	if _, err := db.ExecContext(ctx, sqlstr); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to execute SQL",
-			"Failed to execute SQL: "+err.Error(),
+
+	policy, err := r.retry.override(state.Retry)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry configuration",
+			err.Error(),
 		)
 		return
 	}
+	if !checkPrivileges(ctx, db, &resp.Diagnostics, state.Role, "") {
+		return
+	}
+	if err := execWithRetry(ctx, db, policy, sqlstr); err != nil {
+		addSQLError(&resp.Diagnostics, "delete", state.Role, err)
+		return
+	}
 }
 
 func (r *statementTimeoutResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.State.SetAttribute(ctx, path.Root("timeout"), "0s")
-	resource.ImportStatePassthroughID(ctx, path.Root("role"), req, resp)
+	connection, diags := importConnection(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.connect(connection.ValueString(), "").GetDB(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get database connection",
+			"Failed to get database connection: "+err.Error(),
+		)
+		return
+	}
+	defer db.Close()
+
+	role, diags := importRole(ctx, db, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, err := readStatementTimeout(ctx, db, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Role not found",
+			fmt.Sprintf("Cannot import role %s: role does not exist", role),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query statement_timeout value for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	effective, err := readEffectiveStatementTimeout(ctx, db, role)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query effective statement_timeout value for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+
+	overrides, err := readDatabaseStatementTimeouts(ctx, db, role)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to query statement_timeout value",
+			fmt.Sprintf("Failed to query database statement_timeout values for role %s: %s", role, err)+sqlErrorDetails(role, err),
+		)
+		return
+	}
+	addDatabaseOverridesWarning(&resp.Diagnostics, role, overrides)
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("timeout"), timeout)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("effective_timeout"), effective)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("connection"), connection)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("deletion_protection"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("skip_reset_on_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sql"), sqlSetStatementTimeout(role, timeout))...)
+	resp.Diagnostics.Append(setRoleIdentity(ctx, resp.Identity, connection, role)...)
+}
+
+// MoveState moves the state of a postgresql_role resource of the community
+// PostgreSQL provider into this resource.
+func (r *statementTimeoutResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{
+			StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+				var source postgresqlRoleState
+				if !readPostgresqlState(req, resp, "postgresql_role", &source) {
+					return
+				}
+
+				timeout, err := statementTimeoutFromMilliseconds(source.StatementTimeout)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Unable to move resource state",
+						fmt.Sprintf("Cannot move statement_timeout of role %s: %s", source.Name, err),
+					)
+					return
+				}
+
+				target := statementTimeoutModel{
+					Role:    source.Name,
+					Timeout: timeout,
+					SQL:     types.StringValue(sqlSetStatementTimeout(source.Name, timeout)),
+				}
+				resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
+				resp.Diagnostics.Append(setRoleIdentity(ctx, resp.TargetIdentity, types.StringNull(), source.Name)...)
+			},
+		},
+	}
+}
+
+// readStatementTimeout returns the statement_timeout set on the role, "0s"
+// if none is set, or sql.ErrNoRows if the role does not exist.
+func readStatementTimeout(ctx context.Context, db *sql.DB, role string) (string, error) {
+	timeout, ok, err := readRoleSetting(ctx, db, role, "statement_timeout")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "0s", nil
+	}
+	return timeout, nil
+}
+
+// readOriginalStatementTimeout returns the statement_timeout set on the role,
+// nil if none is set, for adopting resources to restore on destroy.
+func readOriginalStatementTimeout(ctx context.Context, db *sql.DB, role string) (*string, error) {
+	timeout, ok, err := readRoleSetting(ctx, db, role, "statement_timeout")
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &timeout, nil
+}
+
+// sqlEffectiveStatementTimeout reads the statement_timeout set on role $1 in
+// the current database, on the role, and on the current database, followed by
+// the server default. The reset value of the session is its own default,
+// unless it comes from settings of the connecting role or database, in which
+// case the built-in default is the best approximation of the server one.
+const sqlEffectiveStatementTimeout = `
+SELECT
+	(SELECT s.setconfig FROM pg_db_role_setting s JOIN pg_database d ON d.oid = s.setdatabase
+		WHERE s.setrole = r.oid AND d.datname = current_database()),
+	(SELECT s.setconfig FROM pg_db_role_setting s WHERE s.setrole = r.oid AND s.setdatabase = 0),
+	(SELECT s.setconfig FROM pg_db_role_setting s JOIN pg_database d ON d.oid = s.setdatabase
+		WHERE s.setrole = 0 AND d.datname = current_database()),
+	(SELECT CASE WHEN source IN ('client', 'database', 'user', 'database user') THEN boot_val ELSE reset_val END
+		FROM pg_settings WHERE name = 'statement_timeout')
+FROM pg_roles r
+WHERE r.rolname = $1;`
+
+// readEffectiveStatementTimeout returns the statement_timeout in effect for
+// new sessions of the role in the current database, or sql.ErrNoRows if the
+// role does not exist.
+func readEffectiveStatementTimeout(ctx context.Context, db *sql.DB, role string) (string, error) {
+	var roleDatabase, roleAll, database pq.StringArray
+	var server string
+	err := db.QueryRowContext(ctx, sqlEffectiveStatementTimeout, role).Scan(&roleDatabase, &roleAll, &database, &server)
+	if err != nil {
+		return "", err
+	}
+	for _, config := range []pq.StringArray{roleDatabase, roleAll, database} {
+		if timeout, ok := parseRoleConfig(config)["statement_timeout"]; ok {
+			return timeout, nil
+		}
+	}
+
+	// The server default is in milliseconds
+	ms, err := strconv.ParseInt(server, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid server statement_timeout %q: %w", server, err)
+	}
+	if ms%1000 != 0 {
+		return fmt.Sprintf("%dms", ms), nil
+	}
+	return fmt.Sprintf("%ds", ms/1000), nil
+}
+
+// sqlDatabaseStatementTimeouts reads the settings of role $1 scoped to a
+// database, set with ALTER ROLE ... IN DATABASE.
+const sqlDatabaseStatementTimeouts = `
+SELECT d.datname, s.setconfig
+FROM pg_db_role_setting s
+JOIN pg_roles r ON r.oid = s.setrole
+JOIN pg_database d ON d.oid = s.setdatabase
+WHERE r.rolname = $1
+ORDER BY d.datname;`
+
+// readDatabaseStatementTimeouts returns the statement_timeout set on the role
+// in specific databases, by database name.
+func readDatabaseStatementTimeouts(ctx context.Context, db *sql.DB, role string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, sqlDatabaseStatementTimeouts, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := map[string]string{}
+	for rows.Next() {
+		var database string
+		var config pq.StringArray
+		if err := rows.Scan(&database, &config); err != nil {
+			return nil, err
+		}
+		if timeout, ok := parseRoleConfig(config)["statement_timeout"]; ok {
+			overrides[database] = timeout
+		}
+	}
+	return overrides, rows.Err()
+}
+
+// addDatabaseOverridesWarning warns that the statement_timeout of the role is
+// overridden in some databases, which the role-level setting managed by the
+// resource does not apply to.
+func addDatabaseOverridesWarning(diags *diag.Diagnostics, role string, overrides map[string]string) {
+	if len(overrides) == 0 {
+		return
+	}
+	var values []string
+	for _, database := range sortedKeys(overrides) {
+		values = append(values, fmt.Sprintf("%s = %s", database, overrides[database]))
+	}
+	diags.AddAttributeWarning(
+		path.Root("timeout"),
+		"Database-scoped statement_timeout overrides",
+		fmt.Sprintf("Role %s has statement_timeout set in specific databases, which take precedence over the value managed by this resource in those databases: %s. "+
+			"Remove them with ALTER ROLE ... IN DATABASE ... RESET statement_timeout if the role-level value should apply everywhere.",
+			role, strings.Join(values, ", ")),
+	)
 }
 
 func sqlSetStatementTimeout(role, timeout string) string {
-	return fmt.Sprintf("ALTER ROLE %q SET statement_timeout = '%s';", role, timeout)
+	return fmt.Sprintf("ALTER ROLE %s SET statement_timeout = %s;", quoteIdentifier(role), pq.QuoteLiteral(timeout))
 }
 
 func sqlResetStatementTimeout(role string) string {
-	return fmt.Sprintf("ALTER ROLE %q RESET statement_timeout;", role)
+	return fmt.Sprintf("ALTER ROLE %s RESET statement_timeout;", quoteIdentifier(role))
 }
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestSqlSetStatementTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    string
+	}{
+		{"100s", `ALTER ROLE "test" SET statement_timeout = '100s';`},
+		{"0'; DROP ROLE test; --", `ALTER ROLE "test" SET statement_timeout = '0''; DROP ROLE test; --';`},
+		{`1s\'`, `ALTER ROLE "test" SET statement_timeout =  E'1s\\''';`},
+	}
+	for _, tt := range tests {
+		if got := sqlSetStatementTimeout("test", tt.timeout); got != tt.want {
+			t.Errorf("sqlSetStatementTimeout(%q) = %q, want %q", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestReadEffectiveStatementTimeout(t *testing.T) {
+	columns := []string{"role_database", "role", "database", "server"}
+	tests := []struct {
+		name string
+		row  []driver.Value
+		want string
+	}{
+		{
+			name: "role in database",
+			row:  []driver.Value{"{statement_timeout=5s}", "{statement_timeout=100s,work_mem=64MB}", "{statement_timeout=1min}", "0"},
+			want: "5s",
+		},
+		{
+			name: "role",
+			row:  []driver.Value{"{work_mem=64MB}", "{statement_timeout=100s}", "{statement_timeout=1min}", "0"},
+			want: "100s",
+		},
+		{
+			name: "database",
+			row:  []driver.Value{nil, nil, "{statement_timeout=1min}", "0"},
+			want: "1min",
+		},
+		{
+			name: "server default",
+			row:  []driver.Value{nil, nil, nil, "30000"},
+			want: "30s",
+		},
+		{
+			name: "server default in milliseconds",
+			row:  []driver.Value{nil, nil, nil, "1500"},
+			want: "1500ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := fakedb.New().ExpectQuery(`pg_db_role_setting`, columns, tt.row).GetDB(ctx)
+			if err != nil {
+				t.Fatalf("GetDB() error = %v", err)
+			}
+			defer db.Close()
+
+			got, err := readEffectiveStatementTimeout(ctx, db, "example_user")
+			if err != nil {
+				t.Fatalf("readEffectiveStatementTimeout() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readEffectiveStatementTimeout() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDatabaseStatementTimeouts(t *testing.T) {
+	ctx := context.Background()
+	db, err := fakedb.New().ExpectQuery(`pg_db_role_setting`, []string{"datname", "setconfig"},
+		[]driver.Value{"analytics", "{statement_timeout=10min,work_mem=256MB}"},
+		[]driver.Value{"app", "{work_mem=64MB}"},
+		[]driver.Value{"reporting", "{statement_timeout=0}"},
+	).GetDB(ctx)
+	if err != nil {
+		t.Fatalf("GetDB() error = %v", err)
+	}
+	defer db.Close()
+
+	got, err := readDatabaseStatementTimeouts(ctx, db, "example_user")
+	if err != nil {
+		t.Fatalf("readDatabaseStatementTimeouts() error = %v", err)
+	}
+	want := map[string]string{"analytics": "10min", "reporting": "0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readDatabaseStatementTimeouts() = %v, want %v", got, want)
+	}
+}
+
+func TestAddDatabaseOverridesWarning(t *testing.T) {
+	var diags diag.Diagnostics
+	addDatabaseOverridesWarning(&diags, "app", map[string]string{})
+	if len(diags) != 0 {
+		t.Fatalf("addDatabaseOverridesWarning() without overrides = %v, want no diagnostics", diags)
+	}
+
+	addDatabaseOverridesWarning(&diags, "app", map[string]string{"reporting": "0", "analytics": "10min"})
+	if len(diags) != 1 || diags.WarningsCount() != 1 {
+		t.Fatalf("addDatabaseOverridesWarning() = %v, want one warning", diags)
+	}
+	if detail := diags[0].Detail(); !strings.Contains(detail, "analytics = 10min, reporting = 0") {
+		t.Errorf("addDatabaseOverridesWarning() detail = %q, want the sorted overrides", detail)
+	}
+}
+
+func TestStatementTimeoutRestoreValue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		CheckDestroy: func(*terraform.State) error {
+			ctx := context.Background()
+			db, err := GetStandardPostgresGetter(testDSN, nil)(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			defer testAccExecSQL(t, `ALTER ROLE "example_user" RESET statement_timeout;`)()
+			timeout, err := readStatementTimeout(ctx, db, "example_user")
+			if err != nil {
+				return err
+			}
+			// The restore_value captured on create is restored
+			if timeout != "30s" {
+				return fmt.Errorf("statement_timeout after destroy = %q, want 30s", timeout)
+			}
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "pgrole_statement_timeout" "test" {
+  role          = "example_user"
+  timeout       = "100s"
+  restore_value = "30s"
+}
+`,
+			},
+			// Changing restore_value later does not change the captured one
+			{
+				Config: providerConfig + `
+resource "pgrole_statement_timeout" "test" {
+  role          = "example_user"
+  timeout       = "100s"
+  restore_value = "60s"
+}
+`,
+				Check: resource.TestCheckResourceAttr("pgrole_statement_timeout.test", "restore_value", "60s"),
+			},
+		},
+	})
+}
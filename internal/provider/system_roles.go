@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// systemRoles are the reserved roles besides the predefined pg_* roles: the
+// bootstrap superuser and the administration roles of managed services.
+var systemRoles = []string{"postgres", "cloudsqladmin", "rdsadmin", "azure_pg_admin"}
+
+// isSystemRole reports whether role is reserved to the server or its managed
+// service.
+func isSystemRole(role string) bool {
+	return strings.HasPrefix(role, "pg_") || slices.Contains(systemRoles, role)
+}
+
+// checkSystemRole returns an error on the role attribute of plan when it is a
+// reserved role, for resources to refuse at plan time unless the provider
+// sets allow_system_roles.
+func checkSystemRole(ctx context.Context, plan tfsdk.Plan) diag.Diagnostics {
+	var role types.String
+	diags := plan.GetAttribute(ctx, path.Root("role"), &role)
+	if diags.HasError() || !isSystemRole(role.ValueString()) {
+		return diags
+	}
+	diags.AddAttributeError(
+		path.Root("role"),
+		"System role",
+		fmt.Sprintf("Role %s is reserved to the server or its managed service, and changing it can break them, e.g. lock out the administration of a managed instance. Set allow_system_roles to true on the provider to manage it anyway.", role.ValueString()),
+	)
+	return diags
+}
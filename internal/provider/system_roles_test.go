@@ -0,0 +1,22 @@
+package provider
+
+import "testing"
+
+func TestIsSystemRole(t *testing.T) {
+	tests := map[string]bool{
+		"postgres":          true,
+		"cloudsqladmin":     true,
+		"rdsadmin":          true,
+		"azure_pg_admin":    true,
+		"pg_read_all_data":  true,
+		"pg_monitor":        true,
+		"app":               false,
+		"postgres_exporter": false,
+		"cloudsqlsuperuser": false,
+	}
+	for role, want := range tests {
+		if got := isSystemRole(role); got != want {
+			t.Errorf("isSystemRole(%q) = %t, want %t", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewTCPKeepalivesResource is a helper function to simplify the provider implementation.
+func NewTCPKeepalivesResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "tcp_keepalives",
+		description: `Manage the TCP keepalive settings of an existing role, e.g. for roles connecting over flaky network paths. Settings left unset are reset to the database default, and 0 uses the operating system default.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-connection.html#GUC-TCP-KEEPALIVES-IDLE) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "idle",
+				Parameter:   "tcp_keepalives_idle",
+				Description: "The number of seconds of inactivity after which a keepalive is sent.",
+				Type:        settingInt64,
+				Unit:        "s",
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "interval",
+				Parameter:   "tcp_keepalives_interval",
+				Description: "The number of seconds after which an unacknowledged keepalive is retransmitted.",
+				Type:        settingInt64,
+				Unit:        "s",
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			{
+				Attribute:   "count",
+				Parameter:   "tcp_keepalives_count",
+				Description: "The number of lost keepalives before the connection is considered dead.",
+				Type:        settingInt64,
+				Int64Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestTCPKeepalivesResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_tcp_keepalives" "test" {
+  role     = "example_user"
+  idle     = 60
+  interval = 10
+  count    = 6
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_tcp_keepalives.test", "idle", "60"),
+					resource.TestCheckResourceAttr("pgrole_tcp_keepalives.test", "interval", "10"),
+					resource.TestCheckResourceAttr("pgrole_tcp_keepalives.test", "count", "6"),
+					resource.TestCheckResourceAttr("pgrole_tcp_keepalives.test", "sql", `ALTER ROLE "example_user" SET tcp_keepalives_idle = 60;
+ALTER ROLE "example_user" SET tcp_keepalives_interval = 10;
+ALTER ROLE "example_user" SET tcp_keepalives_count = 6;`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_tcp_keepalives.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" RESET tcp_keepalives_count;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
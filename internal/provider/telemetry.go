@@ -0,0 +1,350 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Telemetry events, each counted with its errors and total duration.
+const (
+	telemetryConnect = "connect"
+	telemetryQuery   = "query"
+)
+
+// telemetryCounters are the names of the counters of the events.
+var telemetryCounters = map[string]string{
+	telemetryConnect: "connects",
+	telemetryQuery:   "queries",
+}
+
+// telemetryPushTimeout bounds each push to the Prometheus Pushgateway. Pushes
+// happen in the background, so they never delay the statements.
+const telemetryPushTimeout = 2 * time.Second
+
+// activeTelemetry is the telemetry of the last Configure sending metrics, the
+// only one of the process, stopped when replaced or by FlushTelemetry.
+var activeTelemetry struct {
+	mu sync.Mutex
+	t  *telemetry
+}
+
+// telemetryModel describes the telemetry provider attribute.
+type telemetryModel struct {
+	StatsdAddress  types.String `tfsdk:"statsd_address"`
+	PushgatewayURL types.String `tfsdk:"pushgateway_url"`
+	Prefix         types.String `tfsdk:"prefix"`
+}
+
+// providerTelemetryAttribute returns the schema of the telemetry provider
+// attribute.
+func providerTelemetryAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "Where to send the metrics of the provider: the counts and durations of its connections and of the SQL statements applying changes, and the count of their retries. The metrics are always logged at TRACE level, e.g. with TF_LOG=trace, and sent to the configured endpoints otherwise. Useful for fleet operators running many workspaces.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"statsd_address": schema.StringAttribute{
+				Description: "The host:port of a StatsD server the metrics are sent to over UDP, as counters and timers.",
+				Optional:    true,
+			},
+			"pushgateway_url": schema.StringAttribute{
+				Description: "The URL the metrics are pushed to, in the background after events and when the provider exits, with their grouping key, e.g. \"http://pushgateway:9091/metrics/job/terraform/workspace/prod\". Each push replaces the metrics of the grouping key with the totals of the current run of the provider, i.e. of one plan or apply, so the counters are per run rather than cumulative: include a label unique to the run in the grouping key, e.g. \".../run/${var.run_id}\", to keep the metrics of every run. See the [Prometheus Pushgateway](https://github.com/prometheus/pushgateway) documentation.",
+				Optional:    true,
+			},
+			"prefix": schema.StringAttribute{
+				Description: "Prefix of the metric names. Defaults to pgrole.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// telemetry counts the connections and SQL statements of the provider. The
+// nil telemetry records nothing.
+type telemetry struct {
+	prefix         string
+	statsd         net.Conn
+	pushgatewayURL string
+	client         *http.Client
+
+	mu sync.Mutex
+	// stopped is set by stop, after which the events are no longer sent.
+	stopped bool
+	// counters are the counts of events, their errors and retries, by
+	// metric name without prefix, e.g. "query_errors".
+	counters map[string]int64
+	// durations are the total durations of events, by event name.
+	durations map[string]time.Duration
+
+	// pushes requests a push from the background pusher, with the context
+	// of the last event, coalescing the events happening during a push.
+	pushes chan context.Context
+	// done stops the background pusher.
+	done chan struct{}
+	// pushMu serializes the pushes, so that the last one sends the latest
+	// totals.
+	pushMu sync.Mutex
+}
+
+// newTelemetry returns the telemetry configured by m, which may be nil. A
+// telemetry sending metrics replaces the one of the previous Configure, if
+// any, which is stopped after pushing its final totals.
+func newTelemetry(ctx context.Context, m *telemetryModel) (*telemetry, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	t := &telemetry{
+		prefix:    "pgrole",
+		client:    &http.Client{Timeout: telemetryPushTimeout},
+		counters:  map[string]int64{},
+		durations: map[string]time.Duration{},
+	}
+	if m == nil {
+		return t, diags
+	}
+
+	p := path.Root("telemetry")
+	if m.StatsdAddress.IsUnknown() || m.PushgatewayURL.IsUnknown() || m.Prefix.IsUnknown() {
+		diags.AddAttributeError(p, "unknown telemetry", "unknown telemetry")
+		return nil, diags
+	}
+	if !m.Prefix.IsNull() {
+		t.prefix = m.Prefix.ValueString()
+		if !parameterNameRe.MatchString(t.prefix) || strings.Contains(t.prefix, ".") {
+			diags.AddAttributeError(p.AtName("prefix"), "Invalid telemetry configuration", fmt.Sprintf("%q is not a valid metric name prefix: use lowercase letters, digits and underscores.", t.prefix))
+		}
+	}
+	if !m.StatsdAddress.IsNull() {
+		conn, err := net.Dial("udp", m.StatsdAddress.ValueString())
+		if err != nil {
+			diags.AddAttributeError(p.AtName("statsd_address"), "Invalid telemetry configuration", err.Error())
+		}
+		t.statsd = conn
+	}
+	if !m.PushgatewayURL.IsNull() {
+		u, err := url.Parse(m.PushgatewayURL.ValueString())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			diags.AddAttributeError(p.AtName("pushgateway_url"), "Invalid telemetry configuration", fmt.Sprintf("%q is not an http or https URL.", m.PushgatewayURL.ValueString()))
+		}
+		t.pushgatewayURL = m.PushgatewayURL.ValueString()
+	}
+	if diags.HasError() {
+		if t.statsd != nil {
+			t.statsd.Close()
+		}
+		return nil, diags
+	}
+	if t.statsd == nil && t.pushgatewayURL == "" {
+		return t, diags
+	}
+
+	if t.pushgatewayURL != "" {
+		t.pushes = make(chan context.Context, 1)
+		t.done = make(chan struct{})
+		go t.pusher()
+	}
+	activeTelemetry.mu.Lock()
+	previous := activeTelemetry.t
+	activeTelemetry.t = t
+	activeTelemetry.mu.Unlock()
+	previous.stop(ctx)
+	return t, diags
+}
+
+// record records an event, e.g. telemetryQuery, that took d and failed with
+// err unless nil.
+func (t *telemetry) record(ctx context.Context, event string, d time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.counters[telemetryCounters[event]]++
+	if err != nil {
+		t.counters[event+"_errors"]++
+	}
+	t.durations[event] += d
+	fields := t.fields()
+	t.mu.Unlock()
+
+	fields["event"] = event
+	fields["duration_ms"] = d.Milliseconds()
+	tflog.Trace(ctx, "Telemetry", fields)
+
+	lines := []string{
+		fmt.Sprintf("%s.%s:1|c", t.prefix, telemetryCounters[event]),
+		fmt.Sprintf("%s.%s_duration:%d|ms", t.prefix, event, d.Milliseconds()),
+	}
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("%s.%s_errors:1|c", t.prefix, event))
+	}
+	t.send(ctx, lines)
+}
+
+// retry records the retry of a SQL statement.
+func (t *telemetry) retry(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.counters["retries"]++
+	fields := t.fields()
+	t.mu.Unlock()
+
+	fields["event"] = "retry"
+	tflog.Trace(ctx, "Telemetry", fields)
+	t.send(ctx, []string{t.prefix + ".retries:1|c"})
+}
+
+// fields returns the totals as log fields. t.mu must be held.
+func (t *telemetry) fields() map[string]any {
+	fields := make(map[string]any, len(t.counters)+len(t.durations))
+	for name, count := range t.counters {
+		fields[name] = count
+	}
+	for event, d := range t.durations {
+		fields[event+"_duration_ms"] = d.Milliseconds()
+	}
+	return fields
+}
+
+// send sends the StatsD lines, and requests a push of the totals to the
+// Pushgateway, without waiting for it. Failures are logged, never reported
+// to the user.
+func (t *telemetry) send(ctx context.Context, lines []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	if t.statsd != nil {
+		if _, err := t.statsd.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+			tflog.Warn(ctx, "Failed to send metrics to StatsD", map[string]any{"error": err.Error()})
+		}
+	}
+	if t.pushes != nil {
+		select {
+		case t.pushes <- context.WithoutCancel(ctx):
+		default:
+			// A push is already pending, and will send these totals
+		}
+	}
+}
+
+// pusher pushes the totals to the Pushgateway when requested by send, until
+// the telemetry is stopped.
+func (t *telemetry) pusher() {
+	for {
+		select {
+		case ctx := <-t.pushes:
+			if err := t.push(ctx); err != nil {
+				tflog.Warn(ctx, "Failed to push metrics to the Pushgateway", map[string]any{"error": err.Error()})
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// stop stops sending the events, pushes the final totals to the Pushgateway
+// and closes the StatsD connection. Stopping the nil or a stopped telemetry
+// does nothing.
+func (t *telemetry) stop(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	t.stopped = true
+	if t.statsd != nil {
+		t.statsd.Close()
+	}
+	t.mu.Unlock()
+
+	if t.done != nil {
+		close(t.done)
+		if err := t.push(ctx); err != nil {
+			tflog.Warn(ctx, "Failed to push metrics to the Pushgateway", map[string]any{"error": err.Error()})
+		}
+	}
+}
+
+// FlushTelemetry stops the telemetry of the provider, pushing its final
+// totals to the Pushgateway, if any, for the main package to call once the
+// provider server stops.
+func FlushTelemetry(ctx context.Context) {
+	activeTelemetry.mu.Lock()
+	t := activeTelemetry.t
+	activeTelemetry.t = nil
+	activeTelemetry.mu.Unlock()
+	t.stop(ctx)
+}
+
+// push replaces the metrics of the grouping key of the Pushgateway with the
+// totals, within telemetryPushTimeout.
+func (t *telemetry) push(ctx context.Context) error {
+	t.pushMu.Lock()
+	defer t.pushMu.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, telemetryPushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.pushgatewayURL, strings.NewReader(t.exposition()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// exposition returns the totals in the Prometheus text format, sorted by
+// metric name.
+func (t *telemetry) exposition() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := map[string]string{}
+	for name, count := range t.counters {
+		metrics[fmt.Sprintf("%s_%s_total", t.prefix, name)] = fmt.Sprint(count)
+	}
+	for event, d := range t.durations {
+		metrics[fmt.Sprintf("%s_%s_duration_seconds_total", t.prefix, event)] = fmt.Sprint(d.Seconds())
+	}
+	var b strings.Builder
+	for _, name := range sortedKeys(metrics) {
+		fmt.Fprintf(&b, "# TYPE %s counter\n%s %s\n", name, name, metrics[name])
+	}
+	return b.String()
+}
+
+// instrument returns a getter recording the connections of getter.
+func (t *telemetry) instrument(getter DBGetter) DBGetter {
+	if t == nil {
+		return getter
+	}
+	return F(func(ctx context.Context) (*sql.DB, error) {
+		start := time.Now()
+		db, err := getter.GetDB(ctx)
+		t.record(ctx, telemetryConnect, time.Since(start), err)
+		return db, err
+	})
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestTelemetry(t *testing.T) {
+	ctx := context.Background()
+	statsd, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer statsd.Close()
+
+	var mu sync.Mutex
+	var pushed string
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/metrics/job/terraform" {
+			t.Errorf("pushed with %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushed = string(body)
+		mu.Unlock()
+	}))
+	defer pushgateway.Close()
+
+	tel, diags := newTelemetry(ctx, &telemetryModel{
+		StatsdAddress:  types.StringValue(statsd.LocalAddr().String()),
+		PushgatewayURL: types.StringValue(pushgateway.URL + "/metrics/job/terraform"),
+		Prefix:         types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("newTelemetry() = %v", diags)
+	}
+
+	tel.record(ctx, telemetryQuery, 1500*time.Millisecond, nil)
+	buf := make([]byte, 1024)
+	n, _, err := statsd.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if got, want := string(buf[:n]), "pgrole.queries:1|c\npgrole.query_duration:1500|ms"; got != want {
+		t.Errorf("statsd = %q, want %q", got, want)
+	}
+
+	tel.retry(ctx)
+	tel.record(ctx, telemetryQuery, 500*time.Millisecond, errors.New("deadlock detected"))
+	want := `# TYPE pgrole_queries_total counter
+pgrole_queries_total 2
+# TYPE pgrole_query_duration_seconds_total counter
+pgrole_query_duration_seconds_total 2
+# TYPE pgrole_query_errors_total counter
+pgrole_query_errors_total 1
+# TYPE pgrole_retries_total counter
+pgrole_retries_total 1
+`
+	if err := tel.push(ctx); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if pushed != want {
+		t.Errorf("pushed = %q, want %q", pushed, want)
+	}
+}
+
+func TestTelemetryPushInBackground(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var pushes int
+	var last string
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushes++
+		last = string(body)
+		mu.Unlock()
+	}))
+	defer pushgateway.Close()
+
+	tel, diags := newTelemetry(ctx, &telemetryModel{
+		StatsdAddress:  types.StringNull(),
+		PushgatewayURL: types.StringValue(pushgateway.URL + "/metrics/job/terraform"),
+		Prefix:         types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("newTelemetry() = %v", diags)
+	}
+
+	// The Pushgateway does not answer before release, so the events would
+	// block if they waited for the pushes
+	for range 10 {
+		tel.record(ctx, telemetryQuery, time.Millisecond, nil)
+	}
+	close(release)
+	FlushTelemetry(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// At most one push in flight and one pending, coalescing the other
+	// events, and the final one
+	if pushes < 1 || pushes > 3 {
+		t.Errorf("pushed %d times, want between 1 and 3", pushes)
+	}
+	if !strings.Contains(last, "pgrole_queries_total 10\n") {
+		t.Errorf("pushed %q, want the totals of the 10 queries", last)
+	}
+}
+
+func TestNewTelemetryInvalid(t *testing.T) {
+	ctx := context.Background()
+	_, diags := newTelemetry(ctx, &telemetryModel{
+		StatsdAddress:  types.StringNull(),
+		PushgatewayURL: types.StringValue("pushgateway:9091"),
+		Prefix:         types.StringValue("Team.Metrics"),
+	})
+	if got := diags.ErrorsCount(); got != 2 {
+		t.Errorf("newTelemetry() = %v, want 2 errors", diags)
+	}
+	if !strings.Contains(diags[0].Detail()+diags[1].Detail(), "not an http or https URL") {
+		t.Errorf("newTelemetry() = %v, want an invalid URL error", diags)
+	}
+}
+
+func TestTelemetryReplaced(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	pushed := map[string]string{}
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushed[r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer pushgateway.Close()
+
+	first, diags := newTelemetry(ctx, &telemetryModel{
+		StatsdAddress:  types.StringNull(),
+		PushgatewayURL: types.StringValue(pushgateway.URL + "/metrics/job/first"),
+		Prefix:         types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("newTelemetry() = %v", diags)
+	}
+	first.record(ctx, telemetryQuery, time.Millisecond, nil)
+
+	// Configuring again stops the first telemetry, after its final push
+	second, diags := newTelemetry(ctx, &telemetryModel{
+		StatsdAddress:  types.StringNull(),
+		PushgatewayURL: types.StringValue(pushgateway.URL + "/metrics/job/second"),
+		Prefix:         types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("newTelemetry() = %v", diags)
+	}
+	mu.Lock()
+	if !strings.Contains(pushed["/metrics/job/first"], "pgrole_queries_total 1\n") {
+		t.Errorf("first pushed %q, want its final totals", pushed["/metrics/job/first"])
+	}
+	mu.Unlock()
+	if !first.stopped {
+		t.Error("first telemetry not stopped when replaced")
+	}
+	if activeTelemetry.t != second {
+		t.Error("second telemetry not active")
+	}
+
+	second.record(ctx, telemetryQuery, time.Millisecond, nil)
+	FlushTelemetry(ctx)
+	if activeTelemetry.t != nil {
+		t.Error("FlushTelemetry() left the telemetry active")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(pushed["/metrics/job/second"], "pgrole_queries_total 1\n") {
+		t.Errorf("second pushed %q, want its final totals", pushed["/metrics/job/second"])
+	}
+}
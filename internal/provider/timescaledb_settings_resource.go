@@ -0,0 +1,21 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// NewTimescaleDBSettingsResource is a helper function to simplify the provider implementation.
+func NewTimescaleDBSettingsResource() resource.Resource {
+	return &namespaceSettingsResource{
+		typeName: "timescaledb_settings",
+		description: `Manage TimescaleDB configuration parameters (timescaledb.*) for an existing role, e.g. timescaledb.enable_chunk_skipping.
+
+Parameters are given as a map, so that any parameter of the timescaledb namespace can be managed. Parameters removed from the map are reset to the database default. Parameters that can only be set in the server configuration, e.g. timescaledb.max_background_workers, are rejected by the database.
+
+The timescaledb extension must be installed in the database, which is checked before applying.
+
+See TimescaleDB [documentation](https://docs.timescale.com/self-hosted/latest/configuration/timescaledb-config/) for more details.`,
+		namespaces: []string{"timescaledb"},
+		check:      requireExtension("timescaledb"),
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestTimescaleDBSettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_timescaledb_settings" "test" {
+  role     = "example_user"
+  settings = {
+    "timescaledb.enable_chunk_skipping" = "on"
+  }
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckExtension(t, "timescaledb")()
+			testAccExecSQL(t, `DROP EXTENSION IF EXISTS timescaledb;`)()
+		},
+		CheckDestroy: func(*terraform.State) error {
+			testAccExecSQL(t, `DROP EXTENSION IF EXISTS timescaledb;`)()
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The extension is required
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile(`The timescaledb extension is not installed`),
+			},
+			// Create and Read testing
+			{
+				PreConfig: testAccExecSQL(t, `CREATE EXTENSION timescaledb;`),
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_timescaledb_settings.test", "settings.timescaledb.enable_chunk_skipping", "on"),
+					resource.TestCheckResourceAttr("pgrole_timescaledb_settings.test", "sql", `ALTER ROLE "example_user" SET timescaledb.enable_chunk_skipping = 'on';`),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_timescaledb_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET timescaledb.enable_chunk_skipping = 'off';`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
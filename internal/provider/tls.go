@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// tlsOptions are the TLS settings of standard PostgreSQL connections that
+// lib/pq does not support. When any is set, the provider negotiates TLS
+// itself, with postgresDialer, and lib/pq connects without SSL over it.
+type tlsOptions struct {
+	// serverName is the name the certificate of the server is verified
+	// against and sent in the SNI extension, instead of the host, if not
+	// empty, e.g. when connecting through a load balancer or a tunnel.
+	serverName string
+	// minVersion is the minimum TLS version accepted, if not zero, instead of
+	// the TLS 1.2 default of crypto/tls.
+	minVersion uint16
+	// cipherSuites are the cipher suites offered for TLS 1.2, if not empty,
+	// instead of the defaults of crypto/tls. The TLS 1.3 ones cannot be
+	// configured.
+	cipherSuites []uint16
+}
+
+// enabled reports whether the provider negotiates TLS itself.
+func (o tlsOptions) enabled() bool {
+	return o.serverName != "" || o.minVersion != 0 || len(o.cipherSuites) > 0
+}
+
+// tlsVersions are the values of min_tls_version.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// cipherSuiteNames returns the names of the cipher suites of
+// tls_cipher_suites: the ones of crypto/tls without known security issues.
+func cipherSuiteNames() []string {
+	var names []string
+	for _, suite := range tls.CipherSuites() {
+		names = append(names, suite.Name)
+	}
+	return names
+}
+
+// cipherSuiteIDs returns the IDs of the cipher suites named names, ignoring
+// unknown ones, rejected by the validators of tls_cipher_suites.
+func cipherSuiteIDs(names []string) []uint16 {
+	var ids []uint16
+	for _, name := range names {
+		for _, suite := range tls.CipherSuites() {
+			if suite.Name == name {
+				ids = append(ids, suite.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// tlsConfig returns the TLS configuration of the connection, or nil if TLS
+// is left to lib/pq or disabled. TLS with a list of hosts is negotiated by
+// the provider, since lib/pq would verify every host against the first one.
+// Like lib/pq, it verifies the certificate of the server
+// according to sslmode, against the root certificates of PGSSLROOTCERT or of
+// the system, and presents the client certificate of PGSSLCERT and PGSSLKEY,
+// or of ~/.postgresql/postgresql.crt and postgresql.key if they exist.
+func (c connectionConfig) tlsConfig() (*tls.Config, error) {
+	if !c.tls.enabled() && (len(c.hosts()) == 1 || c.sslmode == "disable") {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName:   c.tls.serverName,
+		MinVersion:   c.tls.minVersion,
+		CipherSuites: c.tls.cipherSuites,
+		// Accept renegotiation requests of the server, like lib/pq
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+	}
+
+	if rootCert := os.Getenv("PGSSLROOTCERT"); rootCert != "" {
+		pem, err := os.ReadFile(rootCert)
+		switch {
+		case err == nil:
+			config.RootCAs = x509.NewCertPool()
+			if !config.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no root certificate in %s", rootCert)
+			}
+		case c.sslmode == "require" && errors.Is(err, os.ErrNotExist):
+			// Only verify-ca and verify-full require it
+		default:
+			return nil, fmt.Errorf("error reading the root certificates of PGSSLROOTCERT: %w", err)
+		}
+	}
+	switch c.sslmode {
+	case "require":
+		// As in libpq, the certificate authority is verified if a root
+		// certificate file exists
+		config.InsecureSkipVerify = true
+		if config.RootCAs != nil {
+			config.VerifyConnection = verifyCertificateAuthority(config.RootCAs)
+		}
+	case "verify-ca":
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = verifyCertificateAuthority(config.RootCAs)
+	case "verify-full":
+	default:
+		return nil, fmt.Errorf("sslmode %q does not negotiate TLS", c.sslmode)
+	}
+
+	cert, key := os.Getenv("PGSSLCERT"), os.Getenv("PGSSLKEY")
+	if home, err := os.UserHomeDir(); err == nil {
+		if cert == "" {
+			cert = filepath.Join(home, ".postgresql", "postgresql.crt")
+		}
+		if key == "" {
+			key = filepath.Join(home, ".postgresql", "postgresql.key")
+		}
+	}
+	if _, err := os.Stat(cert); err == nil {
+		certificate, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate %s: %w", cert, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// verifyCertificateAuthority returns a function verifying that the
+// certificate of the server is signed by roots, or by the system roots if
+// nil, whatever its name.
+func verifyCertificateAuthority(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("the server presented no certificate")
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a self-signed certificate for name and 127.0.0.1,
+// and the path of its PEM file.
+func testCertificate(t *testing.T, name string) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "root.crt")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, file
+}
+
+func TestConnectionTLSConfig(t *testing.T) {
+	_, rootCert := testCertificate(t, "db.example.com")
+	t.Setenv("HOME", t.TempDir())
+
+	if config, err := (connectionConfig{sslmode: "verify-full"}).tlsConfig(); config != nil || err != nil {
+		t.Errorf("tlsConfig() = %v, %v, want nil without TLS options", config, err)
+	}
+
+	tests := []struct {
+		name        string
+		sslmode     string
+		rootCert    string
+		wantVerify  bool
+		wantSkip    bool
+		wantErrPart string
+	}{
+		{name: "require", sslmode: "require", wantSkip: true},
+		{name: "require with missing root certificate", sslmode: "require", rootCert: "/nonexistent/root.crt", wantSkip: true},
+		{name: "require with root certificate", sslmode: "require", rootCert: rootCert, wantSkip: true, wantVerify: true},
+		{name: "verify-ca", sslmode: "verify-ca", rootCert: rootCert, wantSkip: true, wantVerify: true},
+		{name: "verify-full", sslmode: "verify-full", rootCert: rootCert},
+		{name: "verify-full with missing root certificate", sslmode: "verify-full", rootCert: "/nonexistent/root.crt", wantErrPart: "PGSSLROOTCERT"},
+		{name: "disable", sslmode: "disable", wantErrPart: "does not negotiate TLS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PGSSLROOTCERT", tt.rootCert)
+			c := connectionConfig{sslmode: tt.sslmode, tls: tlsOptions{serverName: "db.example.com"}}
+			config, err := c.tlsConfig()
+			if tt.wantErrPart != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrPart) {
+					t.Errorf("tlsConfig() error = %v, want an error containing %q", err, tt.wantErrPart)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tlsConfig() error = %v", err)
+			}
+			if config.ServerName != "db.example.com" {
+				t.Errorf("ServerName = %q, want db.example.com", config.ServerName)
+			}
+			if config.InsecureSkipVerify != tt.wantSkip {
+				t.Errorf("InsecureSkipVerify = %v, want %v", config.InsecureSkipVerify, tt.wantSkip)
+			}
+			if (config.VerifyConnection != nil) != tt.wantVerify {
+				t.Errorf("VerifyConnection set = %v, want %v", config.VerifyConnection != nil, tt.wantVerify)
+			}
+		})
+	}
+}
+
+func TestCipherSuiteIDs(t *testing.T) {
+	got := cipherSuiteIDs([]string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_RSA_WITH_RC4_128_SHA", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"})
+	want := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cipherSuiteIDs() = %v, want %v", got, want)
+	}
+	for _, name := range cipherSuiteNames() {
+		if name == "TLS_RSA_WITH_RC4_128_SHA" {
+			t.Errorf("cipherSuiteNames() contains the insecure %s", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = (*toAlterStatementsFunction)(nil)
+
+// NewToAlterStatementsFunction is a helper function to simplify the provider implementation.
+func NewToAlterStatementsFunction() function.Function {
+	return &toAlterStatementsFunction{}
+}
+
+type toAlterStatementsFunction struct{}
+
+// Metadata returns the function name.
+func (f *toAlterStatementsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "to_alter_statements"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *toAlterStatementsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Render role settings as ALTER ROLE statements",
+		MarkdownDescription: "Converts a map of configuration parameter name to value into the ALTER ROLE ... SET statements the provider runs to apply them, sorted by parameter name, e.g. to generate migration scripts from the same data as the pgrole_config_map resource.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "role",
+				MarkdownDescription: "Name of the role.",
+			},
+			function.MapParameter{
+				Name:                "settings",
+				MarkdownDescription: "Map of configuration parameter name to value, e.g. { statement_timeout = \"30s\" }.",
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+// Run renders the statements.
+func (f *toAlterStatementsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var role string
+	var settings map[string]string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &role, &settings))
+	if resp.Error != nil {
+		return
+	}
+
+	statements, err := sqlAlterStatements(role, settings)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, statements))
+}
+
+// sqlAlterStatements returns the statements setting the parameters of settings
+// for role in all databases, sorted by parameter name.
+func sqlAlterStatements(role string, settings map[string]string) ([]string, error) {
+	statements := make([]string, 0, len(settings))
+	for _, name := range sortedKeys(settings) {
+		if !parameterNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid parameter name %q: must be a lowercase parameter name, e.g. statement_timeout or pgaudit.log", name)
+		}
+		statements = append(statements, sqlSetRoleSetting(role, "", name, pq.QuoteLiteral(settings[name])))
+	}
+	return statements, nil
+}
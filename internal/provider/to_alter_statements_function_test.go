@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestToAlterStatementsFunction(t *testing.T) {
+	ctx := context.Background()
+	settings := func(values map[string]string) types.Map {
+		elements := map[string]attr.Value{}
+		for k, v := range values {
+			elements[k] = types.StringValue(v)
+		}
+		return types.MapValueMust(types.StringType, elements)
+	}
+	tests := []struct {
+		name     string
+		settings types.Map
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "sorted",
+			settings: settings(map[string]string{"work_mem": "64MB", "statement_timeout": "30s", "pgaudit.log": "write, ddl"}),
+			want: []string{
+				`ALTER ROLE "app" SET pgaudit.log = 'write, ddl';`,
+				`ALTER ROLE "app" SET statement_timeout = '30s';`,
+				`ALTER ROLE "app" SET work_mem = '64MB';`,
+			},
+		},
+		{
+			name:     "quoted",
+			settings: settings(map[string]string{"search_path": "app's"}),
+			want:     []string{`ALTER ROLE "app" SET search_path = 'app''s';`},
+		},
+		{
+			name:     "empty",
+			settings: settings(nil),
+			want:     []string{},
+		},
+		{
+			name:     "invalid parameter",
+			settings: settings(map[string]string{"work_mem; DROP ROLE app": "1"}),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{types.StringValue("app"), tt.settings}),
+			}
+			resp := function.RunResponse{
+				Result: function.NewResultData(types.ListUnknown(types.StringType)),
+			}
+			NewToAlterStatementsFunction().Run(ctx, req, &resp)
+			if tt.wantErr {
+				if resp.Error == nil {
+					t.Error("Run() error = nil, want an error")
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("Run() error = %v", resp.Error)
+			}
+			var got []string
+			resp.Result.Value().(types.List).ElementsAs(ctx, &got, false)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Run() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Run()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
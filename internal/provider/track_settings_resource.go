@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// NewTrackSettingsResource is a helper function to simplify the provider implementation.
+func NewTrackSettingsResource() resource.Resource {
+	return &roleSettingsResource{
+		typeName: "track_settings",
+		description: `Manage the statistics tracking settings of an existing role. Settings left unset are reset to the database default.
+
+These settings can only be set by superusers, or since PostgreSQL 15 by roles granted the privilege with ` + "`GRANT SET ON PARAMETER`" + `. The privileges of the connecting role are checked before applying, with a diagnostic explaining what is missing.
+
+See Postgres [documentation](https://www.postgresql.org/docs/current/runtime-config-statistics.html#RUNTIME-CONFIG-CUMULATIVE-STATISTICS) for more details.`,
+		settings: []roleSetting{
+			{
+				Attribute:   "track_activities",
+				Parameter:   "track_activities",
+				Description: "Whether information on the currently executing command of each session is collected.",
+				Type:        settingBool,
+			},
+			{
+				Attribute:   "track_functions",
+				Parameter:   "track_functions",
+				Description: "Which function call counts and time are tracked, one of \"none\", \"pl\" or \"all\".",
+				StringValidators: []validator.String{
+					stringvalidator.OneOf("none", "pl", "all"),
+				},
+			},
+			{
+				Attribute:   "track_io_timing",
+				Parameter:   "track_io_timing",
+				Description: "Whether timing of database I/O calls is collected.",
+				Type:        settingBool,
+			},
+			{
+				Attribute:   "track_wal_io_timing",
+				Parameter:   "track_wal_io_timing",
+				Description: "Whether timing of WAL I/O calls is collected. Requires PostgreSQL 14 or later.",
+				Type:        settingBool,
+			},
+		},
+	}
+}
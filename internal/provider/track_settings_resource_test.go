@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestTrackSettingsResource(t *testing.T) {
+	config := providerConfig + `
+resource "pgrole_track_settings" "test" {
+  role            = "example_user"
+  track_io_timing = true
+  track_functions = "pl"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("pgrole_track_settings.test", "track_io_timing", "true"),
+					resource.TestCheckResourceAttr("pgrole_track_settings.test", "track_functions", "pl"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "pgrole_track_settings.test",
+				ImportState:                          true,
+				ImportStateId:                        "example_user",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role",
+			},
+			// Out-of-band change is detected as drift
+			{
+				PreConfig:          testAccExecSQL(t, `ALTER ROLE "example_user" SET track_io_timing = off;`),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestTrackSettingsResourceRequiresSuperuser(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: testAccExecSQL(t, `CREATE ROLE "track_admin" LOGIN CREATEROLE PASSWORD 'track_admin';
+GRANT "example_user" TO "track_admin" WITH ADMIN OPTION;`),
+		CheckDestroy: func(*terraform.State) error {
+			testAccExecSQL(t, `DROP ROLE "track_admin";`)()
+			return nil
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfigAs(t, "track_admin", "track_admin") + `
+resource "pgrole_track_settings" "test" {
+  role            = "example_user"
+  track_io_timing = true
+}
+`,
+				ExpectError: regexp.MustCompile(`Parameter track_io_timing can only be set by superusers`),
+			},
+		},
+	})
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = (*validatePgIdentifierFunction)(nil)
+
+// NewValidatePgIdentifierFunction is a helper function to simplify the provider implementation.
+func NewValidatePgIdentifierFunction() function.Function {
+	return &validatePgIdentifierFunction{}
+}
+
+type validatePgIdentifierFunction struct{}
+
+// Metadata returns the function name.
+func (f *validatePgIdentifierFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_pg_identifier"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *validatePgIdentifierFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check a PostgreSQL identifier",
+		MarkdownDescription: "Returns whether a string is a valid PostgreSQL identifier without quoting: at most 63 bytes, starting with a lowercase letter or an underscore, followed by lowercase letters, digits, underscores or dollar signs, and not a reserved keyword such as user. Uppercase letters are rejected since PostgreSQL folds them to lowercase unless quoted. Useful in variable validation blocks for role names.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "name",
+				MarkdownDescription: "The identifier to check, e.g. a role name.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+// Run checks the identifier.
+func (f *validatePgIdentifierFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &name))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, isPlainIdentifier(name)))
+}
+
+// maxIdentifierLength is the maximum length in bytes of identifiers, longer
+// ones being truncated by PostgreSQL, i.e. NAMEDATALEN - 1.
+const maxIdentifierLength = 63
+
+// reservedKeywords are the keywords PostgreSQL rejects as unquoted
+// identifiers, see https://www.postgresql.org/docs/current/sql-keywords-appendix.html.
+var reservedKeywords = []string{
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric",
+	"both", "case", "cast", "check", "collate", "column", "constraint", "create",
+	"current_catalog", "current_date", "current_role", "current_time",
+	"current_timestamp", "current_user", "default", "deferrable", "desc",
+	"distinct", "do", "else", "end", "except", "false", "fetch", "for", "foreign",
+	"from", "grant", "group", "having", "in", "initially", "intersect", "into",
+	"lateral", "leading", "limit", "localtime", "localtimestamp", "not", "null",
+	"offset", "on", "only", "or", "order", "placing", "primary", "references",
+	"returning", "select", "session_user", "some", "symmetric", "system_user",
+	"table", "then", "to", "trailing", "true", "union", "unique", "user", "using",
+	"variadic", "when", "where", "window", "with",
+}
+
+// isPlainIdentifier reports whether name is a valid PostgreSQL identifier
+// without quoting, i.e. quoting it does not change its meaning.
+func isPlainIdentifier(name string) bool {
+	if name == "" || len(name) > maxIdentifierLength || slices.Contains(reservedKeywords, name) {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', unicode.IsLetter(c) && !unicode.IsUpper(c):
+		case i > 0 && (c == '$' || '0' <= c && c <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
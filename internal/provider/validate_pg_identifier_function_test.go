@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIsPlainIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app", true},
+		{"_app_2", true},
+		{"app$ro", true},
+		{"équipe", true},
+		{strings.Repeat("a", 63), true},
+		{"", false},
+		{strings.Repeat("a", 64), false},
+		{"App", false},
+		{"2app", false},
+		{"$app", false},
+		{"app-ro", false},
+		{"app ro", false},
+		{"user", false},
+		{"current_user", false},
+	}
+	for _, tt := range tests {
+		if got := isPlainIdentifier(tt.name); got != tt.want {
+			t.Errorf("isPlainIdentifier(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePgIdentifierFunction(t *testing.T) {
+	ctx := context.Background()
+	req := function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue("app")}),
+	}
+	resp := function.RunResponse{
+		Result: function.NewResultData(types.BoolUnknown()),
+	}
+	NewValidatePgIdentifierFunction().Run(ctx, req, &resp)
+	if resp.Error != nil {
+		t.Fatalf("Run() error = %v", resp.Error)
+	}
+	if got := resp.Result.Value(); !got.Equal(types.BoolValue(true)) {
+		t.Errorf("Run() = %v, want true", got)
+	}
+}
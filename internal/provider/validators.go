@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var (
+	_ validator.String = durationValidator{}
+	_ validator.String = regexValidator{}
+	_ validator.String = memoryValidator{}
+	_ validator.String = asciiValidator{}
+)
+
+// durationValidator validates that a string is a Go duration, e.g. "1.5s".
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a duration, e.g. \"500ms\" or \"2s\""
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid duration", err.Error())
+	}
+}
+
+// regexValidator validates that a string is a valid regular expression.
+type regexValidator struct{}
+
+func (v regexValidator) Description(_ context.Context) string {
+	return "value must be a valid regular expression"
+}
+
+func (v regexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v regexValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid regular expression", err.Error())
+	}
+}
+
+// memorySizeRe matches PostgreSQL memory parameter values, e.g. "64MB", or a
+// bare number in the parameter's default unit.
+var memorySizeRe = regexp.MustCompile(`^\d+\s*(B|kB|MB|GB|TB)?$`)
+
+// memoryValidator validates that a string is a PostgreSQL memory size, e.g.
+// "512kB" or "64MB".
+type memoryValidator struct{}
+
+func (v memoryValidator) Description(_ context.Context) string {
+	return "value must be a memory size, e.g. \"512kB\" or \"64MB\""
+}
+
+func (v memoryValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v memoryValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if !memorySizeRe.MatchString(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid memory size",
+			"Memory sizes must be a number optionally followed by one of the units B, kB, MB, GB or TB, e.g. \"64MB\".",
+		)
+	}
+}
+
+// asciiValidator validates that a string only contains printable ASCII
+// characters. It guards passwords, which are hashed by the provider without
+// the SASLprep normalization PostgreSQL applies to non-ASCII passwords.
+type asciiValidator struct{}
+
+func (v asciiValidator) Description(_ context.Context) string {
+	return "value must only contain printable ASCII characters"
+}
+
+func (v asciiValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v asciiValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	for _, c := range req.ConfigValue.ValueString() {
+		if c < ' ' || c > '~' {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Invalid character",
+				fmt.Sprintf("Value must only contain printable ASCII characters, found %q.", c),
+			)
+			return
+		}
+	}
+}
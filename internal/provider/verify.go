@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// discrepancy returns a line of a discrepancy report when the value of
+// attribute read back from the catalog after an apply differs from the
+// applied one, or an empty string otherwise.
+func discrepancy[T comparable](attribute string, applied, actual T) string {
+	if applied == actual {
+		return ""
+	}
+	return fmt.Sprintf("%s: applied %v, found %v", attribute, applied, actual)
+}
+
+// verifyWrite adds an error to diags when the changes applied to role did not
+// take effect, as reported by discrepancies, or when they could not be read
+// back because of err. Empty discrepancies are ignored.
+//
+// Some managed services silently ignore changes to certain attributes, which
+// would otherwise only surface as drift on the next plan.
+func verifyWrite(diags *diag.Diagnostics, role string, err error, discrepancies ...string) bool {
+	if err != nil {
+		diags.AddError(
+			"Failed to verify changes",
+			fmt.Sprintf("Failed to read back role %s after the apply: %s", role, err),
+		)
+		return false
+	}
+
+	var report []string
+	for _, d := range discrepancies {
+		if d != "" {
+			report = append(report, "  - "+d)
+		}
+	}
+	if len(report) == 0 {
+		return true
+	}
+	diags.AddError(
+		"Changes did not take effect",
+		fmt.Sprintf("The changes applied to role %s were not found in the catalog afterwards, the server may have ignored them:\n%s", role, strings.Join(report, "\n")),
+	)
+	return false
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestVerifyWrite(t *testing.T) {
+	var diags diag.Diagnostics
+	if !verifyWrite(&diags, "app", nil, discrepancy("enabled", true, true), discrepancy("connection_limit", 10, 10)) {
+		t.Errorf("verifyWrite() = false without discrepancies, diagnostics: %v", diags)
+	}
+
+	diags = nil
+	if verifyWrite(&diags, "app", nil, discrepancy("enabled", true, false), discrepancy("timeout", "5s", "5s")) {
+		t.Fatal("verifyWrite() = true with a discrepancy")
+	}
+	if got, want := diags[0].Detail(), "\n  - enabled: applied true, found false"; !strings.HasSuffix(got, want) {
+		t.Errorf("verifyWrite() detail = %q, want suffix %q", got, want)
+	}
+
+	diags = nil
+	if verifyWrite(&diags, "app", errors.New("connection reset"), "") {
+		t.Error("verifyWrite() = true when the role could not be read back")
+	}
+}
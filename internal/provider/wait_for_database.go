@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// waitForDatabaseBackoff is the delay before the first new connection attempt
+// of waitForDatabase, doubled after each attempt up to
+// waitForDatabaseMaxBackoff.
+var (
+	waitForDatabaseBackoff    = time.Second
+	waitForDatabaseMaxBackoff = 15 * time.Second
+)
+
+// waitForDatabase returns a getter retrying to connect with getter until
+// timeout elapses, e.g. for Cloud SQL instances created in the same apply
+// that do not accept connections yet. Refused authentications are not
+// retried, since waiting does not fix them.
+func waitForDatabase(getter DBGetter, timeout time.Duration) DBGetter {
+	if timeout <= 0 {
+		return getter
+	}
+	return F(func(ctx context.Context) (*sql.DB, error) {
+		deadline := time.Now().Add(timeout)
+		backoff := waitForDatabaseBackoff
+		for attempt := 1; ; attempt++ {
+			db, err := getter.GetDB(ctx)
+			if err == nil {
+				// Connections are opened lazily by the Cloud SQL connector
+				if err = db.PingContext(ctx); err == nil {
+					return db, nil
+				}
+				db.Close()
+			}
+			if ctx.Err() != nil || isAuthenticationFailure(err) || time.Now().Add(backoff).After(deadline) {
+				return nil, err
+			}
+
+			tflog.Warn(ctx, "Waiting for the database to accept connections", map[string]any{
+				"attempt": attempt,
+				"backoff": backoff.String(),
+				"error":   err.Error(),
+			})
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, waitForDatabaseMaxBackoff)
+		}
+	})
+}
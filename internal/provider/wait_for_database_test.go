@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/anhpngt/terraform-provider-pgrole/internal/testing/fakedb"
+)
+
+func TestWaitForDatabase(t *testing.T) {
+	backoff, maxBackoff := waitForDatabaseBackoff, waitForDatabaseMaxBackoff
+	waitForDatabaseBackoff, waitForDatabaseMaxBackoff = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() {
+		waitForDatabaseBackoff, waitForDatabaseMaxBackoff = backoff, maxBackoff
+	})
+
+	refused := errors.New("dial tcp 10.0.0.3:5432: connect: connection refused")
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "ready", timeout: time.Minute, wantAttempts: 1},
+		{name: "becomes ready", timeout: time.Minute, failures: 3, err: refused, wantAttempts: 4},
+		{name: "no wait", failures: 3, err: refused, wantAttempts: 1, wantErr: true},
+		{name: "authentication refused", timeout: time.Minute, failures: 3, err: &pq.Error{Code: "28P01"}, wantAttempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			getter := F(func(ctx context.Context) (*sql.DB, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return nil, tt.err
+				}
+				return fakedb.New().GetDB(ctx)
+			})
+
+			db, err := waitForDatabase(getter, tt.timeout).GetDB(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetDB() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if db != nil {
+				db.Close()
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("GetDB() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWaitForDatabaseTimeout(t *testing.T) {
+	backoff := waitForDatabaseBackoff
+	waitForDatabaseBackoff = 10 * time.Millisecond
+	t.Cleanup(func() { waitForDatabaseBackoff = backoff })
+
+	getter := F(func(context.Context) (*sql.DB, error) {
+		return nil, errors.New("connection refused")
+	})
+	start := time.Now()
+	if _, err := waitForDatabase(getter, 50*time.Millisecond).GetDB(context.Background()); err == nil {
+		t.Fatal("GetDB() error = nil, want the connection error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetDB() took %s, want it to give up after the timeout", elapsed)
+	}
+}
@@ -0,0 +1,202 @@
+// Package fakedb provides an in-memory database/sql database for unit tests,
+// so that resource logic can be exercised without a live PostgreSQL server.
+//
+// A DB answers queries from scripted expectations and records every
+// statement it executes, including BEGIN, COMMIT and ROLLBACK.
+package fakedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// DB is a fake database. The zero value is not usable, use New.
+type DB struct {
+	mu      sync.Mutex
+	queries []*expectation
+	execs   []Statement
+	errs    []*expectation
+}
+
+// Statement is a statement executed against a DB.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+type expectation struct {
+	pattern *regexp.Regexp
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+// New returns an empty fake database.
+func New() *DB {
+	return &DB{}
+}
+
+// ExpectQuery makes queries matching pattern return rows of columns. Later
+// expectations take precedence over earlier ones.
+func (d *DB) ExpectQuery(pattern string, columns []string, rows ...[]driver.Value) *DB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, &expectation{
+		pattern: regexp.MustCompile(pattern),
+		columns: columns,
+		rows:    rows,
+	})
+	return d
+}
+
+// ExpectError makes queries and statements matching pattern fail with err.
+func (d *DB) ExpectError(pattern string, err error) *DB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errs = append(d.errs, &expectation{
+		pattern: regexp.MustCompile(pattern),
+		err:     err,
+	})
+	return d
+}
+
+// Execs returns the statements executed so far.
+func (d *DB) Execs() []Statement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Statement(nil), d.execs...)
+}
+
+// GetDB opens a connection pool to the fake database.
+func (d *DB) GetDB(_ context.Context) (*sql.DB, error) {
+	return sql.OpenDB(connector{d}), nil
+}
+
+func (d *DB) failure(query string) error {
+	for i := len(d.errs) - 1; i >= 0; i-- {
+		if d.errs[i].pattern.MatchString(query) {
+			return d.errs[i].err
+		}
+	}
+	return nil
+}
+
+func (d *DB) query(query string) (driver.Rows, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.failure(query); err != nil {
+		return nil, err
+	}
+	for i := len(d.queries) - 1; i >= 0; i-- {
+		if e := d.queries[i]; e.pattern.MatchString(query) {
+			return &rows{columns: e.columns, rows: e.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("fakedb: unexpected query %q", query)
+}
+
+func (d *DB) exec(query string, args []driver.Value) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.failure(query); err != nil {
+		return err
+	}
+	d.execs = append(d.execs, Statement{SQL: query, Args: args})
+	return nil
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fakedb: use DB.GetDB")
+}
+
+type conn struct {
+	db *DB
+}
+
+var (
+	_ driver.ExecerContext  = conn{}
+	_ driver.QueryerContext = conn{}
+)
+
+func (c conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakedb: prepared statements are not supported")
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	if err := c.db.exec("BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return tx(c), nil
+}
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.db.exec(query, values(args)); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c conn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query)
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vs[i] = arg.Value
+	}
+	return vs
+}
+
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error   { return t.db.exec("COMMIT", nil) }
+func (t tx) Rollback() error { return t.db.exec("ROLLBACK", nil) }
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,262 @@
+// Package pgtest provides a disposable PostgreSQL instance for acceptance
+// tests, so that they run hermetically instead of assuming a pre-existing
+// database.
+package pgtest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+const (
+	// DSNEnv names the environment variable pointing at an existing
+	// database, e.g. the one started by docker-compose.yml. When set, no
+	// container is started.
+	DSNEnv = "PGROLE_TEST_DSN"
+
+	// ImageEnv names the environment variable overriding the image of the
+	// started container. It defaults to the image built from docker/postgres.
+	ImageEnv = "PGROLE_TEST_IMAGE"
+
+	// VersionEnv names the environment variable selecting the PostgreSQL
+	// major version of the image built from docker/postgres, e.g. to run
+	// acceptance tests across a version matrix.
+	VersionEnv = "PGROLE_TEST_POSTGRES_VERSION"
+
+	defaultImage   = "pgrole-test-postgres"
+	defaultVersion = "14"
+	password       = "postgres"
+	readyTimeout   = time.Minute
+)
+
+// Fixtures are the statements run once the database is ready, creating the
+// roles acceptance tests manage.
+var Fixtures = []string{
+	`CREATE EXTENSION IF NOT EXISTS pgaudit;`,
+	createRoleIfNotExists("test"),
+	createRoleIfNotExists("example_user"),
+	createRoleIfNotExists("test_role"),
+}
+
+func createRoleIfNotExists(role string) string {
+	return fmt.Sprintf(`DO $$ BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = '%[1]s') THEN
+    CREATE ROLE "%[1]s" LOGIN;
+  END IF;
+END $$;`, role)
+}
+
+// Instance is a PostgreSQL instance for acceptance tests.
+type Instance struct {
+	// DSN is the connection string of the instance.
+	DSN string
+
+	// container is the ID of the started container, empty when an existing
+	// database is used.
+	container string
+}
+
+// Start starts a disposable PostgreSQL container, or uses the database
+// pointed at by DSNEnv, and provisions the fixtures.
+func Start(ctx context.Context) (*Instance, error) {
+	i := &Instance{DSN: os.Getenv(DSNEnv)}
+	if i.DSN == "" {
+		if err := i.startContainer(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := i.provision(ctx); err != nil {
+		i.Close()
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *Instance) startContainer(ctx context.Context) error {
+	image := os.Getenv(ImageEnv)
+	if image == "" {
+		version := os.Getenv(VersionEnv)
+		if version == "" {
+			version = defaultVersion
+		}
+		image = defaultImage + ":" + version
+		if _, err := docker(ctx, "build", "--quiet",
+			"--build-arg", "PG_MAJOR="+version,
+			"--tag", image,
+			dockerContext(),
+		); err != nil {
+			return err
+		}
+	}
+
+	id, err := docker(ctx, "run", "--detach", "--rm",
+		"--env", "POSTGRES_PASSWORD="+password,
+		"--publish", "127.0.0.1::5432",
+		image,
+		"postgres", "-c", "shared_preload_libraries=pgaudit",
+	)
+	if err != nil {
+		return err
+	}
+	i.container = id
+
+	// The output is like "127.0.0.1:49153", possibly followed by IPv6 bindings.
+	out, err := docker(ctx, "port", id, "5432/tcp")
+	if err != nil {
+		i.Close()
+		return err
+	}
+	host, port, err := net.SplitHostPort(strings.Fields(out)[0])
+	if err != nil {
+		i.Close()
+		return fmt.Errorf("error parsing container port %q: %s", out, err)
+	}
+
+	i.DSN = (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword("postgres", password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/postgres",
+		RawQuery: "sslmode=disable",
+	}).String()
+	return nil
+}
+
+// provision waits for the database to accept connections and runs the
+// fixtures.
+func (i *Instance) provision(ctx context.Context) error {
+	db, err := sql.Open("postgres", i.DSN)
+	if err != nil {
+		return fmt.Errorf("error opening database connection: %s", err)
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		err := db.PingContext(ctx)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("database not ready after %s: %s", readyTimeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+
+	for _, sqlstr := range Fixtures {
+		if _, err := db.ExecContext(ctx, sqlstr); err != nil {
+			return fmt.Errorf("error provisioning fixture %q: %s", sqlstr, err)
+		}
+	}
+	return nil
+}
+
+// ServerVersion returns the server_version_num of the instance, e.g. 160002
+// for PostgreSQL 16.2.
+func (i *Instance) ServerVersion(ctx context.Context) (int, error) {
+	return ServerVersion(ctx, i.DSN)
+}
+
+// ServerVersion returns the server_version_num of the database at dsn.
+func ServerVersion(ctx context.Context, dsn string) (int, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return 0, fmt.Errorf("error opening database connection: %s", err)
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRowContext(ctx, "SELECT current_setting('server_version_num')::int;").Scan(&version); err != nil {
+		return 0, fmt.Errorf("error reading server version: %s", err)
+	}
+	return version, nil
+}
+
+// MajorVersion returns the major version of a server_version_num, e.g. 16
+// for 160002.
+func MajorVersion(versionNum int) int {
+	return versionNum / 10000
+}
+
+// SkipBelowVersion skips the test when the database at dsn runs a
+// PostgreSQL major version below major, e.g. for resources relying on
+// features introduced in PostgreSQL 16.
+func SkipBelowVersion(t testing.TB, dsn string, major int) {
+	t.Helper()
+	version, err := ServerVersion(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := MajorVersion(version); got < major {
+		t.Skipf("Requires PostgreSQL %d or later, got %d", major, got)
+	}
+}
+
+// ProviderConfig returns the pgrole provider block connecting to the instance.
+func (i *Instance) ProviderConfig() string {
+	u, err := url.Parse(i.DSN)
+	if err != nil {
+		panic(fmt.Sprintf("invalid DSN: %s", err))
+	}
+	password, _ := u.User.Password()
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 5432
+	}
+	sslmode := u.Query().Get("sslmode")
+	if sslmode == "" {
+		sslmode = "disable"
+	}
+	return fmt.Sprintf(`
+provider "pgrole" {
+  host     = %q
+  port     = %d
+  database = %q
+  username = %q
+  password = %q
+  sslmode  = %q
+}
+`, u.Hostname(), port, strings.TrimPrefix(u.Path, "/"), u.User.Username(), password, sslmode)
+}
+
+// Close removes the container, if one was started.
+func (i *Instance) Close() error {
+	if i.container == "" {
+		return nil
+	}
+	_, err := docker(context.Background(), "rm", "--force", i.container)
+	i.container = ""
+	return err
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// dockerContext returns the docker/postgres directory of the repository.
+func dockerContext() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "docker", "postgres")
+}
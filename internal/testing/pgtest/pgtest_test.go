@@ -0,0 +1,20 @@
+package pgtest
+
+import "testing"
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		versionNum int
+		want       int
+	}{
+		{140011, 14},
+		{150006, 15},
+		{160002, 16},
+		{170000, 17},
+	}
+	for _, tt := range tests {
+		if got := MajorVersion(tt.versionNum); got != tt.want {
+			t.Errorf("MajorVersion(%d) = %d, want %d", tt.versionNum, got, tt.want)
+		}
+	}
+}
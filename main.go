@@ -32,6 +32,9 @@ func main() {
 	}
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// The server stopped: push the final metrics, which the background
+	// pushes may not have sent yet
+	provider.FlushTelemetry(context.Background())
 	if err != nil {
 		log.Fatal(err.Error())
 	}